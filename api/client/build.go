@@ -12,14 +12,17 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api"
+	"github.com/docker/docker/api/types"
 	Cli "github.com/docker/docker/cli"
 	"github.com/docker/docker/opts"
 	"github.com/docker/docker/pkg/archive"
@@ -56,7 +59,12 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 	rm := cmd.Bool([]string{"-rm"}, true, "Remove intermediate containers after a successful build")
 	forceRm := cmd.Bool([]string{"-force-rm"}, false, "Always remove intermediate containers")
 	pull := cmd.Bool([]string{"-pull"}, false, "Always attempt to pull a newer version of the image")
+	squash := cmd.Bool([]string{"-squash"}, false, "Squash the newly built layers into a single new layer")
+	progress := cmd.String([]string{"-progress"}, "plain", "Type of progress output (plain, json)")
+	session := cmd.String([]string{"-session"}, "", "Only send the files of the context which changed since the previous build of the session")
 	dockerfileName := cmd.String([]string{"f", "-file"}, "", "Name of the Dockerfile (Default is 'PATH/Dockerfile')")
+	target := cmd.String([]string{"-target"}, "", "Set the target build stage to build")
+	iidfile := cmd.String([]string{"-iidfile"}, "", "Write the image ID to the file")
 	flMemoryString := cmd.String([]string{"m", "-memory"}, "", "Memory limit")
 	flMemorySwap := cmd.String([]string{"-memory-swap"}, "", "Total memory (memory + swap), '-1' to disable swap")
 	flShmSize := cmd.String([]string{"-shm-size"}, "", "Size of /dev/shm, default value is 64MB")
@@ -66,9 +74,18 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 	flCPUSetCpus := cmd.String([]string{"-cpuset-cpus"}, "", "CPUs in which to allow execution (0-3, 0,1)")
 	flCPUSetMems := cmd.String([]string{"-cpuset-mems"}, "", "MEMs in which to allow execution (0-3, 0,1)")
 	flCgroupParent := cmd.String([]string{"-cgroup-parent"}, "", "Optional parent cgroup for the container")
+	flNetworkMode := cmd.String([]string{"-network"}, "default", "Set the networking mode for the RUN instructions during build")
+	flExtraHosts := opts.NewListOpts(opts.ValidateExtraHost)
+	cmd.Var(&flExtraHosts, []string{"-add-host"}, "Add a custom host-to-IP mapping (host:ip)")
 	flBuildArg := opts.NewListOpts(opts.ValidateEnv)
 	cmd.Var(&flBuildArg, []string{"-build-arg"}, "Set build-time variables")
+	flLabels := opts.NewListOpts(opts.ValidateEnv)
+	cmd.Var(&flLabels, []string{"-label"}, "Set metadata for an image")
 	isolation := cmd.String([]string{"-isolation"}, "", "Container isolation level")
+	flSecrets := opts.NewListOpts(nil)
+	cmd.Var(&flSecrets, []string{"-secret"}, "Secret file to expose to the RUN instructions (id=mysecret,src=/local/secret)")
+	flCacheFrom := opts.NewListOpts(nil)
+	cmd.Var(&flCacheFrom, []string{"-cache-from"}, "Images to consider as cache sources")
 
 	ulimits := make(map[string]*ulimit.Ulimit)
 	flUlimits := opts.NewUlimitOpt(&ulimits)
@@ -81,6 +98,18 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 
 	cmd.ParseFlags(args, true)
 
+	if *progress != "plain" && *progress != "json" {
+		return fmt.Errorf("invalid progress output %q: must be plain or json", *progress)
+	}
+
+	// Do not leave the image ID of a previous build in the file if this
+	// build fails.
+	if *iidfile != "" {
+		if err := os.Remove(*iidfile); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("unable to remove the image ID file: %v", err)
+		}
+	}
+
 	var (
 		context  io.ReadCloser
 		isRemote bool
@@ -164,11 +193,23 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 		includes = append(includes, ".dockerignore", relDockerfile)
 	}
 
-	context, err = archive.TarWithOptions(contextDir, &archive.TarOptions{
+	options := &archive.TarOptions{
 		Compression:     archive.Uncompressed,
 		ExcludePatterns: excludes,
 		IncludeFiles:    includes,
-	})
+	}
+
+	// With a build session, the daemon keeps the context of the previous
+	// build, to which the changes of the context are applied.
+	var deleted []string
+	if *session != "" {
+		options.IncludeFiles, deleted, err = cli.changedContextFiles(*session, contextDir, options, relDockerfile)
+		if err != nil {
+			return fmt.Errorf("unable to get the context of the build session %s: %v", *session, err)
+		}
+	}
+
+	context, err = archive.TarWithOptions(contextDir, options)
 	if err != nil {
 		return err
 	}
@@ -176,13 +217,21 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 	// Wrap the tar archive to replace the Dockerfile entry with the rewritten
 	// Dockerfile which uses trusted pulls.
 	context = replaceDockerfileTarWrapper(context, newDockerfile, relDockerfile)
+	if len(deleted) > 0 {
+		context = addWhiteoutsTarWrapper(context, deleted)
+	}
 
 	// Setup an upload progress bar
 	// FIXME: ProgressReader shouldn't be this annoying to use
 	sf := streamformatter.NewStreamFormatter()
+	progressOut := cli.out
+	if *progress == "json" {
+		// the output only contains the steps of the build
+		progressOut = cli.err
+	}
 	var body io.Reader = progressreader.New(progressreader.Config{
 		In:        context,
-		Out:       cli.out,
+		Out:       progressOut,
 		Formatter: sf,
 		NewLines:  true,
 		ID:        "",
@@ -250,6 +299,18 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 		v.Set("pull", "1")
 	}
 
+	if *squash {
+		v.Set("squash", "1")
+	}
+
+	if *progress == "json" {
+		v.Set("progress", "json")
+	}
+
+	if *session != "" {
+		v.Set("session", *session)
+	}
+
 	if !runconfig.IsolationLevel.IsDefault(runconfig.IsolationLevel(*isolation)) {
 		v.Set("isolation", *isolation)
 	}
@@ -263,8 +324,15 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 	v.Set("memswap", strconv.FormatInt(memorySwap, 10))
 	v.Set("shmsize", strconv.FormatInt(shmSize, 10))
 	v.Set("cgroupparent", *flCgroupParent)
+	v.Set("networkmode", *flNetworkMode)
+	for _, host := range flExtraHosts.GetAll() {
+		v.Add("extrahosts", host)
+	}
 
 	v.Set("dockerfile", relDockerfile)
+	if *target != "" {
+		v.Set("target", *target)
+	}
 
 	ulimitsVar := flUlimits.GetList()
 	ulimitsJSON, err := json.Marshal(ulimitsVar)
@@ -281,22 +349,63 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 	}
 	v.Set("buildargs", string(buildArgsJSON))
 
+	if flLabels.Len() > 0 {
+		labelsJSON, err := json.Marshal(runconfig.ConvertKVStringsToMap(flLabels.GetAll()))
+		if err != nil {
+			return err
+		}
+		v.Set("labels", string(labelsJSON))
+	}
+
+	if flCacheFrom.Len() > 0 {
+		// the images can be given with several flags, or separated by commas
+		var cacheFrom []string
+		for _, images := range flCacheFrom.GetAll() {
+			cacheFrom = append(cacheFrom, strings.Split(images, ",")...)
+		}
+		cacheFromJSON, err := json.Marshal(cacheFrom)
+		if err != nil {
+			return err
+		}
+		v.Set("cachefrom", string(cacheFromJSON))
+	}
+
 	headers := http.Header(make(map[string][]string))
 	buf, err := json.Marshal(cli.configFile.AuthConfigs)
 	if err != nil {
 		return err
 	}
 	headers.Add("X-Registry-Config", base64.URLEncoding.EncodeToString(buf))
+
+	// the secrets are sent in a header rather than in the query string, so
+	// that they are not logged with the URL of the request by the daemon
+	if flSecrets.Len() > 0 {
+		secrets, err := readBuildSecrets(flSecrets.GetAll())
+		if err != nil {
+			return err
+		}
+		buf, err := json.Marshal(secrets)
+		if err != nil {
+			return err
+		}
+		headers.Add("X-Build-Secrets", base64.URLEncoding.EncodeToString(buf))
+	}
 	headers.Set("Content-Type", "application/tar")
 
-	sopts := &streamOpts{
-		rawTerminal: true,
-		in:          body,
-		out:         cli.out,
-		headers:     headers,
+	var imageID string
+	onResult := func(result *jsonmessage.JSONBuildResult) {
+		imageID = result.ImageID
 	}
 
-	serverResp, err := cli.stream("POST", fmt.Sprintf("/build?%s", v.Encode()), sopts)
+	serverResp, err := cli.clientRequest("POST", fmt.Sprintf("/build?%s", v.Encode()), body, headers)
+	if err == nil {
+		if *progress == "json" {
+			err = displayBuildSteps(serverResp.body, cli.out, onResult)
+		} else {
+			err = jsonmessage.DisplayJSONMessagesStreamWithResult(serverResp.body, cli.out, cli.outFd, cli.isTerminalOut, onResult)
+			serverResp.body.Close()
+		}
+	}
 
 	// Windows: show error message about modified file permissions.
 	if runtime.GOOS == "windows" {
@@ -328,6 +437,94 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 		}
 	}
 
+	if *iidfile != "" {
+		if imageID == "" {
+			return fmt.Errorf("unable to write the image ID file: the daemon did not report the ID of the image")
+		}
+		if err := ioutil.WriteFile(*iidfile, []byte(imageID), 0666); err != nil {
+			return fmt.Errorf("unable to write the image ID file: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// displayBuildSteps writes the steps of the build reported in the JSON
+// message stream `in` to `out`, one JSON object per line, calls `onResult`
+// with the result of the build, and returns the error of the build, if any.
+func displayBuildSteps(in io.ReadCloser, out io.Writer, onResult func(*jsonmessage.JSONBuildResult)) error {
+	defer in.Close()
+	dec := json.NewDecoder(in)
+	enc := json.NewEncoder(out)
+	for {
+		var jm jsonmessage.JSONMessage
+		if err := dec.Decode(&jm); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if jm.Error != nil {
+			return jm.Error
+		}
+		if jm.BuildStep != nil {
+			if err := enc.Encode(jm.BuildStep); err != nil {
+				return err
+			}
+		}
+		if jm.BuildResult != nil {
+			onResult(jm.BuildResult)
+		}
+	}
+}
+
+// readBuildSecrets reads the secrets given with `--secret`, in the form
+// `id=<id>,src=<path>`, and returns their contents by ID. The ID defaults to
+// the name of the file.
+func readBuildSecrets(values []string) (map[string][]byte, error) {
+	secrets := make(map[string][]byte, len(values))
+	for _, value := range values {
+		var id, src string
+		for _, field := range strings.Split(value, ",") {
+			parts := strings.SplitN(field, "=", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("invalid secret %q: fields must be in the form key=value", value)
+			}
+			switch strings.ToLower(parts[0]) {
+			case "id":
+				id = parts[1]
+			case "src", "source":
+				src = parts[1]
+			default:
+				return nil, fmt.Errorf("invalid secret %q: unknown field %q", value, parts[0])
+			}
+		}
+		if src == "" {
+			return nil, fmt.Errorf("invalid secret %q: src is required", value)
+		}
+		if id == "" {
+			id = filepath.Base(src)
+		}
+		if err := validateSecretID(id); err != nil {
+			return nil, err
+		}
+		if _, exists := secrets[id]; exists {
+			return nil, fmt.Errorf("duplicate secret %q", id)
+		}
+		data, err := ioutil.ReadFile(src)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read secret %q: %v", id, err)
+		}
+		secrets[id] = data
+	}
+	return secrets, nil
+}
+
+// validateSecretID checks that a secret ID can be used as a file name.
+func validateSecretID(id string) error {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+		return fmt.Errorf("invalid secret ID %q: it must be a valid file name", id)
+	}
 	return nil
 }
 
@@ -704,3 +901,138 @@ func replaceDockerfileTarWrapper(inputTarStream io.ReadCloser, newDockerfile *tr
 
 	return pipeReader
 }
+
+// changedContextFiles returns the files of the build context in contextDir,
+// archived with the options, which changed since the previous build of the
+// build session `id`, and the files which were deleted since then. The files
+// are compared with the ones kept by the daemon using their mode, their size
+// and their modification time.
+func (cli *DockerCli) changedContextFiles(id, contextDir string, options *archive.TarOptions, relDockerfile string) (changed, deleted []string, err error) {
+	serverResp, err := cli.call("GET", "/build/sessions/"+id, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer serverResp.body.Close()
+
+	session := types.BuildSession{}
+	if err := json.NewDecoder(serverResp.body).Decode(&session); err != nil {
+		return nil, nil, err
+	}
+	if len(session.Files) == 0 {
+		return options.IncludeFiles, nil, nil
+	}
+
+	previous := make(map[string]types.BuildContextFile, len(session.Files))
+	var newest int64
+	for _, f := range session.Files {
+		previous[f.Name] = f
+		if !f.Mode.IsDir() && f.ModTime.Unix() > newest {
+			newest = f.ModTime.Unix()
+		}
+	}
+
+	// The Dockerfile is always sent, as it may be replaced by the one using
+	// trusted pulls, and so is the .dockerignore file which goes with it.
+	changed = []string{".dockerignore", relDockerfile}
+	walked := make(map[string]bool)
+	err = archive.WalkWithOptions(contextDir, options, func(filePath, relFilePath string, f os.FileInfo) error {
+		name := filepath.ToSlash(relFilePath)
+		// The parent directories are kept, even if they are excluded.
+		for dir := name; dir != "." && !walked[dir]; dir = path.Dir(dir) {
+			walked[dir] = true
+		}
+		if p, exists := previous[name]; !exists || contextFileChanged(p, f, newest) {
+			changed = append(changed, relFilePath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The files of a deleted directory are deleted with it, and they are
+	// listed right after it.
+	for _, f := range session.Files {
+		if !walked[f.Name] && (len(deleted) == 0 || !strings.HasPrefix(f.Name, deleted[len(deleted)-1]+"/")) {
+			deleted = append(deleted, f.Name)
+		}
+	}
+	return changed, deleted, nil
+}
+
+// contextFileChanged returns whether the file `f` of the build context changed
+// since it was sent as `previous`. Files modified in the same second as the
+// newest file, at `newest`, are considered changed: they may have been
+// modified again after the previous build in the same second.
+func contextFileChanged(previous types.BuildContextFile, f os.FileInfo, newest int64) bool {
+	if !sameFileMode(previous.Mode, f.Mode()) {
+		return true
+	}
+	if f.IsDir() {
+		// the modification time of a directory changes with its files
+		return false
+	}
+	modTime := previous.ModTime.Unix()
+	return previous.Size != f.Size() || modTime != f.ModTime().Unix() || modTime >= newest
+}
+
+func sameFileMode(a, b os.FileMode) bool {
+	if runtime.GOOS == "windows" {
+		// the permissions of the files are not kept in the build context
+		return a&os.ModeType == b&os.ModeType
+	}
+	return a == b
+}
+
+// addWhiteoutsTarWrapper wraps the given input tar archive stream and adds a
+// whiteout file for each of the deleted files before its entries.
+func addWhiteoutsTarWrapper(inputTarStream io.ReadCloser, deleted []string) io.ReadCloser {
+	pipeReader, pipeWriter := io.Pipe()
+
+	go func() {
+		tarReader := tar.NewReader(inputTarStream)
+		tarWriter := tar.NewWriter(pipeWriter)
+
+		defer inputTarStream.Close()
+
+		now := time.Now()
+		for _, name := range deleted {
+			dir, base := path.Split(name)
+			hdr := &tar.Header{
+				Name:     dir + archive.WhiteoutPrefix + base,
+				Typeflag: tar.TypeReg,
+				ModTime:  now,
+			}
+			if err := tarWriter.WriteHeader(hdr); err != nil {
+				pipeWriter.CloseWithError(err)
+				return
+			}
+		}
+
+		for {
+			hdr, err := tarReader.Next()
+			if err == io.EOF {
+				// Signals end of archive.
+				tarWriter.Close()
+				pipeWriter.Close()
+				return
+			}
+			if err != nil {
+				pipeWriter.CloseWithError(err)
+				return
+			}
+
+			if err := tarWriter.WriteHeader(hdr); err != nil {
+				pipeWriter.CloseWithError(err)
+				return
+			}
+
+			if _, err := io.Copy(tarWriter, tarReader); err != nil {
+				pipeWriter.CloseWithError(err)
+				return
+			}
+		}
+	}()
+
+	return pipeReader
+}
@@ -1,9 +1,15 @@
 package client
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/url"
+	"sort"
+	"strings"
 	"time"
 
+	eventtypes "github.com/docker/docker/api/types/events"
 	Cli "github.com/docker/docker/cli"
 	"github.com/docker/docker/opts"
 	flag "github.com/docker/docker/pkg/mflag"
@@ -60,12 +66,61 @@ func (cli *DockerCli) CmdEvents(args ...string) error {
 		}
 		v.Set("filters", filterJSON)
 	}
-	sopts := &streamOpts{
-		rawTerminal: true,
-		out:         cli.out,
-	}
-	if _, err := cli.stream("GET", "/events?"+v.Encode(), sopts); err != nil {
+
+	serverResp, err := cli.call("GET", "/events?"+v.Encode(), nil, nil)
+	if err != nil {
 		return err
 	}
-	return nil
+	defer serverResp.body.Close()
+
+	dec := json.NewDecoder(serverResp.body)
+	for {
+		var event eventtypes.Message
+		if err := dec.Decode(&event); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		printOutput(event, cli.out)
+	}
+}
+
+// printOutput prints all types of event information.
+// Container and image events keep the format of previous versions,
+// `time id: (from image) status`. Other events are printed as
+// `time type action actor-id (attributes)`.
+func printOutput(event eventtypes.Message, output io.Writer) {
+	if event.TimeNano != 0 {
+		fmt.Fprintf(output, "%s ", time.Unix(0, event.TimeNano).Format(timeutils.RFC3339NanoFixed))
+	} else if event.Time != 0 {
+		fmt.Fprintf(output, "%s ", time.Unix(event.Time, 0).Format(timeutils.RFC3339NanoFixed))
+	}
+
+	if event.Status != "" {
+		if event.ID != "" {
+			fmt.Fprintf(output, "%s: ", event.ID)
+		}
+		if event.From != "" {
+			fmt.Fprintf(output, "(from %s) ", event.From)
+		}
+		fmt.Fprintf(output, "%s\n", event.Status)
+		return
+	}
+
+	fmt.Fprintf(output, "%s %s %s", event.Type, event.Action, event.Actor.ID)
+	if len(event.Actor.Attributes) > 0 {
+		var keys []string
+		for k := range event.Actor.Attributes {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var attrs []string
+		for _, k := range keys {
+			attrs = append(attrs, fmt.Sprintf("%s=%s", k, event.Actor.Attributes[k]))
+		}
+		fmt.Fprintf(output, " (%s)", strings.Join(attrs, ", "))
+	}
+	fmt.Fprint(output, "\n")
 }
@@ -12,11 +12,6 @@ import (
 	"github.com/docker/docker/pkg/timeutils"
 )
 
-var validDrivers = map[string]bool{
-	"json-file": true,
-	"journald":  true,
-}
-
 // CmdLogs fetches the logs of a given container.
 //
 // docker logs [OPTIONS] CONTAINER
@@ -42,8 +37,10 @@ func (cli *DockerCli) CmdLogs(args ...string) error {
 		return err
 	}
 
-	if !validDrivers[c.HostConfig.LogConfig.Type] {
-		return fmt.Errorf("\"logs\" command is supported only for \"json-file\" and \"journald\" logging drivers (got: %s)", c.HostConfig.LogConfig.Type)
+	// The logs of the other drivers are read from the logging system, or
+	// from the local cache of the daemon.
+	if c.HostConfig.LogConfig.Type == "none" {
+		return fmt.Errorf("\"logs\" command is not supported for the \"none\" logging driver")
 	}
 
 	v := url.Values{}
@@ -12,8 +12,8 @@ import (
 	"time"
 
 	"github.com/docker/docker/api/types"
+	eventtypes "github.com/docker/docker/api/types/events"
 	Cli "github.com/docker/docker/cli"
-	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/pkg/units"
 )
 
@@ -27,6 +27,7 @@ type containerStats struct {
 	NetworkTx        float64
 	BlockRead        float64
 	BlockWrite       float64
+	PidsCurrent      uint64
 	mu               sync.RWMutex
 	err              error
 }
@@ -88,6 +89,7 @@ func (s *containerStats) Collect(cli *DockerCli, streamStats bool) {
 			s.NetworkRx, s.NetworkTx = calculateNetwork(v.Networks)
 			s.BlockRead = float64(blkRead)
 			s.BlockWrite = float64(blkWrite)
+			s.PidsCurrent = v.PidsStats.Current
 			s.mu.Unlock()
 			u <- nil
 			if !streamStats {
@@ -109,6 +111,7 @@ func (s *containerStats) Collect(cli *DockerCli, streamStats bool) {
 			s.NetworkTx = 0
 			s.BlockRead = 0
 			s.BlockWrite = 0
+			s.PidsCurrent = 0
 			s.mu.Unlock()
 		case err := <-u:
 			if err != nil {
@@ -130,13 +133,14 @@ func (s *containerStats) Display(w io.Writer) error {
 	if s.err != nil {
 		return s.err
 	}
-	fmt.Fprintf(w, "%s\t%.2f%%\t%s / %s\t%.2f%%\t%s / %s\t%s / %s\n",
+	fmt.Fprintf(w, "%s\t%.2f%%\t%s / %s\t%.2f%%\t%s / %s\t%s / %s\t%d\n",
 		s.Name,
 		s.CPUPercentage,
 		units.HumanSize(s.Memory), units.HumanSize(s.MemoryLimit),
 		s.MemoryPercentage,
 		units.HumanSize(s.NetworkRx), units.HumanSize(s.NetworkTx),
-		units.HumanSize(s.BlockRead), units.HumanSize(s.BlockWrite))
+		units.HumanSize(s.BlockRead), units.HumanSize(s.BlockWrite),
+		s.PidsCurrent)
 	return nil
 }
 
@@ -186,7 +190,7 @@ func (cli *DockerCli) CmdStats(args ...string) error {
 			fmt.Fprint(cli.out, "\033[2J")
 			fmt.Fprint(cli.out, "\033[H")
 		}
-		io.WriteString(w, "CONTAINER\tCPU %\tMEM USAGE / LIMIT\tMEM %\tNET I/O\tBLOCK I/O\n")
+		io.WriteString(w, "CONTAINER\tCPU %\tMEM USAGE / LIMIT\tMEM %\tNET I/O\tBLOCK I/O\tPIDS\n")
 	}
 	for _, n := range names {
 		s := &containerStats{Name: n}
@@ -211,11 +215,14 @@ func (cli *DockerCli) CmdStats(args ...string) error {
 
 			dec := json.NewDecoder(res.body)
 			for {
-				var j *jsonmessage.JSONMessage
+				var j eventtypes.Message
 				if err := dec.Decode(&j); err != nil {
 					c <- watch{err: err}
 					return
 				}
+				if j.Type != "" && j.Type != eventtypes.ContainerEventType {
+					continue
+				}
 				c <- watch{j.ID[:12], j.Status, nil}
 			}
 		}
@@ -13,6 +13,7 @@ import (
 	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/dockerversion"
 	"github.com/docker/docker/errors"
+	"github.com/docker/docker/pkg/authorization"
 	"github.com/docker/docker/pkg/version"
 	"golang.org/x/net/context"
 )
@@ -79,6 +80,49 @@ func (s *Server) userAgentMiddleware(handler httputils.APIFunc) httputils.APIFun
 	}
 }
 
+// authorizationMiddleware consults the authorization plugins before and after
+// each request, so that they can deny it or modify its response.
+func (s *Server) authorizationMiddleware(handler httputils.APIFunc) httputils.APIFunc {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		// The user is only known when the client authenticated with a TLS certificate
+		user := ""
+		userAuthNMethod := ""
+		if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+			user = r.TLS.PeerCertificates[0].Subject.CommonName
+			userAuthNMethod = "TLS"
+		}
+		authCtx := authorization.NewCtx(s.authZPlugins, user, userAuthNMethod, r.Method, r.RequestURI)
+
+		if err := authCtx.AuthZRequest(r); err != nil {
+			logrus.Errorf("AuthZRequest for %s %s returned error: %s", r.Method, r.RequestURI, err)
+			return authorizationError(err)
+		}
+
+		rw := authorization.NewResponseModifier(w)
+
+		if err := handler(ctx, rw, r, vars); err != nil {
+			logrus.Errorf("Handler for %s %s returned error: %s", r.Method, r.RequestURI, err)
+			return err
+		}
+
+		if err := authCtx.AuthZResponse(rw); err != nil {
+			logrus.Errorf("AuthZResponse for %s %s returned error: %s", r.Method, r.RequestURI, err)
+			return authorizationError(err)
+		}
+
+		return rw.FlushAll()
+	}
+}
+
+// authorizationError converts the denials of the authorization plugins to
+// API errors.
+func authorizationError(err error) error {
+	if _, ok := err.(*authorization.DeniedError); ok {
+		return errors.ErrorCodeAuthorizationDenied.WithArgs(err.Error())
+	}
+	return err
+}
+
 // corsMiddleware sets the CORS header expectations in the server.
 func (s *Server) corsMiddleware(handler httputils.APIFunc) httputils.APIFunc {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
@@ -146,6 +190,10 @@ func (s *Server) handleWithGlobalMiddlewares(handler httputils.APIFunc) httputil
 		middlewares = append(middlewares, s.debugRequestMiddleware)
 	}
 
+	if len(s.authZPlugins) > 0 {
+		middlewares = append(middlewares, s.authorizationMiddleware)
+	}
+
 	h := handler
 	for _, m := range middlewares {
 		h = m(h)
@@ -22,6 +22,7 @@ import (
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/chrootarchive"
 	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/pkg/progressreader"
 	"github.com/docker/docker/pkg/streamformatter"
 	"github.com/docker/docker/pkg/ulimit"
@@ -300,10 +301,40 @@ func (s *router) getImagesByName(ctx context.Context, w http.ResponseWriter, r *
 	return httputils.WriteJSON(w, http.StatusOK, imageInspect)
 }
 
+// errBuildSessionsUnavailable is returned when the daemon failed to initialize
+// the directory of the build contexts of the build sessions.
+var errBuildSessionsUnavailable = errors.New("Build sessions are not available on this daemon")
+
+func (s *router) getBuildSession(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if s.buildSessions == nil {
+		return errBuildSessionsUnavailable
+	}
+	session, err := s.buildSessions.Get(vars["id"])
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusOK, session)
+}
+
+func (s *router) deleteBuildSession(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if s.buildSessions == nil {
+		return errBuildSessionsUnavailable
+	}
+	if err := s.buildSessions.Delete(vars["id"]); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
 func (s *router) postBuild(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	var (
 		authConfigs        = map[string]cliconfig.AuthConfig{}
 		authConfigsEncoded = r.Header.Get("X-Registry-Config")
+		secrets            = map[string][]byte{}
+		secretsEncoded     = r.Header.Get("X-Build-Secrets")
 		buildConfig        = &dockerfile.Config{}
 	)
 
@@ -316,6 +347,13 @@ func (s *router) postBuild(ctx context.Context, w http.ResponseWriter, r *http.R
 		}
 	}
 
+	if secretsEncoded != "" {
+		secretsJSON := base64.NewDecoder(base64.URLEncoding, strings.NewReader(secretsEncoded))
+		if err := json.NewDecoder(secretsJSON).Decode(&secrets); err != nil {
+			return fmt.Errorf("Invalid build secrets: %v", err)
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 
 	version := httputils.VersionFromContext(ctx)
@@ -355,6 +393,7 @@ func (s *router) postBuild(ctx context.Context, w http.ResponseWriter, r *http.R
 	buildConfig.Verbose = !httputils.BoolValue(r, "q")
 	buildConfig.UseCache = !httputils.BoolValue(r, "nocache")
 	buildConfig.ForceRemove = httputils.BoolValue(r, "forcerm")
+	buildConfig.Squash = httputils.BoolValue(r, "squash")
 	buildConfig.MemorySwap = httputils.Int64ValueOrZero(r, "memswap")
 	buildConfig.Memory = httputils.Int64ValueOrZero(r, "memory")
 	buildConfig.ShmSize = httputils.Int64ValueOrZero(r, "shmsize")
@@ -364,6 +403,9 @@ func (s *router) postBuild(ctx context.Context, w http.ResponseWriter, r *http.R
 	buildConfig.CPUSetCpus = r.FormValue("cpusetcpus")
 	buildConfig.CPUSetMems = r.FormValue("cpusetmems")
 	buildConfig.CgroupParent = r.FormValue("cgroupparent")
+	buildConfig.NetworkMode = r.FormValue("networkmode")
+	buildConfig.ExtraHosts = r.Form["extrahosts"]
+	buildConfig.Target = r.FormValue("target")
 
 	if i := runconfig.IsolationLevel(r.FormValue("isolation")); i != "" {
 		if !runconfig.IsolationLevel.IsValid(i) {
@@ -381,6 +423,24 @@ func (s *router) postBuild(ctx context.Context, w http.ResponseWriter, r *http.R
 		buildConfig.Ulimits = buildUlimits
 	}
 
+	var cacheFrom = []string{}
+	cacheFromJSON := r.FormValue("cachefrom")
+	if cacheFromJSON != "" {
+		if err := json.NewDecoder(strings.NewReader(cacheFromJSON)).Decode(&cacheFrom); err != nil {
+			return errf(err)
+		}
+		buildConfig.CacheFrom = cacheFrom
+	}
+
+	var labels = map[string]string{}
+	labelsJSON := r.FormValue("labels")
+	if labelsJSON != "" {
+		if err := json.NewDecoder(strings.NewReader(labelsJSON)).Decode(&labels); err != nil {
+			return errf(err)
+		}
+		buildConfig.Labels = labels
+	}
+
 	var buildArgs = map[string]string{}
 	buildArgsJSON := r.FormValue("buildargs")
 	if buildArgsJSON != "" {
@@ -409,7 +469,20 @@ func (s *router) postBuild(ctx context.Context, w http.ResponseWriter, r *http.R
 		context        builder.ModifiableContext
 		dockerfileName string
 	)
-	context, dockerfileName, err = daemonbuilder.DetectContextFromRemoteURL(r.Body, remoteURL, pReader)
+	if session := r.FormValue("session"); session != "" {
+		// The body only contains the files which changed since the
+		// previous build of the session.
+		switch {
+		case remoteURL != "":
+			err = fmt.Errorf("A build session can't be used with a remote build context")
+		case s.buildSessions == nil:
+			err = errBuildSessionsUnavailable
+		default:
+			context, err = s.buildSessions.MakeContext(session, r.Body)
+		}
+	} else {
+		context, dockerfileName, err = daemonbuilder.DetectContextFromRemoteURL(r.Body, remoteURL, pReader)
+	}
 	if err != nil {
 		return errf(err)
 	}
@@ -430,6 +503,7 @@ func (s *router) postBuild(ctx context.Context, w http.ResponseWriter, r *http.R
 		OutOld:      output,
 		AuthConfigs: authConfigs,
 		Archiver:    defaultArchiver,
+		Secrets:     secrets,
 	}
 
 	b, err := dockerfile.NewBuilder(buildConfig, docker, builder.DockerIgnoreContext{ModifiableContext: context}, nil)
@@ -438,6 +512,11 @@ func (s *router) postBuild(ctx context.Context, w http.ResponseWriter, r *http.R
 	}
 	b.Stdout = &streamformatter.StdoutFormatter{Writer: output, StreamFormatter: sf}
 	b.Stderr = &streamformatter.StderrFormatter{Writer: output, StreamFormatter: sf}
+	if r.FormValue("progress") == "json" {
+		b.StepProgress = func(step *jsonmessage.JSONBuildStep) {
+			output.Write(sf.FormatBuildStep(step))
+		}
+	}
 
 	if closeNotifier, ok := w.(http.CloseNotifier); ok {
 		finished := make(chan struct{})
@@ -467,6 +546,11 @@ func (s *router) postBuild(ctx context.Context, w http.ResponseWriter, r *http.R
 		}
 	}
 
+	// Older clients would display the result as an empty line.
+	if version.GreaterThanOrEqualTo("1.22") {
+		output.Write(sf.FormatBuildResult(imgID))
+	}
+
 	return nil
 }
 
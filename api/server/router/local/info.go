@@ -10,9 +10,9 @@ import (
 	"github.com/docker/docker/api"
 	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/dockerversion"
 	"github.com/docker/docker/pkg/ioutils"
-	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/pkg/parsers/filters"
 	"github.com/docker/docker/pkg/parsers/kernel"
 	"github.com/docker/docker/pkg/timeutils"
@@ -66,10 +66,26 @@ func (s *router) getEvents(ctx context.Context, w http.ResponseWriter, r *http.R
 		return err
 	}
 
+	var (
+		sinceTime, untilTime time.Time
+		onlyPastEvents       bool
+	)
+	if since != -1 {
+		sinceTime = time.Unix(since, sinceNano)
+	}
+
 	timer := time.NewTimer(0)
 	timer.Stop()
 	if until > 0 || untilNano > 0 {
-		dur := time.Unix(until, untilNano).Sub(time.Now())
+		untilTime = time.Unix(until, untilNano)
+		if untilNano == 0 {
+			// a timestamp in seconds includes the events of that second
+			untilTime = untilTime.Add(time.Second - time.Nanosecond)
+		}
+		dur := untilTime.Sub(time.Now())
+		// when until is in the past, only the recorded events are
+		// returned and the stream is closed right away.
+		onlyPastEvents = dur <= 0
 		timer = time.NewTimer(dur)
 	}
 
@@ -92,11 +108,8 @@ func (s *router) getEvents(ctx context.Context, w http.ResponseWriter, r *http.R
 
 	enc := json.NewEncoder(output)
 
-	current, l, cancel := s.daemon.SubscribeToEvents()
-	defer cancel()
-
 	eventFilter := s.daemon.GetEventFilter(ef)
-	handleEvent := func(ev *jsonmessage.JSONMessage) error {
+	handleEvent := func(ev events.Message) error {
 		if eventFilter.Include(ev) {
 			if err := enc.Encode(ev); err != nil {
 				return err
@@ -105,16 +118,15 @@ func (s *router) getEvents(ctx context.Context, w http.ResponseWriter, r *http.R
 		return nil
 	}
 
-	if since == -1 {
-		current = nil
+	// the recorded events are sent as they are read
+	l, cancel, err := s.daemon.SubscribeToEvents(sinceTime, untilTime, handleEvent)
+	if err != nil {
+		return err
 	}
-	for _, ev := range current {
-		if ev.Time < since || ((ev.Time == since) && (ev.TimeNano < sinceNano)) {
-			continue
-		}
-		if err := handleEvent(ev); err != nil {
-			return err
-		}
+	defer cancel()
+
+	if onlyPastEvents {
+		return nil
 	}
 
 	var closeNotify <-chan bool
@@ -125,7 +137,7 @@ func (s *router) getEvents(ctx context.Context, w http.ResponseWriter, r *http.R
 	for {
 		select {
 		case ev := <-l:
-			jev, ok := ev.(*jsonmessage.JSONMessage)
+			jev, ok := ev.(events.Message)
 			if !ok {
 				continue
 			}
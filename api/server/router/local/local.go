@@ -2,18 +2,22 @@ package local
 
 import (
 	"net/http"
+	"path/filepath"
 
 	"golang.org/x/net/context"
 
+	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/api/server/httputils"
 	dkrouter "github.com/docker/docker/api/server/router"
+	"github.com/docker/docker/builder"
 	"github.com/docker/docker/daemon"
 )
 
 // router is a docker router that talks with the local docker daemon.
 type router struct {
-	daemon *daemon.Daemon
-	routes []dkrouter.Route
+	daemon        *daemon.Daemon
+	buildSessions *builder.ContextSessions
+	routes        []dkrouter.Route
 }
 
 // localRoute defines an individual API route to connect with the docker daemon.
@@ -79,6 +83,10 @@ func NewRouter(daemon *daemon.Daemon) dkrouter.Router {
 	r := &router{
 		daemon: daemon,
 	}
+	var err error
+	if r.buildSessions, err = builder.NewContextSessions(filepath.Join(daemon.BuilderRoot(), "sessions")); err != nil {
+		logrus.Errorf("Failed to initialize the build sessions: %v", err)
+	}
 	r.initRoutes()
 	return r
 }
@@ -104,6 +112,7 @@ func (r *router) initRoutes() {
 		NewGetRoute("/images/{name:.*}/get", r.getImagesGet),
 		NewGetRoute("/images/{name:.*}/history", r.getImagesHistory),
 		NewGetRoute("/images/{name:.*}/json", r.getImagesByName),
+		NewGetRoute("/build/sessions/{id:.*}", r.getBuildSession),
 		// POST
 		NewPostRoute("/auth", r.postAuth),
 		NewPostRoute("/commit", r.postCommit),
@@ -114,6 +123,7 @@ func (r *router) initRoutes() {
 		NewPostRoute("/images/{name:.*}/tag", r.postImagesTag),
 		// DELETE
 		NewDeleteRoute("/images/{name:.*}", r.deleteImages),
+		NewDeleteRoute("/build/sessions/{id:.*}", r.deleteBuildSession),
 	}
 }
 
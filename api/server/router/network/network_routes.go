@@ -172,7 +172,7 @@ func (n *networkRouter) deleteNetwork(ctx context.Context, w http.ResponseWriter
 			fmt.Sprintf("%s is a pre-defined network and cannot be removed", nw.Name()))
 	}
 
-	return nw.Delete()
+	return n.backend.DeleteNetwork(nw.ID())
 }
 
 func buildNetworkResource(nw libnetwork.Network) *types.NetworkResource {
@@ -15,6 +15,7 @@ import (
 	"github.com/docker/docker/api/server/router/network"
 	"github.com/docker/docker/api/server/router/volume"
 	"github.com/docker/docker/daemon"
+	"github.com/docker/docker/pkg/authorization"
 	"github.com/docker/docker/pkg/sockets"
 	"github.com/docker/docker/utils"
 	"github.com/gorilla/mux"
@@ -34,6 +35,10 @@ type Config struct {
 	SocketGroup string
 	TLSConfig   *tls.Config
 	Addrs       []Addr
+
+	// AuthorizationPluginNames are the names of the authorization plugins
+	// consulted for each request, in order.
+	AuthorizationPluginNames []string
 }
 
 // Server contains instance details for the server
@@ -42,6 +47,8 @@ type Server struct {
 	start   chan struct{}
 	servers []*HTTPServer
 	routers []router.Router
+
+	authZPlugins []authorization.Plugin
 }
 
 // Addr contains string representation of address and its protocol (tcp, unix...).
@@ -54,8 +61,9 @@ type Addr struct {
 // It allocates resources which will be needed for ServeAPI(ports, unix-sockets).
 func New(cfg *Config) (*Server, error) {
 	s := &Server{
-		cfg:   cfg,
-		start: make(chan struct{}),
+		cfg:          cfg,
+		start:        make(chan struct{}),
+		authZPlugins: authorization.NewPlugins(cfg.AuthorizationPluginNames),
 	}
 	for _, addr := range cfg.Addrs {
 		srv, err := s.newServer(addr.Proto, addr.Addr)
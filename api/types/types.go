@@ -21,6 +21,13 @@ type ContainerCreateResponse struct {
 	Warnings []string `json:"Warnings"`
 }
 
+// ContainerUpdateResponse contains response of Remote API:
+// POST "/containers/{name:.*}/update"
+type ContainerUpdateResponse struct {
+	// Warnings are any warnings encountered during the updating of the container.
+	Warnings []string `json:"Warnings"`
+}
+
 // ContainerExecCreateResponse contains response of Remote API:
 // POST "/containers/{name:.*}/exec"
 type ContainerExecCreateResponse struct {
@@ -260,32 +267,57 @@ type ContainerState struct {
 	Error      string
 	StartedAt  string
 	FinishedAt string
+	Health     *Health `json:",omitempty"`
+}
+
+// Health states of a container that has a healthcheck
+const (
+	NoHealthcheck = "none"      // Indicates there is no healthcheck
+	Starting      = "starting"  // Starting indicates that the container is not yet ready
+	Healthy       = "healthy"   // Healthy indicates that the container is running correctly
+	Unhealthy     = "unhealthy" // Unhealthy indicates that the container has a problem
+)
+
+// HealthcheckResult stores information about a single run of a healthcheck probe
+type HealthcheckResult struct {
+	Start    time.Time // Start is the time this check started
+	End      time.Time // End is the time this check ended
+	ExitCode int       // ExitCode meanings: 0=healthy, 1=unhealthy, 2=reserved (considered unhealthy), else=error running probe
+	Output   string    // Output from last check
+}
+
+// Health stores information about the container's healthcheck results
+type Health struct {
+	Status        string               // Status is one of Starting, Healthy or Unhealthy
+	FailingStreak int                  // FailingStreak is the number of consecutive failures
+	Log           []*HealthcheckResult // Log contains the last few results (oldest first)
 }
 
 // ContainerJSONBase contains response of Remote API:
 // GET "/containers/{name:.*}/json"
 type ContainerJSONBase struct {
-	ID              string `json:"Id"`
-	Created         string
-	Path            string
-	Args            []string
-	State           *ContainerState
-	Image           string
-	ResolvConfPath  string
-	HostnamePath    string
-	HostsPath       string
-	LogPath         string
-	Name            string
-	RestartCount    int
-	Driver          string
-	MountLabel      string
-	ProcessLabel    string
-	AppArmorProfile string
-	ExecIDs         []string
-	HostConfig      *runconfig.HostConfig
-	GraphDriver     GraphDriverData
-	SizeRw          *int64 `json:",omitempty"`
-	SizeRootFs      *int64 `json:",omitempty"`
+	ID                 string `json:"Id"`
+	Created            string
+	Path               string
+	Args               []string
+	State              *ContainerState
+	Image              string
+	ResolvConfPath     string
+	HostnamePath       string
+	HostsPath          string
+	LogPath            string
+	LogDroppedMessages int64
+	Name               string
+	RestartCount       int
+	Driver             string
+	MountLabel         string
+	ProcessLabel       string
+	AppArmorProfile    string
+	ExecIDs            []string
+	HostConfig         *runconfig.HostConfig
+	GraphDriver        GraphDriverData
+	SizeRw             *int64 `json:",omitempty"`
+	SizeRootFs         *int64 `json:",omitempty"`
 }
 
 // ContainerJSON is newly used struct along with MountPoint
@@ -361,6 +393,21 @@ type VolumeCreateRequest struct {
 	DriverOpts map[string]string // DriverOpts holds the driver specific options to use for when creating the volume.
 }
 
+// BuildContextFile represents a file of the build context of a build session
+type BuildContextFile struct {
+	Name    string      // Name is the path of the file in the build context
+	Mode    os.FileMode // Mode is the mode of the file
+	Size    int64       // Size is the size of the file
+	ModTime time.Time   // ModTime is the modification time of the file
+}
+
+// BuildSession contains the response for the remote API:
+// GET "/build/sessions/{id}"
+type BuildSession struct {
+	ID    string             // ID is the ID of the build session
+	Files []BuildContextFile // Files are the files of the build context kept for the session
+}
+
 // NetworkResource is the body of the "get network" http response message
 type NetworkResource struct {
 	Name       string
@@ -122,10 +122,16 @@ type Docker interface {
 	// Create creates a new Docker container and returns potential warnings
 	// TODO: put warnings in the error
 	Create(*runconfig.Config, *runconfig.HostConfig) (*daemon.Container, []string, error)
+	// CreateWithSecrets creates a new Docker container like Create, in
+	// which the build secrets are mounted while it runs.
+	CreateWithSecrets(*runconfig.Config, *runconfig.HostConfig) (*daemon.Container, []string, error)
 	// Remove removes a container specified by `id`.
 	Remove(id string, cfg *daemon.ContainerRmConfig) error
 	// Commit creates a new Docker image from an existing Docker container.
 	Commit(string, *daemon.ContainerCommitConfig) (string, error)
+	// SquashImage creates a new image from the image `id`, in which the layers
+	// added on top of the image `parent` are squashed into a single layer.
+	SquashImage(id, parent string) (string, error)
 	// Copy copies/extracts a source FileInfo to a destination path inside a container
 	// specified by a container object.
 	// TODO: make an Extract method instead of passing `decompress`
@@ -156,3 +162,14 @@ type ImageCache interface {
 	// and runconfig equals `cfg`. A cache miss is expected to return an empty ID and a nil error.
 	GetCachedImage(parentID string, cfg *runconfig.Config) (imageID string, err error)
 }
+
+// ImageCacheFrom abstracts an image cache made of the history of source images,
+// which don't need to have been built locally.
+// (parent image, child runconfig, source images) -> child image
+type ImageCacheFrom interface {
+	// GetCachedImageFrom returns a reference to an image whose parent equals `parent`
+	// and which was created with the runconfig `cfg` in the history of one of the
+	// images `sources`. The image is created with the config `imageCfg` if needed.
+	// A cache miss is expected to return an empty ID and a nil error.
+	GetCachedImageFrom(sources []string, parentID string, cfg, imageCfg *runconfig.Config) (imageID string, err error)
+}
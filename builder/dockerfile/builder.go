@@ -13,21 +13,23 @@ import (
 	"github.com/docker/docker/builder"
 	"github.com/docker/docker/builder/dockerfile/parser"
 	"github.com/docker/docker/daemon"
+	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/pkg/stringid"
 	"github.com/docker/docker/pkg/ulimit"
 	"github.com/docker/docker/runconfig"
 )
 
 var validCommitCommands = map[string]bool{
-	"cmd":        true,
-	"entrypoint": true,
-	"env":        true,
-	"expose":     true,
-	"label":      true,
-	"onbuild":    true,
-	"user":       true,
-	"volume":     true,
-	"workdir":    true,
+	"cmd":         true,
+	"entrypoint":  true,
+	"env":         true,
+	"expose":      true,
+	"healthcheck": true,
+	"label":       true,
+	"onbuild":     true,
+	"user":        true,
+	"volume":      true,
+	"workdir":     true,
 }
 
 // BuiltinAllowedBuildArgs is list of built-in allowed build args
@@ -52,8 +54,14 @@ type Config struct {
 	Remove      bool
 	ForceRemove bool
 	Pull        bool
+	Squash      bool
+	CacheFrom   []string          // images to use as a build cache, in addition to the local images.
 	BuildArgs   map[string]string // build-time args received in build context for expansion/substitution and commands in 'run'.
+	Labels      map[string]string // labels added to the image with an implicit LABEL instruction after the last step.
+	Target      string            // name of the build stage after which the build stops.
 	Isolation   runconfig.IsolationLevel
+	NetworkMode string   // network mode of the containers of the RUN instructions.
+	ExtraHosts  []string // extra host-to-IP mappings of the containers of the RUN instructions.
 
 	// resource constraints
 	// TODO: factor out to be reused with Run ?
@@ -77,6 +85,9 @@ type Builder struct {
 
 	Stdout io.Writer
 	Stderr io.Writer
+	// StepProgress, if set, is called with the description of each step of
+	// the build once it is done.
+	StepProgress func(*jsonmessage.JSONBuildStep)
 
 	docker  builder.Docker
 	context builder.Context
@@ -94,12 +105,23 @@ type Builder struct {
 	cancelled        chan struct{}
 	cancelOnce       sync.Once
 	allowedBuildArgs map[string]bool // list of build-time args that are allowed for expansion/substitution and passing to commands in 'run'.
+	cacheFrom        []string        // IDs of the images of CacheFrom.
+	stages           []*buildStage   // stages of the build, one for each FROM instruction.
+	step             *buildStep      // current step of the build, when StepProgress is set.
 
 	// TODO: remove once docker.Commit can receive a tag
 	id           string
 	activeImages []string
 }
 
+// buildStage is a stage of a multi-stage build, which starts with a FROM
+// instruction and produces an image that later stages can copy files from.
+type buildStage struct {
+	name  string // set with `FROM <image> AS <name>`
+	base  string // imageID of the base image, empty for scratch
+	image string // imageID, set once the stage is completed
+}
+
 // NewBuilder creates a new Dockerfile builder from an optional dockerfile and a Config.
 // If dockerfile is nil, the Dockerfile specified by Config.DockerfileName,
 // will be read from the Context passed to Build().
@@ -158,6 +180,14 @@ func (b *Builder) Build() (string, error) {
 		}
 	}
 
+	if b.Target != "" {
+		if err := b.selectTarget(); err != nil {
+			return "", err
+		}
+	}
+
+	b.lookupCacheFrom()
+
 	var shortImgID string
 	for i, n := range b.dockerfile.Children {
 		select {
@@ -168,7 +198,10 @@ func (b *Builder) Build() (string, error) {
 		default:
 			// Not cancelled yet, keep going...
 		}
-		if err := b.dispatch(i, n); err != nil {
+		b.startStep(i, n)
+		err := b.dispatch(i, n)
+		b.finishStep(err)
+		if err != nil {
 			if b.ForceRemove {
 				b.clearTmp()
 			}
@@ -197,6 +230,27 @@ func (b *Builder) Build() (string, error) {
 		return "", fmt.Errorf("No image was generated. Is your Dockerfile empty?")
 	}
 
+	if len(b.Labels) > 0 {
+		if err := b.addLabels(); err != nil {
+			if b.ForceRemove {
+				b.clearTmp()
+			}
+			return "", err
+		}
+		shortImgID = stringid.TruncateID(b.image)
+		fmt.Fprintf(b.Stdout, " ---> %s\n", shortImgID)
+		if b.Remove {
+			b.clearTmp()
+		}
+	}
+
+	if b.Squash {
+		if err := b.squash(); err != nil {
+			return "", err
+		}
+		shortImgID = stringid.TruncateID(b.image)
+	}
+
 	fmt.Fprintf(b.Stdout, "Successfully built %s\n", shortImgID)
 	return b.image, nil
 }
@@ -15,11 +15,12 @@ import (
 	"regexp"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	derr "github.com/docker/docker/errors"
-	"github.com/docker/docker/image"
 	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/pkg/nat"
 	"github.com/docker/docker/pkg/signal"
@@ -165,40 +166,81 @@ func add(b *Builder, args []string, attributes map[string]bool, original string)
 		return err
 	}
 
-	return b.runContextCommand(args, true, true, "ADD")
+	return b.runContextCommand(b.context, args, true, true, "ADD")
 }
 
 // COPY foo /path
 //
 // Same as 'ADD' but without the tar and remote url handling.
+// With --from=<stage|image>, the files are copied from a previous build
+// stage or from an image instead of the context.
 //
 func dispatchCopy(b *Builder, args []string, attributes map[string]bool, original string) error {
 	if len(args) < 2 {
 		return derr.ErrorCodeAtLeastTwoArgs.WithArgs("COPY")
 	}
 
+	flFrom := b.flags.AddString("from", "")
+
 	if err := b.flags.Parse(); err != nil {
 		return err
 	}
 
-	return b.runContextCommand(args, false, false, "COPY")
+	context := b.context
+	if flFrom.Value != "" {
+		imageContext, err := b.imageContext(flFrom.Value)
+		if err != nil {
+			return err
+		}
+		defer imageContext.Close()
+		context = imageContext
+	}
+
+	return b.runContextCommand(context, args, false, false, "COPY")
 }
 
-// FROM imagename
+// FROM imagename [AS name]
 //
-// This sets the image the dockerfile will build on top of.
+// This sets the image the dockerfile will build on top of. Each FROM starts
+// a new build stage, which can be named to refer to it from later stages.
 //
 func from(b *Builder, args []string, attributes map[string]bool, original string) error {
-	if len(args) != 1 {
-		return derr.ErrorCodeExactlyOneArg.WithArgs("FROM")
+	if len(args) != 1 && (len(args) != 3 || !strings.EqualFold(args[1], "AS")) {
+		return derr.ErrorCodeFromArgs
 	}
 
 	if err := b.flags.Parse(); err != nil {
 		return err
 	}
 
+	var stageName string
+	if len(args) == 3 {
+		stageName = strings.ToLower(args[2])
+		if !validStageName.MatchString(stageName) {
+			return derr.ErrorCodeInvalidStageName.WithArgs(args[2])
+		}
+		for _, stage := range b.stages {
+			if stage.name == stageName {
+				return derr.ErrorCodeDuplicateStageName.WithArgs(args[2])
+			}
+		}
+	}
+	b.startStage(stageName)
+
 	name := args[0]
 
+	// A previous stage can be used as the base image.
+	if stage := b.lookupStage(name); stage != nil {
+		if stage.image == "" {
+			return fmt.Errorf("Build stage %s has no image to build on", name)
+		}
+		image, err := b.docker.LookupImage(stage.image)
+		if err != nil {
+			return err
+		}
+		return b.processImageFrom(image)
+	}
+
 	// Windows cannot support a container with no base image.
 	if name == NoBaseImageSpecifier {
 		if runtime.GOOS == "windows" {
@@ -209,20 +251,9 @@ func from(b *Builder, args []string, attributes map[string]bool, original string
 		return nil
 	}
 
-	var (
-		image *image.Image
-		err   error
-	)
-	// TODO: don't use `name`, instead resolve it to a digest
-	if !b.Pull {
-		image, err = b.docker.LookupImage(name)
-		// TODO: shouldn't we error out if error is different from "not found" ?
-	}
-	if image == nil {
-		image, err = b.docker.Pull(name)
-		if err != nil {
-			return err
-		}
+	image, err := b.getImage(name)
+	if err != nil {
+		return err
 	}
 	return b.processImageFrom(image)
 }
@@ -308,11 +339,7 @@ func run(b *Builder, args []string, attributes map[string]bool, original string)
 	args = handleJSONArgs(args, attributes)
 
 	if !attributes["json"] {
-		if runtime.GOOS != "windows" {
-			args = append([]string{"/bin/sh", "-c"}, args...)
-		} else {
-			args = append([]string{"cmd", "/S", "/C"}, args...)
-		}
+		args = append(b.getShell(), args...)
 	}
 
 	runCmd := flag.NewFlagSet("run", flag.ContinueOnError)
@@ -377,7 +404,7 @@ func run(b *Builder, args []string, attributes map[string]bool, original string)
 	}
 
 	b.runConfig.Cmd = saveCmd
-	hit, err := b.probeCache()
+	hit, err := b.probeCache(cmd)
 	if err != nil {
 		return err
 	}
@@ -394,7 +421,7 @@ func run(b *Builder, args []string, attributes map[string]bool, original string)
 
 	logrus.Debugf("[BUILDER] Command to be executed: %v", b.runConfig.Cmd)
 
-	c, err := b.create()
+	c, err := b.create(true)
 	if err != nil {
 		return err
 	}
@@ -434,11 +461,7 @@ func cmd(b *Builder, args []string, attributes map[string]bool, original string)
 	cmdSlice := handleJSONArgs(args, attributes)
 
 	if !attributes["json"] {
-		if runtime.GOOS != "windows" {
-			cmdSlice = append([]string{"/bin/sh", "-c"}, cmdSlice...)
-		} else {
-			cmdSlice = append([]string{"cmd", "/S", "/C"}, cmdSlice...)
-		}
+		cmdSlice = append(b.getShell(), cmdSlice...)
 	}
 
 	b.runConfig.Cmd = stringutils.NewStrSlice(cmdSlice...)
@@ -454,6 +477,108 @@ func cmd(b *Builder, args []string, attributes map[string]bool, original string)
 	return nil
 }
 
+// HEALTHCHECK [--interval=DURATION] [--timeout=DURATION] [--retries=N] CMD command
+// HEALTHCHECK NONE
+//
+// Set the command run periodically inside the container to check that it is
+// still healthy, or disable any healthcheck inherited from the base image.
+// Argument handling of the command is the same as RUN.
+//
+func healthcheck(b *Builder, args []string, attributes map[string]bool, original string) error {
+	if len(args) == 0 {
+		return derr.ErrorCodeAtLeastOneArg.WithArgs("HEALTHCHECK")
+	}
+
+	flInterval := b.flags.AddString("interval", "")
+	flTimeout := b.flags.AddString("timeout", "")
+	flRetries := b.flags.AddString("retries", "")
+
+	if err := b.flags.Parse(); err != nil {
+		return err
+	}
+
+	typ := strings.ToUpper(args[0])
+	args = args[1:]
+
+	if typ == "NONE" {
+		if len(args) != 0 {
+			return fmt.Errorf("HEALTHCHECK NONE takes no arguments")
+		}
+		b.runConfig.Healthcheck = &runconfig.HealthConfig{
+			Test: []string{typ},
+		}
+		return b.commit("", b.runConfig.Cmd, fmt.Sprintf("HEALTHCHECK %+v", *b.runConfig.Healthcheck))
+	}
+
+	if b.runConfig.Healthcheck != nil {
+		oldCmd := b.runConfig.Healthcheck.Test
+		if len(oldCmd) > 0 && oldCmd[0] != "NONE" {
+			fmt.Fprintf(b.Stdout, "Note: overriding previous HEALTHCHECK: %v\n", oldCmd)
+		}
+	}
+
+	healthcheck := runconfig.HealthConfig{}
+
+	switch typ {
+	case "CMD":
+		cmdSlice := handleJSONArgs(args, attributes)
+		if len(cmdSlice) == 0 {
+			return fmt.Errorf("Missing command after HEALTHCHECK CMD")
+		}
+
+		if !attributes["json"] {
+			typ = "CMD-SHELL"
+		}
+
+		healthcheck.Test = append([]string{typ}, cmdSlice...)
+	default:
+		return fmt.Errorf("Unknown type %#v in HEALTHCHECK (try CMD)", typ)
+	}
+
+	interval, err := parseOptInterval(flInterval)
+	if err != nil {
+		return err
+	}
+	healthcheck.Interval = interval
+
+	timeout, err := parseOptInterval(flTimeout)
+	if err != nil {
+		return err
+	}
+	healthcheck.Timeout = timeout
+
+	if flRetries.Value != "" {
+		retries, err := strconv.ParseInt(flRetries.Value, 10, 32)
+		if err != nil {
+			return err
+		}
+		if retries < 1 {
+			return fmt.Errorf("--retries must be at least 1 (not %d)", retries)
+		}
+		healthcheck.Retries = int(retries)
+	}
+
+	b.runConfig.Healthcheck = &healthcheck
+	return b.commit("", b.runConfig.Cmd, fmt.Sprintf("HEALTHCHECK %+v", healthcheck))
+}
+
+// parseOptInterval returns the duration of the flag value, or 0 if it is
+// empty. An error is reported if the value is given and is not positive.
+func parseOptInterval(f *Flag) (time.Duration, error) {
+	s := f.Value
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("Interval %#v must be positive", f.name)
+	}
+	return d, nil
+}
+
 // ENTRYPOINT /usr/sbin/nginx
 //
 // Set the entrypoint (which defaults to sh -c on linux, or cmd /S /C on Windows) to
@@ -478,11 +603,7 @@ func entrypoint(b *Builder, args []string, attributes map[string]bool, original
 		b.runConfig.Entrypoint = nil
 	default:
 		// ENTRYPOINT echo hi
-		if runtime.GOOS != "windows" {
-			b.runConfig.Entrypoint = stringutils.NewStrSlice("/bin/sh", "-c", parsed[0])
-		} else {
-			b.runConfig.Entrypoint = stringutils.NewStrSlice("cmd", "/S", "/C", parsed[0])
-		}
+		b.runConfig.Entrypoint = stringutils.NewStrSlice(append(b.getShell(), parsed[0])...)
 	}
 
 	// when setting the entrypoint if a CMD was not explicitly set then
@@ -647,3 +768,40 @@ func arg(b *Builder, args []string, attributes map[string]bool, original string)
 
 	return b.commit("", b.runConfig.Cmd, fmt.Sprintf("ARG %s", arg))
 }
+
+// SHELL ["executable", "param1", "param2"]
+//
+// Set the shell used to run the shell form of RUN, CMD and ENTRYPOINT.
+// The shell is kept in the image, so that it's also used by the ONBUILD
+// triggers and by the images built from it.
+//
+func shell(b *Builder, args []string, attributes map[string]bool, original string) error {
+	if err := b.flags.Parse(); err != nil {
+		return err
+	}
+
+	shellSlice := handleJSONArgs(args, attributes)
+	switch {
+	case len(shellSlice) == 0:
+		// SHELL []
+		return derr.ErrorCodeAtLeastOneArg.WithArgs("SHELL")
+	case !attributes["json"]:
+		// SHELL powershell -command
+		return derr.ErrorCodeShellNotJSON
+	}
+
+	b.runConfig.Shell = stringutils.NewStrSlice(shellSlice...)
+	return b.commit("", b.runConfig.Cmd, fmt.Sprintf("SHELL %q", shellSlice))
+}
+
+// getShell returns the shell used to run the shell form of RUN, CMD and
+// ENTRYPOINT, which is the one set with SHELL or the default of the platform.
+func (b *Builder) getShell() []string {
+	if b.runConfig.Shell.Len() > 0 {
+		return append([]string(nil), b.runConfig.Shell.Slice()...)
+	}
+	if runtime.GOOS != "windows" {
+		return []string{"/bin/sh", "-c"}
+	}
+	return []string{"cmd", "/S", "/C"}
+}
@@ -0,0 +1,159 @@
+package dockerfile
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/builder"
+	"github.com/docker/docker/pkg/stringutils"
+	"github.com/docker/docker/pkg/symlink"
+	"github.com/docker/docker/runconfig"
+)
+
+// imageContext is a read-only build Context backed by the root filesystem of
+// an image, which is used to copy files from a previous build stage or from
+// another image with `COPY --from`.
+//
+// The files of an image never change, so they are hashed by the ID of the
+// image and their path instead of their content.
+type imageContext struct {
+	root    string
+	imageID string
+	release func() error
+}
+
+// imageContext returns a Context for the root filesystem of the build stage
+// or image referred to by `from`, which is either the name of a previous
+// stage, its index, or the name of an image. The Context has to be closed to
+// release the root filesystem.
+func (b *Builder) imageContext(from string) (builder.Context, error) {
+	if n := len(b.stages); n > 0 && b.stages[n-1].name != "" && b.stages[n-1].name == strings.ToLower(from) {
+		return nil, fmt.Errorf("Build stage %s can't copy files from itself", from)
+	}
+
+	var imageID string
+	if stage := b.lookupStage(from); stage != nil {
+		imageID = stage.image
+	} else if index, err := strconv.Atoi(from); err == nil {
+		stages := b.completedStages()
+		if index < 0 || index >= len(stages) {
+			return nil, fmt.Errorf("Invalid build stage index %d for COPY --from", index)
+		}
+		imageID = stages[index].image
+	} else {
+		img, err := b.getImage(from)
+		if err != nil {
+			return nil, err
+		}
+		imageID = img.ID().String()
+	}
+	if imageID == "" {
+		return nil, fmt.Errorf("Build stage %s has no files to copy from", from)
+	}
+
+	config := &runconfig.Config{Image: imageID}
+	if runtime.GOOS != "windows" {
+		config.Cmd = stringutils.NewStrSlice("/bin/sh", "-c", "#(nop) COPY --from="+from)
+	} else {
+		config.Cmd = stringutils.NewStrSlice("cmd", "/S", "/C", "REM (nop) COPY --from="+from)
+	}
+	container, _, err := b.docker.Create(config, nil)
+	if err != nil {
+		return nil, err
+	}
+	release := func() error {
+		b.docker.Unmount(container)
+		return b.removeContainer(container.ID)
+	}
+	root, err := container.GetResourcePath(string(os.PathSeparator))
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &imageContext{root: root, imageID: imageID, release: release}, nil
+}
+
+func (c *imageContext) Close() error {
+	return c.release()
+}
+
+func (c *imageContext) Open(path string) (io.ReadCloser, error) {
+	cleanpath, fullpath, err := c.normalize(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(fullpath)
+	if err != nil {
+		return nil, hidePath(err, cleanpath)
+	}
+	return f, nil
+}
+
+func (c *imageContext) Stat(path string) (string, builder.FileInfo, error) {
+	cleanpath, fullpath, err := c.normalize(path)
+	if err != nil {
+		return "", nil, err
+	}
+	st, err := os.Lstat(fullpath)
+	if err != nil {
+		return "", nil, hidePath(err, cleanpath)
+	}
+	rel, err := filepath.Rel(c.root, fullpath)
+	if err != nil {
+		return "", nil, err
+	}
+	fi := &builder.HashedFileInfo{
+		FileInfo: builder.PathFileInfo{FileInfo: st, FilePath: fullpath, FileName: filepath.Base(cleanpath)},
+		FileHash: c.hash(rel),
+	}
+	return rel, fi, nil
+}
+
+func (c *imageContext) Walk(root string, walkFn builder.WalkFunc) error {
+	root = filepath.Join(c.root, filepath.Join(string(filepath.Separator), root))
+	return filepath.Walk(root, func(fullpath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(c.root, fullpath)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		fi := &builder.HashedFileInfo{
+			FileInfo: builder.PathFileInfo{FileInfo: info, FilePath: fullpath},
+			FileHash: c.hash(rel),
+		}
+		return walkFn(rel, fi, nil)
+	})
+}
+
+func (c *imageContext) hash(rel string) string {
+	return c.imageID + ":" + rel
+}
+
+// normalize resolves path inside the root filesystem of the image, following
+// symlinks without leaving it.
+func (c *imageContext) normalize(path string) (cleanpath, fullpath string, err error) {
+	cleanpath = filepath.Clean(string(os.PathSeparator) + path)[1:]
+	fullpath, err = symlink.FollowSymlinkInScope(filepath.Join(c.root, path), c.root)
+	if err != nil {
+		return "", "", fmt.Errorf("Forbidden path outside the image: %s (%s)", path, fullpath)
+	}
+	return cleanpath, fullpath, nil
+}
+
+// hidePath replaces the path on the host in err with the path in the image.
+func hidePath(err error, cleanpath string) error {
+	if err, ok := err.(*os.PathError); ok {
+		err.Path = cleanpath
+	}
+	return err
+}
@@ -13,6 +13,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"sort"
 	"strings"
@@ -21,8 +22,10 @@ import (
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/api"
 	"github.com/docker/docker/builder"
+	"github.com/docker/docker/builder/dockerfile/command"
 	"github.com/docker/docker/builder/dockerfile/parser"
 	"github.com/docker/docker/daemon"
+	derr "github.com/docker/docker/errors"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/httputils"
@@ -55,12 +58,12 @@ func (b *Builder) commit(id string, autoCmd *stringutils.StrSlice, comment strin
 		}
 		defer func(cmd *stringutils.StrSlice) { b.runConfig.Cmd = cmd }(cmd)
 
-		if hit, err := b.probeCache(); err != nil {
+		if hit, err := b.probeCache(autoCmd); err != nil {
 			return err
 		} else if hit {
 			return nil
 		}
-		container, err := b.create()
+		container, err := b.create(false)
 		if err != nil {
 			return err
 		}
@@ -98,8 +101,8 @@ type copyInfo struct {
 	decompress bool
 }
 
-func (b *Builder) runContextCommand(args []string, allowRemote bool, allowLocalDecompression bool, cmdName string) error {
-	if b.context == nil {
+func (b *Builder) runContextCommand(context builder.Context, args []string, allowRemote bool, allowLocalDecompression bool, cmdName string) error {
+	if context == nil {
 		return fmt.Errorf("No context given. Impossible to use %s", cmdName)
 	}
 
@@ -135,7 +138,7 @@ func (b *Builder) runContextCommand(args []string, allowRemote bool, allowLocalD
 			continue
 		}
 		// not a URL
-		subInfos, err := b.calcCopyInfo(cmdName, orig, allowLocalDecompression, true)
+		subInfos, err := b.calcCopyInfo(context, cmdName, orig, allowLocalDecompression, true)
 		if err != nil {
 			return err
 		}
@@ -185,7 +188,7 @@ func (b *Builder) runContextCommand(args []string, allowRemote bool, allowLocalD
 	}
 	defer func(cmd *stringutils.StrSlice) { b.runConfig.Cmd = cmd }(cmd)
 
-	if hit, err := b.probeCache(); err != nil {
+	if hit, err := b.probeCache(cmd); err != nil {
 		return err
 	} else if hit {
 		return nil
@@ -319,7 +322,7 @@ func (b *Builder) download(srcURL string) (fi builder.FileInfo, err error) {
 	return &builder.HashedFileInfo{FileInfo: builder.PathFileInfo{FileInfo: tmpFileSt, FilePath: tmpFileName}, FileHash: hash}, nil
 }
 
-func (b *Builder) calcCopyInfo(cmdName, origPath string, allowLocalDecompression, allowWildcards bool) ([]copyInfo, error) {
+func (b *Builder) calcCopyInfo(context builder.Context, cmdName, origPath string, allowLocalDecompression, allowWildcards bool) ([]copyInfo, error) {
 
 	// Work in daemon-specific OS filepath semantics
 	origPath = filepath.FromSlash(origPath)
@@ -332,7 +335,7 @@ func (b *Builder) calcCopyInfo(cmdName, origPath string, allowLocalDecompression
 	// Deal with wildcards
 	if allowWildcards && containsWildcards(origPath) {
 		var copyInfos []copyInfo
-		if err := b.context.Walk("", func(path string, info builder.FileInfo, err error) error {
+		if err := context.Walk("", func(path string, info builder.FileInfo, err error) error {
 			if err != nil {
 				return err
 			}
@@ -346,7 +349,7 @@ func (b *Builder) calcCopyInfo(cmdName, origPath string, allowLocalDecompression
 
 			// Note we set allowWildcards to false in case the name has
 			// a * in it
-			subInfos, err := b.calcCopyInfo(cmdName, path, allowLocalDecompression, false)
+			subInfos, err := b.calcCopyInfo(context, cmdName, path, allowLocalDecompression, false)
 			if err != nil {
 				return err
 			}
@@ -360,7 +363,7 @@ func (b *Builder) calcCopyInfo(cmdName, origPath string, allowLocalDecompression
 
 	// Must be a dir or a file
 
-	statPath, fi, err := b.context.Stat(origPath)
+	statPath, fi, err := context.Stat(origPath)
 	if err != nil {
 		return nil, err
 	}
@@ -379,7 +382,7 @@ func (b *Builder) calcCopyInfo(cmdName, origPath string, allowLocalDecompression
 	}
 	// Must be a dir
 	var subfiles []string
-	err = b.context.Walk(statPath, func(path string, info builder.FileInfo, err error) error {
+	err = context.Walk(statPath, func(path string, info builder.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -411,8 +414,72 @@ func containsWildcards(name string) bool {
 	return false
 }
 
+// validStageName matches the names that can be given to a build stage with
+// `FROM <image> AS <name>`.
+var validStageName = regexp.MustCompile(`^[a-z][a-z0-9_.-]*$`)
+
+// startStage completes the current build stage, if any, and starts a new one
+// with the given name. Nothing but the image of a stage is carried over to
+// the next one.
+func (b *Builder) startStage(name string) {
+	if n := len(b.stages); n > 0 {
+		b.stages[n-1].image = b.image
+		b.image = ""
+		b.noBaseImage = false
+		b.runConfig = new(runconfig.Config)
+		b.maintainer = ""
+		b.cmdSet = false
+		b.cacheBusted = false
+	}
+	b.stages = append(b.stages, &buildStage{name: name})
+}
+
+// lookupStage returns the completed build stage with the given name, or nil
+// if there is none.
+func (b *Builder) lookupStage(name string) *buildStage {
+	name = strings.ToLower(name)
+	for _, stage := range b.completedStages() {
+		if stage.name != "" && stage.name == name {
+			return stage
+		}
+	}
+	return nil
+}
+
+// completedStages returns the build stages before the current one.
+func (b *Builder) completedStages() []*buildStage {
+	if len(b.stages) == 0 {
+		return nil
+	}
+	return b.stages[:len(b.stages)-1]
+}
+
+// getImage returns the image with the given name, pulling it if it's not
+// found locally or if the build was started with --pull.
+func (b *Builder) getImage(name string) (*image.Image, error) {
+	var (
+		img *image.Image
+		err error
+	)
+	// TODO: don't use `name`, instead resolve it to a digest
+	if !b.Pull {
+		img, err = b.docker.LookupImage(name)
+		// TODO: shouldn't we error out if error is different from "not found" ?
+	}
+	if img == nil {
+		img, err = b.docker.Pull(name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return img, nil
+}
+
 func (b *Builder) processImageFrom(img *image.Image) error {
 	b.image = img.ID().String()
+	if n := len(b.stages); n > 0 {
+		b.stages[n-1].base = b.image
+	}
 
 	if img.Config != nil {
 		b.runConfig = img.Config
@@ -460,13 +527,114 @@ func (b *Builder) processImageFrom(img *image.Image) error {
 	return nil
 }
 
+// squash replaces the image of the build with an image in which all the
+// layers added on top of the base image of the last stage are squashed into
+// one. The intermediate images are left untouched, so that they can still
+// be used as cache by later builds.
+func (b *Builder) squash() error {
+	var base string
+	if n := len(b.stages); n > 0 {
+		base = b.stages[n-1].base
+	}
+	if base == b.image {
+		// no layer was added to the base image
+		return nil
+	}
+	fmt.Fprintf(b.Stdout, "Squashing layers on top of %s\n", baseName(base))
+	id, err := b.docker.SquashImage(b.image, base)
+	if err != nil {
+		return err
+	}
+	b.image = id
+	fmt.Fprintf(b.Stdout, " ---> %s\n", stringid.TruncateID(id))
+	return nil
+}
+
+// baseName returns the short ID of a base image, or `scratch`.
+func baseName(id string) string {
+	if id == "" {
+		return NoBaseImageSpecifier
+	}
+	return stringid.TruncateID(id)
+}
+
+// addLabels sets the labels given with `--label` on the image, and commits
+// them. It is the last step of the build, so that the cache of the previous
+// steps is still used when the labels change.
+func (b *Builder) addLabels() error {
+	keys := make([]string, 0, len(b.Labels))
+	for k := range b.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	if b.runConfig.Labels == nil {
+		b.runConfig.Labels = map[string]string{}
+	}
+
+	commitStr := "LABEL"
+	for _, k := range keys {
+		commitStr += fmt.Sprintf(" %q=%q", k, b.Labels[k])
+		b.runConfig.Labels[k] = b.Labels[k]
+	}
+	return b.commit("", b.runConfig.Cmd, commitStr)
+}
+
+// selectTarget removes the instructions of the build stages following the
+// stage given with `--target` from the Dockerfile, so that the build stops
+// once the target stage is built.
+func (b *Builder) selectTarget() error {
+	children := b.dockerfile.Children
+	for i, n := range children {
+		if n.Value != command.From || !strings.EqualFold(stageName(n), b.Target) {
+			continue
+		}
+		for j := i + 1; j < len(children); j++ {
+			if children[j].Value == command.From {
+				b.dockerfile.Children = children[:j]
+				break
+			}
+		}
+		return nil
+	}
+	return derr.ErrorCodeTargetStageNotFound.WithArgs(b.Target)
+}
+
+// stageName returns the name given to the build stage started by the FROM
+// instruction `n`, if any.
+func stageName(n *parser.Node) string {
+	var args []string
+	for next := n.Next; next != nil; next = next.Next {
+		args = append(args, next.Value)
+	}
+	if len(args) != 3 || !strings.EqualFold(args[1], "AS") {
+		return ""
+	}
+	return args[2]
+}
+
+// lookupCacheFrom resolves the images given with `--cache-from`. The images
+// which are not found are ignored, as the build cache is only an optimization.
+func (b *Builder) lookupCacheFrom() {
+	for _, name := range b.CacheFrom {
+		img, err := b.docker.LookupImage(name)
+		if err != nil {
+			fmt.Fprintf(b.Stderr, "Ignoring cache source %s: %v\n", name, err)
+			continue
+		}
+		b.cacheFrom = append(b.cacheFrom, img.ID().String())
+	}
+}
+
 // probeCache checks if `b.docker` implements builder.ImageCache and image-caching
 // is enabled (`b.UseCache`).
-// If so attempts to look up the current `b.image` and `b.runConfig` pair with `b.docker`.
+// If so attempts to look up the current `b.image` and `b.runConfig` pair with `b.docker`,
+// and then in the history of the images given with `--cache-from`, for which
+// `autoCmd` is the command of the image to create.
 // If an image is found, probeCache returns `(true, nil)`.
 // If no image is found, it returns `(false, nil)`.
 // If there is any error, it returns `(false, err)`.
-func (b *Builder) probeCache() (bool, error) {
+func (b *Builder) probeCache(autoCmd *stringutils.StrSlice) (bool, error) {
 	c, ok := b.docker.(builder.ImageCache)
 	if !ok || !b.UseCache || b.cacheBusted {
 		return false, nil
@@ -475,6 +643,15 @@ func (b *Builder) probeCache() (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	if cf, ok := b.docker.(builder.ImageCacheFrom); ok && len(cache) == 0 && len(b.cacheFrom) > 0 {
+		imageCfg := *b.runConfig
+		imageCfg.Image = b.image
+		imageCfg.Cmd = autoCmd
+		cache, err = cf.GetCachedImageFrom(b.cacheFrom, b.image, b.runConfig, &imageCfg)
+		if err != nil {
+			return false, err
+		}
+	}
 	if len(cache) == 0 {
 		logrus.Debugf("[BUILDER] Cache miss: %s", b.runConfig.Cmd)
 		b.cacheBusted = true
@@ -484,6 +661,9 @@ func (b *Builder) probeCache() (bool, error) {
 	fmt.Fprintf(b.Stdout, " ---> Using cache\n")
 	logrus.Debugf("[BUILDER] Use cached version: %s", b.runConfig.Cmd)
 	b.image = string(cache)
+	if b.step != nil {
+		b.step.Cached = true
+	}
 
 	// TODO: remove once Commit can take a tag parameter.
 	b.docker.Retain(b.id, b.image)
@@ -492,7 +672,9 @@ func (b *Builder) probeCache() (bool, error) {
 	return true, nil
 }
 
-func (b *Builder) create() (*daemon.Container, error) {
+// create creates the container of a build step, in which the build secrets
+// are mounted if withSecrets is set, which is only the case for RUN.
+func (b *Builder) create(withSecrets bool) (*daemon.Container, error) {
 	if b.image == "" && !b.noBaseImage {
 		return nil, fmt.Errorf("Please provide a source image with `from` prior to run")
 	}
@@ -512,15 +694,21 @@ func (b *Builder) create() (*daemon.Container, error) {
 
 	// TODO: why not embed a hostconfig in builder?
 	hostConfig := &runconfig.HostConfig{
-		Isolation: b.Isolation,
-		ShmSize:   b.ShmSize,
-		Resources: resources,
+		Isolation:   b.Isolation,
+		ShmSize:     b.ShmSize,
+		Resources:   resources,
+		NetworkMode: runconfig.NetworkMode(b.NetworkMode),
+		ExtraHosts:  b.ExtraHosts,
 	}
 
 	config := *b.runConfig
 
 	// Create the container
-	c, warnings, err := b.docker.Create(b.runConfig, hostConfig)
+	create := b.docker.Create
+	if withSecrets {
+		create = b.docker.CreateWithSecrets
+	}
+	c, warnings, err := create(b.runConfig, hostConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -545,7 +733,12 @@ func (b *Builder) create() (*daemon.Container, error) {
 func (b *Builder) run(c *daemon.Container) error {
 	var errCh chan error
 	if b.Verbose {
-		errCh = c.Attach(nil, b.Stdout, b.Stderr)
+		stdout, stderr := b.Stdout, b.Stderr
+		if b.step != nil {
+			stdout = io.MultiWriter(stdout, b.step)
+			stderr = io.MultiWriter(stderr, b.step)
+		}
+		errCh = c.Attach(nil, stdout, stderr)
 	}
 
 	//start the container
@@ -0,0 +1,97 @@
+package dockerfile
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/builder/dockerfile/parser"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// maxStepOutput is the maximum size of the output of a step which is
+// reported, only its last lines being kept beyond it.
+const maxStepOutput = 64 * 1024
+
+// buildStep tracks the current step of the build, which is reported with
+// StepProgress once it is done.
+type buildStep struct {
+	jsonmessage.JSONBuildStep
+	start time.Time
+
+	mu        sync.Mutex
+	output    []byte // last bytes of the output of the container of the step
+	truncated bool   // whether the beginning of the output was dropped
+}
+
+// Write records the output of the container of the step. It is called
+// concurrently for the stdout and the stderr of the container. Only the last
+// maxStepOutput bytes are kept, so that a step writing a lot does not use
+// unbounded memory.
+func (s *buildStep) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.output = append(s.output, p...)
+	if len(s.output) > 2*maxStepOutput {
+		s.output = append(s.output[:0], s.output[len(s.output)-maxStepOutput:]...)
+		s.truncated = true
+	}
+	return len(p), nil
+}
+
+// lines returns the last lines of the output of the step, within
+// maxStepOutput bytes. The first line is dropped if it was truncated.
+func (s *buildStep) lines() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	output, truncated := s.output, s.truncated
+	if len(output) > maxStepOutput {
+		output = output[len(output)-maxStepOutput:]
+		truncated = true
+	}
+	if truncated {
+		if i := bytes.IndexByte(output, '\n'); i >= 0 {
+			output = output[i+1:]
+		}
+	}
+	if len(output) == 0 {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSuffix(string(output), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+// startStep starts tracking the step `stepN` of the build, for the
+// instruction `ast`, if the progress of the steps is reported.
+func (b *Builder) startStep(stepN int, ast *parser.Node) {
+	if b.StepProgress == nil {
+		return
+	}
+	b.step = &buildStep{start: time.Now()}
+	b.step.Index = stepN + 1
+	b.step.Total = len(b.dockerfile.Children)
+	b.step.Instruction = strings.TrimSpace(ast.Original)
+}
+
+// finishStep reports the current step of the build, which ended with the
+// error `err`.
+func (b *Builder) finishStep(err error) {
+	step := b.step
+	if step == nil {
+		return
+	}
+	b.step = nil
+
+	step.Duration = time.Since(step.start)
+	step.Output = step.lines()
+	if err != nil {
+		step.Error = err.Error()
+	} else {
+		step.ImageID = b.image
+	}
+	b.StepProgress(&step.JSONBuildStep)
+}
@@ -0,0 +1,41 @@
+package dockerfile
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildStepLines(t *testing.T) {
+	step := &buildStep{}
+	if lines := step.lines(); lines != nil {
+		t.Fatalf("Expected no lines, got %v", lines)
+	}
+	fmt.Fprint(step, "hello\r\n")
+	fmt.Fprint(step, "world\n")
+	if lines := step.lines(); !reflect.DeepEqual(lines, []string{"hello", "world"}) {
+		t.Fatalf("Unexpected lines %v", lines)
+	}
+}
+
+func TestBuildStepLinesTruncated(t *testing.T) {
+	step := &buildStep{}
+	line := strings.Repeat("x", 99)
+	n := 3 * maxStepOutput / 100
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(step, "%s\n", line)
+	}
+	if len(step.output) > 2*maxStepOutput {
+		t.Fatalf("Expected at most %d bytes of output to be kept, got %d", 2*maxStepOutput, len(step.output))
+	}
+	lines := step.lines()
+	if len(lines) != maxStepOutput/100 {
+		t.Fatalf("Expected %d lines, got %d", maxStepOutput/100, len(lines))
+	}
+	for _, l := range lines {
+		if l != line {
+			t.Fatalf("Expected the line %q, got %q", line, l)
+		}
+	}
+}
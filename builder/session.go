@@ -0,0 +1,203 @@
+package builder
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/chrootarchive"
+	"github.com/docker/docker/utils"
+)
+
+var validSessionID = regexp.MustCompile(`^` + utils.RestrictedNameChars + `+$`)
+
+const (
+	// sessionExpiry is how long the build context of a session is kept
+	// after its last use.
+	sessionExpiry = 24 * time.Hour
+	// expiryInterval is the interval between the removals of the expired
+	// build contexts.
+	expiryInterval = time.Hour
+)
+
+// ContextSessions keeps the build contexts of the build sessions, so that the
+// client of a session only has to send the files of its build context which
+// changed since the previous build of the session.
+//
+// The build context of a session is kept in a directory, to which the changes
+// sent by the client are applied as a layer: the files which were deleted are
+// sent as whiteout files. It is removed once the session has not been used
+// for sessionExpiry, or when the session is deleted.
+type ContextSessions struct {
+	root string
+
+	mu       sync.Mutex
+	sessions map[string]*contextSession // sessions which have a build context or are in use
+}
+
+// contextSession is a session of ContextSessions. Its lock is held while its
+// build context is used.
+type contextSession struct {
+	sync.Mutex
+	id string
+
+	// refs and lastUsed are protected by the lock of ContextSessions
+	refs     int       // number of users holding or waiting for the lock
+	lastUsed time.Time // time the lock was last released
+}
+
+// NewContextSessions returns a ContextSessions keeping the build contexts in
+// root. The build contexts kept from a previous run of the daemon are removed,
+// as a client sends all its files when its session has no build context.
+func NewContextSessions(root string) (*ContextSessions, error) {
+	if err := os.RemoveAll(root); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, err
+	}
+	s := &ContextSessions{root: root, sessions: make(map[string]*contextSession)}
+	go func() {
+		for range time.Tick(expiryInterval) {
+			s.expire(sessionExpiry)
+		}
+	}()
+	return s, nil
+}
+
+// lock locks the session `id`, so that its build context is only used by one
+// build at a time. The session has to be released with unlock.
+func (s *ContextSessions) lock(id string) (*contextSession, error) {
+	if !validSessionID.MatchString(id) {
+		return nil, fmt.Errorf("Invalid build session ID %q, only %s are allowed", id, utils.RestrictedNameChars)
+	}
+	s.mu.Lock()
+	cs, exists := s.sessions[id]
+	if !exists {
+		cs = &contextSession{id: id}
+		s.sessions[id] = cs
+	}
+	cs.refs++
+	s.mu.Unlock()
+	cs.Lock()
+	return cs, nil
+}
+
+// unlock releases a session locked with lock. A session without a build
+// context is forgotten once it is not used anymore.
+func (s *ContextSessions) unlock(cs *contextSession) {
+	cs.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cs.lastUsed = time.Now()
+	cs.refs--
+	if cs.refs == 0 {
+		if _, err := os.Lstat(filepath.Join(s.root, cs.id)); os.IsNotExist(err) {
+			delete(s.sessions, cs.id)
+		}
+	}
+}
+
+// expire removes the build contexts of the sessions which have not been used
+// for maxAge.
+func (s *ContextSessions) expire(maxAge time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, cs := range s.sessions {
+		if cs.refs > 0 || time.Since(cs.lastUsed) < maxAge {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(s.root, id)); err != nil {
+			logrus.Errorf("Failed to remove the build context of the build session %s: %v", id, err)
+			continue
+		}
+		delete(s.sessions, id)
+	}
+}
+
+// Delete removes the build context of the session `id`, so that the next
+// build of the session sends all its files.
+func (s *ContextSessions) Delete(id string) error {
+	cs, err := s.lock(id)
+	if err != nil {
+		return err
+	}
+	defer s.unlock(cs)
+	return os.RemoveAll(filepath.Join(s.root, id))
+}
+
+// Get returns the files of the build context of the session `id`, which has
+// no files if no build used the session yet.
+func (s *ContextSessions) Get(id string) (*types.BuildSession, error) {
+	cs, err := s.lock(id)
+	if err != nil {
+		return nil, err
+	}
+	defer s.unlock(cs)
+
+	session := &types.BuildSession{ID: id, Files: []types.BuildContextFile{}}
+	dir := filepath.Join(s.root, id)
+	if _, err := os.Lstat(dir); os.IsNotExist(err) {
+		return session, nil
+	}
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		session.Files = append(session.Files, types.BuildContextFile{
+			Name:    filepath.ToSlash(rel),
+			Mode:    info.Mode(),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// MakeContext applies the tar stream `changes` of the files which changed
+// since the previous build of the session `id` to its build context, and
+// returns a build Context from the resulting files.
+//
+// Closing changes has to be done by the caller.
+func (s *ContextSessions) MakeContext(id string, changes io.Reader) (ModifiableContext, error) {
+	cs, err := s.lock(id)
+	if err != nil {
+		return nil, err
+	}
+	defer s.unlock(cs)
+
+	dir := filepath.Join(s.root, id)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	if _, err := chrootarchive.ApplyLayer(dir, changes); err != nil {
+		// The build context is in an unknown state, the client has to
+		// send all its files again.
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	// The build itself uses a copy of the build context, which it can
+	// modify and which is not shared with the next builds of the session.
+	tarStream, err := archive.Tar(dir, archive.Uncompressed)
+	if err != nil {
+		return nil, err
+	}
+	defer tarStream.Close()
+	return MakeTarSumContext(tarStream)
+}
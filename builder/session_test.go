@@ -0,0 +1,158 @@
+package builder
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/reexec"
+)
+
+func init() {
+	reexec.Init()
+}
+
+func makeTar(t *testing.T, files map[string]string) *bytes.Buffer {
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+	for _, name := range []string{"dir/", "dir/bar", "foo", ".wh.foo", "dir/.wh.bar", "baz"} {
+		content, exists := files[name]
+		if !exists {
+			continue
+		}
+		hdr := &tar.Header{Name: name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(content))}
+		if name[len(name)-1] == '/' {
+			hdr.Mode, hdr.Typeflag = 0755, tar.TypeDir
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+func sessionFiles(t *testing.T, sessions *ContextSessions, id string) []string {
+	session, err := sessions.Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.ID != id {
+		t.Fatalf("Expected the session %s, got %s", id, session.ID)
+	}
+	var files []string
+	for _, f := range session.Files {
+		files = append(files, f.Name)
+	}
+	return files
+}
+
+func TestContextSessions(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-test-build-sessions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	sessions, err := NewContextSessions(filepath.Join(root, "sessions"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sessions.Get("../foo"); err == nil {
+		t.Fatal("Expected an error for an invalid session ID")
+	}
+	if files := sessionFiles(t, sessions, "test"); len(files) != 0 {
+		t.Fatalf("Expected a new session to have no files, got %v", files)
+	}
+
+	ctx, err := sessions.MakeContext("test", makeTar(t, map[string]string{"dir/": "", "dir/bar": "bar", "foo": "foo"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx.Close()
+	if files := sessionFiles(t, sessions, "test"); !reflect.DeepEqual(files, []string{"dir", "dir/bar", "foo"}) {
+		t.Fatalf("Unexpected files in the session: %v", files)
+	}
+
+	// the changes are applied to the files of the previous build
+	ctx, err = sessions.MakeContext("test", makeTar(t, map[string]string{".wh.foo": "", "dir/.wh.bar": "", "baz": "baz"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ctx.Close()
+	if files := sessionFiles(t, sessions, "test"); !reflect.DeepEqual(files, []string{"baz", "dir"}) {
+		t.Fatalf("Unexpected files in the session: %v", files)
+	}
+	if _, _, err := ctx.Stat("foo"); !os.IsNotExist(err) {
+		t.Fatalf("Expected foo to be removed from the context, got %v", err)
+	}
+	_, fi, err := ctx.Stat("baz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashed, ok := fi.(*HashedFileInfo); !ok || hashed.Hash() == "baz" {
+		t.Fatalf("Expected baz to have a checksum, got %v", fi)
+	}
+
+	// the build can modify its context without modifying the session
+	if err := ctx.Remove("baz"); err != nil {
+		t.Fatal(err)
+	}
+	if files := sessionFiles(t, sessions, "test"); !reflect.DeepEqual(files, []string{"baz", "dir"}) {
+		t.Fatalf("Unexpected files in the session: %v", files)
+	}
+}
+
+func TestContextSessionsExpiry(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-test-build-sessions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	sessions, err := NewContextSessions(filepath.Join(root, "sessions"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the sessions without a build context are forgotten
+	sessionFiles(t, sessions, "empty")
+	if len(sessions.sessions) != 0 {
+		t.Fatalf("Expected no sessions, got %v", sessions.sessions)
+	}
+
+	for _, id := range []string{"old", "new", "deleted"} {
+		ctx, err := sessions.MakeContext(id, makeTar(t, map[string]string{"foo": "foo"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx.Close()
+	}
+	sessions.sessions["old"].lastUsed = time.Now().Add(-2 * time.Hour)
+	sessions.expire(time.Hour)
+	if files := sessionFiles(t, sessions, "old"); len(files) != 0 {
+		t.Fatalf("Expected the expired session to have no files, got %v", files)
+	}
+	if files := sessionFiles(t, sessions, "new"); !reflect.DeepEqual(files, []string{"foo"}) {
+		t.Fatalf("Unexpected files in the session: %v", files)
+	}
+
+	if err := sessions.Delete("deleted"); err != nil {
+		t.Fatal(err)
+	}
+	if files := sessionFiles(t, sessions, "deleted"); len(files) != 0 {
+		t.Fatalf("Expected the deleted session to have no files, got %v", files)
+	}
+	if _, exists := sessions.sessions["deleted"]; exists || len(sessions.sessions) != 1 {
+		t.Fatalf("Expected only the new session, got %v", sessions.sessions)
+	}
+}
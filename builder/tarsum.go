@@ -1,10 +1,13 @@
 package builder
 
 import (
+	"archive/tar"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"runtime"
 
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/chrootarchive"
@@ -16,6 +19,9 @@ import (
 type tarSumContext struct {
 	root string
 	sums tarsum.FileInfoSums
+	// index of the sums by file name, in which the first sum of a file
+	// wins, as with GetFile
+	index map[string]tarsum.FileInfoSumInterface
 }
 
 func (c *tarSumContext) Close() error {
@@ -63,7 +69,7 @@ func (c *tarSumContext) Stat(path string) (string, FileInfo, error) {
 	sum := path
 	// Use the checksum of the followed path(not the possible symlink) because
 	// this is the file that is actually copied.
-	if tsInfo := c.sums.GetFile(rel); tsInfo != nil {
+	if tsInfo := c.index[rel]; tsInfo != nil {
 		sum = tsInfo.Sum()
 	}
 	fi := &HashedFileInfo{PathFileInfo{st, fullpath, filepath.Base(cleanpath)}, sum}
@@ -74,8 +80,9 @@ func (c *tarSumContext) Stat(path string) (string, FileInfo, error) {
 //
 // It extracts the tar stream to a temporary folder that is deleted as soon as
 // the Context is closed.
-// As the extraction happens, a tarsum is calculated for every file, and the set of
-// all those sums then becomes the source of truth for all operations on this Context.
+// Once the extraction is done, a tarsum is calculated for every file, hashing
+// the files in parallel, and the set of all those sums then becomes the source
+// of truth for all operations on this Context.
 //
 // Closing tarStream has to be done by the caller.
 func MakeTarSumContext(tarStream io.Reader) (ModifiableContext, error) {
@@ -99,20 +106,71 @@ func MakeTarSumContext(tarStream io.Reader) (ModifiableContext, error) {
 		return nil, err
 	}
 
-	sum, err := tarsum.NewTarSum(decompressedStream, true, tarsum.Version1)
+	// The headers of the entries are read as the stream is extracted, as
+	// they are part of the sums of the files.
+	var (
+		headers    []*tar.Header
+		headersErr error
+		done       = make(chan struct{})
+	)
+	pr, pw := io.Pipe()
+	go func() {
+		headers, headersErr = readTarHeaders(pr)
+		close(done)
+	}()
+	err = chrootarchive.Untar(io.TeeReader(decompressedStream, pw), root, nil)
+	pw.Close()
+	<-done
+	if err == nil {
+		err = headersErr
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	if err := chrootarchive.Untar(sum, root, nil); err != nil {
+	tsc.sums, err = tarsum.SumFiles(headers, tsc.openEntry, tarsum.Version1, nil, runtime.NumCPU())
+	if err != nil {
 		return nil, err
 	}
-
-	tsc.sums = sum.GetSums()
+	tsc.index = make(map[string]tarsum.FileInfoSumInterface, len(tsc.sums))
+	for _, fis := range tsc.sums {
+		if _, exists := tsc.index[fis.Name()]; !exists {
+			tsc.index[fis.Name()] = fis
+		}
+	}
 
 	return tsc, nil
 }
 
+// readTarHeaders returns the headers of the entries of a tar stream. The
+// stream is always read until its end.
+func readTarHeaders(r io.Reader) ([]*tar.Header, error) {
+	defer io.Copy(ioutil.Discard, r)
+
+	var headers []*tar.Header
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return headers, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, hdr)
+	}
+}
+
+// openEntry opens the file extracted from the entry `hdr` of the tar stream
+// of the context.
+func (c *tarSumContext) openEntry(hdr *tar.Header) (io.ReadCloser, error) {
+	fullpath, err := symlink.FollowSymlinkInScope(filepath.Join(c.root, filepath.FromSlash(hdr.Name)), c.root)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(fullpath)
+}
+
 func (c *tarSumContext) normalize(path string) (cleanpath, fullpath string, err error) {
 	cleanpath = filepath.Clean(string(os.PathSeparator) + path)[1:]
 	fullpath, err = symlink.FollowSymlinkInScope(filepath.Join(c.root, path), c.root)
@@ -138,7 +196,7 @@ func (c *tarSumContext) Walk(root string, walkFn WalkFunc) error {
 		}
 
 		sum := rel
-		if tsInfo := c.sums.GetFile(rel); tsInfo != nil {
+		if tsInfo := c.index[rel]; tsInfo != nil {
 			sum = tsInfo.Sum()
 		}
 		fi := &HashedFileInfo{PathFileInfo{FileInfo: info, FilePath: fullpath}, sum}
@@ -68,12 +68,15 @@ func (daemon *Daemon) ContainerWsAttachWithLogs(prefixOrName string, c *Containe
 
 func (daemon *Daemon) attachWithLogs(container *Container, stdin io.ReadCloser, stdout, stderr io.Writer, logs, stream bool) error {
 	if logs {
-		logDriver, err := daemon.getLogger(container)
+		logDriver, created, err := daemon.getLogger(container)
 		if err != nil {
 			return err
 		}
 		cLog, ok := logDriver.(logger.LogReader)
 		if !ok {
+			if created {
+				logDriver.Close()
+			}
 			return logger.ErrReadLogsNotSupported
 		}
 		logs := cLog.ReadLogs(logger.ReadConfig{Tail: -1})
@@ -96,6 +99,9 @@ func (daemon *Daemon) attachWithLogs(container *Container, stdin io.ReadCloser,
 				break LogLoop
 			}
 		}
+		if created {
+			logDriver.Close()
+		}
 	}
 
 	daemon.LogContainerEvent(container, "attach")
@@ -0,0 +1,142 @@
+package daemon
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/dockerversion"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/runconfig"
+)
+
+// ImageGetCachedFrom returns an image built from `parentID` with the
+// container configuration `cfg`, looking for it in the history of the images
+// `sources` instead of in the children of the parent image. This allows to
+// use images which were pulled, and not built locally, as a build cache.
+//
+// A step of a source image matches if the history and the layers of the
+// parent image are the first ones of the source image, and if the next entry
+// of its history was created by the same command. The image of the step is
+// then created with the configuration `imageCfg`, unless it is the source
+// image itself. A cache miss returns a nil image and a nil error.
+func (daemon *Daemon) ImageGetCachedFrom(sources []image.ID, parentID image.ID, cfg, imageCfg *runconfig.Config) (*image.Image, error) {
+	var parent *image.Image
+	if parentID != "" {
+		var err error
+		parent, err = daemon.imageStore.Get(parentID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, id := range sources {
+		source, err := daemon.imageStore.Get(id)
+		if err != nil {
+			logrus.Debugf("[BUILDER] Could not find cache source %s: %v", id, err)
+			continue
+		}
+		index := 0
+		if parent != nil {
+			index = len(parent.History)
+		}
+		if !isCacheParent(source, parent) || source.History[index].CreatedBy != strings.Join(cfg.Cmd.Slice(), " ") {
+			continue
+		}
+
+		if index == len(source.History)-1 && runconfig.Compare(source.Config, imageCfg) {
+			if parent != nil {
+				if err := daemon.imageStore.SetParent(source.ID(), parent.ID()); err != nil {
+					return nil, err
+				}
+			}
+			return source, nil
+		}
+		return daemon.restoreCachedImage(parent, source, index, cfg, imageCfg)
+	}
+	return nil, nil
+}
+
+// restoreCachedImage creates the image of the step `index` of the history of
+// the image `source`, as a child of the image `parent`.
+func (daemon *Daemon) restoreCachedImage(parent, source *image.Image, index int, cfg, imageCfg *runconfig.Config) (*image.Image, error) {
+	var history []image.History
+	rootFS := image.NewRootFS()
+	if parent != nil {
+		history = append(history, parent.History...)
+		*rootFS = *parent.RootFS
+		rootFS.DiffIDs = append([]layer.DiffID(nil), parent.RootFS.DiffIDs...)
+	}
+	h := source.History[index]
+	history = append(history, h)
+	if !h.EmptyLayer {
+		rootFS.Append(source.RootFS.DiffIDs[len(rootFS.DiffIDs)])
+	}
+
+	config, err := json.Marshal(&image.Image{
+		V1Image: image.V1Image{
+			DockerVersion:   dockerversion.Version,
+			Config:          imageCfg,
+			Architecture:    source.Architecture,
+			OS:              source.OS,
+			ContainerConfig: *cfg,
+			Author:          h.Author,
+			Created:         h.Created,
+		},
+		RootFS:  rootFS,
+		History: history,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := daemon.imageStore.Create(config)
+	if err != nil {
+		return nil, err
+	}
+	if parent != nil {
+		if err := daemon.imageStore.SetParent(id, parent.ID()); err != nil {
+			return nil, err
+		}
+	}
+	return daemon.imageStore.Get(id)
+}
+
+// isCacheParent returns whether the history and the layers of the image
+// `parent` are the first ones of the image `img`, which has at least one
+// more entry in its history. A nil parent is the parent of every image.
+func isCacheParent(img, parent *image.Image) bool {
+	if parent == nil {
+		return len(img.History) > 0 && countLayers(img.History[:1]) <= len(img.RootFS.DiffIDs)
+	}
+	if len(parent.History) >= len(img.History) || len(parent.RootFS.DiffIDs) > len(img.RootFS.DiffIDs) {
+		return false
+	}
+	for i, h := range parent.History {
+		if !reflect.DeepEqual(h, img.History[i]) {
+			return false
+		}
+	}
+	for i, diffID := range parent.RootFS.DiffIDs {
+		if img.RootFS.DiffIDs[i] != diffID {
+			return false
+		}
+	}
+	// the layers of the parent must be those of its history, so that the
+	// next entry of the history is for the next layer
+	return countLayers(img.History[:len(parent.History)+1]) <= len(img.RootFS.DiffIDs) &&
+		countLayers(parent.History) == len(parent.RootFS.DiffIDs)
+}
+
+// countLayers returns the number of layers created by the history entries.
+func countLayers(history []image.History) int {
+	n := 0
+	for _, h := range history {
+		if !h.EmptyLayer {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,65 @@
+// +build !windows
+
+package daemon
+
+import (
+	"testing"
+
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/pkg/stringutils"
+	"github.com/docker/docker/runconfig"
+)
+
+func TestImageGetCachedFrom(t *testing.T) {
+	daemon, cleanup := newImageStoreTestDaemon(t)
+	defer cleanup()
+
+	base := createTestImage(t, daemon, nil, writeTestFile("base", "base"))
+	source := createTestImage(t, daemon, base, writeTestFile("foo", "foo"), writeTestFile("bar", "bar"))
+	sources := []image.ID{source.ID()}
+
+	step := func(parent *image.Image, cmd ...string) *image.Image {
+		cfg := &runconfig.Config{Cmd: stringutils.NewStrSlice(cmd...)}
+		imageCfg := &runconfig.Config{Cmd: stringutils.NewStrSlice("app")}
+		img, err := daemon.ImageGetCachedFrom(sources, parent.ID(), cfg, imageCfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return img
+	}
+
+	if img := step(base, "change", "1"); img != nil {
+		t.Fatalf("Expected a cache miss for another command, got %s", img.ID())
+	}
+	if img := step(source, "change", "0"); img != nil {
+		t.Fatalf("Expected a cache miss at the end of the history, got %s", img.ID())
+	}
+
+	first := step(base, "change", "0")
+	if first == nil {
+		t.Fatal("Expected a cache hit for the first step")
+	}
+	if len(first.History) != 2 || len(first.RootFS.DiffIDs) != 2 || first.RootFS.DiffIDs[1] != source.RootFS.DiffIDs[1] {
+		t.Fatalf("Expected the first layer of the source on top of the base image, got %v", first.RootFS.DiffIDs)
+	}
+	if first.Config == nil || first.Config.Cmd.Slice()[0] != "app" {
+		t.Fatalf("Expected the image config of the step, got %v", first.Config)
+	}
+	if parent, err := daemon.imageStore.GetParent(first.ID()); err != nil || parent != base.ID() {
+		t.Fatalf("Expected the parent of the restored image to be %s, got %s (%v)", base.ID(), parent, err)
+	}
+
+	// the restored image is now part of the local cache
+	cached, err := daemon.ImageGetCached(base.ID(), &runconfig.Config{Cmd: stringutils.NewStrSlice("change", "0")})
+	if err != nil || cached == nil || cached.ID() != first.ID() {
+		t.Fatalf("Expected the restored image to be found in the local cache, got %v (%v)", cached, err)
+	}
+
+	second := step(first, "change", "1")
+	if second == nil {
+		t.Fatal("Expected a cache hit for the second step")
+	}
+	if second.RootFS.ChainID() != source.RootFS.ChainID() {
+		t.Fatalf("Expected the layers of the source image, got %v", second.RootFS.DiffIDs)
+	}
+}
@@ -1,6 +1,13 @@
 package daemon
 
-import "github.com/docker/docker/pkg/archive"
+import (
+	"archive/tar"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/pkg/archive"
+)
 
 // ContainerChanges returns a list of container fs changes
 func (daemon *Daemon) ContainerChanges(name string) ([]archive.Change, error) {
@@ -11,5 +18,149 @@ func (daemon *Daemon) ContainerChanges(name string) ([]archive.Change, error) {
 
 	container.Lock()
 	defer container.Unlock()
-	return daemon.changes(container)
+	changes, err := daemon.changes(container)
+	if err != nil {
+		return nil, err
+	}
+	return filterTmpfsChanges(changes, container.memoryMounts()), nil
+}
+
+// secretsMountPath is the path where the build secrets are mounted in the
+// container.
+const secretsMountPath = "/run/secrets"
+
+// memoryMounts returns the destinations of the mounts of the container
+// whose contents are only kept in memory: its tmpfs mounts, and the mount of
+// its build secrets.
+func (container *Container) memoryMounts() map[string]string {
+	if !container.hasSecrets {
+		return container.hostConfig.Tmpfs
+	}
+	mounts := make(map[string]string, len(container.hostConfig.Tmpfs)+1)
+	for dest, data := range container.hostConfig.Tmpfs {
+		mounts[dest] = data
+	}
+	mounts[secretsMountPath] = ""
+	return mounts
+}
+
+// filterTmpfsChanges removes the changes made below tmpfs mounts. The
+// contents of a tmpfs never reach the rw layer, but its mount point may
+// have been created there when the container was started.
+func filterTmpfsChanges(changes []archive.Change, tmpfs map[string]string) []archive.Change {
+	if len(tmpfs) == 0 {
+		return changes
+	}
+
+	filtered := make([]archive.Change, 0, len(changes))
+	for _, change := range changes {
+		if !isTmpfsPath(change.Path, tmpfs) {
+			filtered = append(filtered, change)
+		}
+	}
+	return filtered
+}
+
+func isTmpfsPath(path string, tmpfs map[string]string) bool {
+	path = filepath.Clean(path)
+	for dest := range tmpfs {
+		dest = filepath.Clean(dest)
+		if path == dest || strings.HasPrefix(path, dest+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// tmpfsMountpoints returns the mount points of the tmpfs mounts and of the
+// build secrets of the container that were created in its rw layer, rather
+// than inherited from its image. The parent directories which were only
+// created for a mount point are returned instead of the mount point.
+func (daemon *Daemon) tmpfsMountpoints(container *Container) ([]string, error) {
+	mounts := container.memoryMounts()
+	if len(mounts) == 0 {
+		return nil, nil
+	}
+
+	changes, err := daemon.changes(container)
+	if err != nil {
+		return nil, err
+	}
+
+	added := make(map[string]bool)
+	for _, change := range changes {
+		if change.Kind == archive.ChangeAdd {
+			added[filepath.Clean(change.Path)] = true
+		}
+	}
+
+	var mountpoints []string
+	for dest := range mounts {
+		dest = filepath.Clean(dest)
+		if !added[dest] {
+			continue
+		}
+		for {
+			parent := filepath.Dir(dest)
+			if !added[parent] || !onlyChangesBelow(changes, parent, dest) {
+				break
+			}
+			dest = parent
+		}
+		mountpoints = append(mountpoints, dest)
+	}
+	return mountpoints, nil
+}
+
+// onlyChangesBelow returns whether all the changes below the directory `dir`
+// are at or below `path`.
+func onlyChangesBelow(changes []archive.Change, dir, path string) bool {
+	for _, change := range changes {
+		p := filepath.Clean(change.Path)
+		if p == dir || !strings.HasPrefix(p, dir+string(filepath.Separator)) {
+			continue
+		}
+		if p != path && !strings.HasPrefix(p, path+string(filepath.Separator)) {
+			return false
+		}
+	}
+	return true
+}
+
+// excludeTarPaths returns a tar stream with the entries of the given one,
+// except the ones at or below any of the excluded paths.
+func excludeTarPaths(in io.Reader, excluded []string) io.ReadCloser {
+	paths := make(map[string]string, len(excluded))
+	for _, p := range excluded {
+		paths[p] = ""
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		tr := tar.NewReader(in)
+		tw := tar.NewWriter(pw)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			if isTmpfsPath("/"+hdr.Name, paths) {
+				continue
+			}
+			if err := tw.WriteHeader(hdr); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			if _, err := io.Copy(tw, tr); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		pw.CloseWithError(tw.Close())
+	}()
+	return pr
 }
@@ -0,0 +1,24 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/docker/docker/pkg/archive"
+)
+
+func TestOnlyChangesBelow(t *testing.T) {
+	changes := []archive.Change{
+		{Path: "/run", Kind: archive.ChangeAdd},
+		{Path: "/run/secrets", Kind: archive.ChangeAdd},
+		{Path: "/tmp", Kind: archive.ChangeModify},
+		{Path: "/tmp/foo", Kind: archive.ChangeAdd},
+	}
+	if !onlyChangesBelow(changes, "/run", "/run/secrets") {
+		t.Fatal("expected /run to only contain /run/secrets")
+	}
+
+	changes = append(changes, archive.Change{Path: "/run/lock", Kind: archive.ChangeAdd})
+	if onlyChangesBelow(changes, "/run", "/run/secrets") {
+		t.Fatal("expected /run to contain more than /run/secrets")
+	}
+}
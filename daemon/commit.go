@@ -150,10 +150,25 @@ func (daemon *Daemon) exportContainerRw(container *Container) (archive.Archive,
 		return nil, err
 	}
 
+	// The mount points of the tmpfs mounts are left out of the image.
+	excluded, err := daemon.tmpfsMountpoints(container)
+	if err != nil {
+		daemon.layerStore.Unmount(container.ID)
+		return nil, err
+	}
+
 	archive, err := container.rwlayer.TarStream()
 	if err != nil {
 		return nil, err
 	}
+	if len(excluded) > 0 {
+		filtered := excludeTarPaths(archive, excluded)
+		return ioutils.NewReadCloserWrapper(filtered, func() error {
+				filtered.Close()
+				return daemon.layerStore.Unmount(container.ID)
+			}),
+			nil
+	}
 	return ioutils.NewReadCloserWrapper(archive, func() error {
 			return daemon.layerStore.Unmount(container.ID)
 		}),
@@ -1,6 +1,15 @@
 package daemon
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
 	"github.com/docker/docker/opts"
 	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/runconfig"
@@ -14,24 +23,26 @@ const (
 // CommonConfig defines the configuration of a docker daemon which are
 // common across platforms.
 type CommonConfig struct {
-	AutoRestart   bool
-	Bridge        bridgeConfig // Bridge holds bridge network specific configuration.
-	Context       map[string][]string
-	DisableBridge bool
-	DNS           []string
-	DNSOptions    []string
-	DNSSearch     []string
-	ExecOptions   []string
-	ExecRoot      string
-	GraphDriver   string
-	GraphOptions  []string
-	Labels        []string
-	LogConfig     runconfig.LogConfig
-	Mtu           int
-	Pidfile       string
-	RemappedRoot  string
-	Root          string
-	TrustKeyPath  string
+	AuthorizationPlugins []string // AuthorizationPlugins holds list of authorization plugins
+	AutoRestart          bool
+	Bridge               bridgeConfig // Bridge holds bridge network specific configuration.
+	Context              map[string][]string
+	DisableBridge        bool
+	DNS                  []string
+	DNSOptions           []string
+	DNSSearch            []string
+	ExecOptions          []string
+	ExecRoot             string
+	GraphDriver          string
+	GraphOptions         []string
+	Labels               []string
+	LiveRestore          bool // LiveRestore keeps containers running while the daemon is down
+	LogConfig            runconfig.LogConfig
+	Mtu                  int
+	Pidfile              string
+	RemappedRoot         string
+	Root                 string
+	TrustKeyPath         string
 
 	// ClusterStore is the storage backend used for the cluster information. It is used by both
 	// multihost networking (to store networks and endpoints information) and by the node discovery
@@ -46,6 +57,18 @@ type CommonConfig struct {
 	// discovery. This should be a 'host:port' combination on which that daemon instance is
 	// reachable by other hosts.
 	ClusterAdvertise string
+
+	// Debug and LogLevel are set by the common flags. They are kept in the daemon
+	// configuration so that they can be changed when the configuration is reloaded.
+	Debug    bool
+	LogLevel string
+
+	// reloadLock protects the configuration values which can be changed
+	// while the daemon is running.
+	reloadLock sync.Mutex
+	// valuesSet holds the options which were set in the configuration file
+	// when the configuration was reloaded.
+	valuesSet map[string]interface{}
 }
 
 // InstallCommonFlags adds command-line options to the top-level flag parser for
@@ -54,6 +77,7 @@ type CommonConfig struct {
 // from the command-line.
 func (config *Config) InstallCommonFlags(cmd *flag.FlagSet, usageFn func(string) string) {
 	cmd.Var(opts.NewListOptsRef(&config.GraphOptions, nil), []string{"-storage-opt"}, usageFn("Set storage driver options"))
+	cmd.Var(opts.NewListOptsRef(&config.AuthorizationPlugins, nil), []string{"-authorization-plugin"}, usageFn("List authorization plugins in order from first evaluator"))
 	cmd.Var(opts.NewListOptsRef(&config.ExecOptions, nil), []string{"-exec-opt"}, usageFn("Set exec driver options"))
 	cmd.StringVar(&config.Pidfile, []string{"p", "-pidfile"}, defaultPidFile, usageFn("Path to use for daemon PID file"))
 	cmd.StringVar(&config.Root, []string{"g", "-graph"}, defaultGraph, usageFn("Root of the Docker runtime"))
@@ -72,3 +96,165 @@ func (config *Config) InstallCommonFlags(cmd *flag.FlagSet, usageFn func(string)
 	cmd.StringVar(&config.ClusterStore, []string{"-cluster-store"}, "", usageFn("Set the cluster store"))
 	cmd.Var(opts.NewMapOpts(config.ClusterOpts, nil), []string{"-cluster-store-opt"}, usageFn("Set cluster store options"))
 }
+
+// IsValueSet returns true if the option was set in the configuration file
+// from which the configuration was reloaded.
+func (config *Config) IsValueSet(name string) bool {
+	if config.valuesSet == nil {
+		return false
+	}
+	_, ok := config.valuesSet[name]
+	return ok
+}
+
+// MergeDaemonConfigurations applies the options of the configuration file on
+// top of the values already parsed from the command line. The options of the
+// file are named after the long names of the flags in the flag set. It returns
+// an error if the file sets an unknown option, or an option which is already
+// set with a flag.
+func MergeDaemonConfigurations(flags *flag.FlagSet, configFile string) error {
+	_, err := applyConfigurationFile(configFile, flags, flags)
+	return err
+}
+
+// ReloadConfiguration reads the configuration file again and calls reload
+// with a new configuration holding the options it sets. The options of the
+// file are checked against the command line flags, like when the daemon
+// starts. Only the options of the daemon configuration are set, the others
+// are ignored.
+func ReloadConfiguration(configFile string, flags *flag.FlagSet, reload func(*Config)) error {
+	newConfig := new(Config)
+	newConfig.LogConfig.Config = make(map[string]string)
+	newConfig.ClusterOpts = make(map[string]string)
+
+	reloadFlags := flag.NewFlagSet("reload", flag.ContinueOnError)
+	newConfig.InstallFlags(reloadFlags, func(string) string { return "" })
+	reloadFlags.BoolVar(&newConfig.Debug, []string{"D", "-debug"}, false, "")
+	reloadFlags.StringVar(&newConfig.LogLevel, []string{"l", "-log-level"}, "", "")
+
+	valuesSet, err := applyConfigurationFile(configFile, flags, reloadFlags)
+	if err != nil {
+		return err
+	}
+	newConfig.valuesSet = valuesSet
+
+	reload(newConfig)
+	return nil
+}
+
+// applyConfigurationFile reads the options of the configuration file and sets
+// them in the target flag set. The options are validated against the flags of
+// the command line, which must neither miss nor set any of them. It returns
+// the options set in the file.
+func applyConfigurationFile(configFile string, flags, target *flag.FlagSet) (map[string]interface{}, error) {
+	b, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %v", configFile, err)
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var conflicts []string
+	for _, name := range names {
+		f := flags.Lookup("-" + name)
+		if f == nil {
+			return nil, fmt.Errorf("unknown option %q in configuration file %s", name, configFile)
+		}
+		for _, flagName := range f.Names {
+			if flags.IsSet(strings.TrimPrefix(flagName, "#")) {
+				conflicts = append(conflicts, fmt.Sprintf("%s: (from flag: %v, from file: %v)", name, f.Value, values[name]))
+				break
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("the following options are set both with a flag and in the configuration file %s: %s", configFile, strings.Join(conflicts, ", "))
+	}
+
+	// The values are set on the flags directly rather than with target.Set,
+	// which would mark the flags as set on the command line, so that the
+	// options of the file are not reported as conflicts when it is reloaded.
+	for _, name := range names {
+		f := target.Lookup("-" + name)
+		if f == nil {
+			continue
+		}
+		args, err := flagArgs(values[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for option %q in configuration file %s: %v", name, configFile, err)
+		}
+		for _, arg := range args {
+			if err := f.Value.Set(arg); err != nil {
+				return nil, fmt.Errorf("invalid value for option %q in configuration file %s: %v", name, configFile, err)
+			}
+		}
+	}
+
+	return values, nil
+}
+
+// flagArgs converts a value of the configuration file to the arguments of the
+// matching flag. Lists give one argument per element, and objects one
+// key=value argument per key.
+func flagArgs(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case []interface{}:
+		args := make([]string, 0, len(v))
+		for _, elem := range v {
+			arg, err := scalarArg(elem)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+		}
+		return args, nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		args := make([]string, 0, len(v))
+		for _, k := range keys {
+			arg, err := scalarArg(v[k])
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, k+"="+arg)
+		}
+		return args, nil
+	default:
+		arg, err := scalarArg(value)
+		if err != nil {
+			return nil, err
+		}
+		return []string{arg}, nil
+	}
+}
+
+// scalarArg converts a string, boolean or number of the configuration file
+// to a flag argument.
+func scalarArg(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case json.Number:
+		return v.String(), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v", value)
+	}
+}
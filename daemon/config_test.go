@@ -0,0 +1,154 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/opts"
+	flag "github.com/docker/docker/pkg/mflag"
+)
+
+func newTestConfig(args ...string) (*Config, *flag.FlagSet) {
+	config := new(Config)
+	config.LogConfig.Config = make(map[string]string)
+	config.ClusterOpts = make(map[string]string)
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	config.InstallFlags(flags, func(string) string { return "" })
+	flags.BoolVar(&config.Debug, []string{"D", "-debug"}, false, "")
+	flags.ParseFlags(args, true)
+	return config, flags
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	f, err := ioutil.TempFile("", "docker-config-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
+func TestMergeDaemonConfigurations(t *testing.T) {
+	configFile := writeConfigFile(t, `{"label": ["foo=bar", "baz=qux"], "log-opt": {"max-size": "1k"}, "mtu": 1400, "icc": false, "debug": true}`)
+	defer os.Remove(configFile)
+
+	config, flags := newTestConfig("--dns", "8.8.8.8")
+	if err := MergeDaemonConfigurations(flags, configFile); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(config.Labels) != 2 || config.Labels[0] != "foo=bar" || config.Labels[1] != "baz=qux" {
+		t.Fatalf("Expected labels from the configuration file, got %v", config.Labels)
+	}
+	if config.LogConfig.Config["max-size"] != "1k" {
+		t.Fatalf("Expected log option max-size=1k, got %v", config.LogConfig.Config)
+	}
+	if config.Mtu != 1400 {
+		t.Fatalf("Expected mtu 1400, got %d", config.Mtu)
+	}
+	if config.Bridge.InterContainerCommunication {
+		t.Fatal("Expected inter-container communication to be disabled")
+	}
+	if !config.Debug {
+		t.Fatal("Expected debug mode to be enabled")
+	}
+	if len(config.DNS) != 1 || config.DNS[0] != "8.8.8.8" {
+		t.Fatalf("Expected dns from the flags, got %v", config.DNS)
+	}
+}
+
+func TestMergeDaemonConfigurationsConflicts(t *testing.T) {
+	configFile := writeConfigFile(t, `{"label": ["foo=bar"], "mtu": 1400}`)
+	defer os.Remove(configFile)
+
+	_, flags := newTestConfig("--label", "baz=qux")
+	err := MergeDaemonConfigurations(flags, configFile)
+	if err == nil {
+		t.Fatal("Expected a conflict between the flags and the configuration file")
+	}
+	if !strings.Contains(err.Error(), "label: (from flag: [baz=qux], from file: [foo=bar])") {
+		t.Fatalf("Expected the conflicting label in the error, got %v", err)
+	}
+	if strings.Contains(err.Error(), "mtu") {
+		t.Fatalf("Expected no conflict for mtu, got %v", err)
+	}
+}
+
+func TestReloadMergedConfiguration(t *testing.T) {
+	configFile := writeConfigFile(t, `{"label": ["foo=bar"], "mtu": 1400}`)
+	defer os.Remove(configFile)
+
+	_, flags := newTestConfig("--dns", "8.8.8.8")
+	if err := MergeDaemonConfigurations(flags, configFile); err != nil {
+		t.Fatal(err)
+	}
+	if flags.IsSet("-label") || flags.IsSet("-mtu") {
+		t.Fatal("Expected the options of the configuration file not to be set on the command line")
+	}
+
+	var reloaded *Config
+	if err := ReloadConfiguration(configFile, flags, func(config *Config) { reloaded = config }); err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.Labels) != 1 || reloaded.Labels[0] != "foo=bar" {
+		t.Fatalf("Expected the labels to be reloaded, got %v", reloaded.Labels)
+	}
+
+	// the flags of the command line still conflict with the file
+	configFile2 := writeConfigFile(t, `{"dns": ["8.8.4.4"]}`)
+	defer os.Remove(configFile2)
+	if err := ReloadConfiguration(configFile2, flags, func(*Config) {}); err == nil {
+		t.Fatal("Expected a conflict between the flags and the reloaded configuration file")
+	}
+}
+
+func TestMergeDaemonConfigurationsInvalid(t *testing.T) {
+	invalid := []string{
+		`{"unknown-option": true}`,
+		`{"dns": ["not-an-ip"]}`,
+		`{"label": "foo"}`,
+		`{"label": ["foo=bar"]`,
+		`{"log-opt": {"max-size": ["1k"]}}`,
+	}
+	for _, content := range invalid {
+		configFile := writeConfigFile(t, content)
+		_, flags := newTestConfig()
+		if err := MergeDaemonConfigurations(flags, configFile); err == nil {
+			t.Fatalf("Expected an error for configuration %s", content)
+		}
+		os.Remove(configFile)
+	}
+}
+
+func TestReloadConfiguration(t *testing.T) {
+	configFile := writeConfigFile(t, `{"label": ["foo=bar"], "log-level": "warn", "insecure-registry": ["example.com"]}`)
+	defer os.Remove(configFile)
+
+	_, flags := newTestConfig()
+	flags.StringVar(new(string), []string{"l", "-log-level"}, "info", "")
+	insecureRegistries := opts.NewListOpts(nil)
+	flags.Var(&insecureRegistries, []string{"-insecure-registry"}, "")
+
+	var reloaded *Config
+	if err := ReloadConfiguration(configFile, flags, func(config *Config) { reloaded = config }); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded == nil {
+		t.Fatal("Expected the configuration to be reloaded")
+	}
+
+	if !reloaded.IsValueSet("label") || len(reloaded.Labels) != 1 || reloaded.Labels[0] != "foo=bar" {
+		t.Fatalf("Expected the labels to be reloaded, got %v", reloaded.Labels)
+	}
+	if !reloaded.IsValueSet("log-level") || reloaded.LogLevel != "warn" {
+		t.Fatalf("Expected the log level to be reloaded, got %q", reloaded.LogLevel)
+	}
+	if reloaded.IsValueSet("debug") {
+		t.Fatal("Expected debug not to be set in the reloaded configuration")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sync"
 	"syscall"
 	"time"
@@ -18,6 +19,8 @@ import (
 	"github.com/docker/docker/daemon/execdriver"
 	"github.com/docker/docker/daemon/logger"
 	"github.com/docker/docker/daemon/logger/jsonfilelog"
+	"github.com/docker/docker/daemon/logger/local"
+	"github.com/docker/docker/daemon/logger/loggerutils/cache"
 	"github.com/docker/docker/daemon/network"
 	derr "github.com/docker/docker/errors"
 	"github.com/docker/docker/image"
@@ -26,6 +29,7 @@ import (
 	"github.com/docker/docker/pkg/promise"
 	"github.com/docker/docker/pkg/signal"
 	"github.com/docker/docker/pkg/symlink"
+	"github.com/docker/docker/pkg/units"
 
 	"github.com/docker/docker/runconfig"
 	"github.com/docker/docker/volume"
@@ -56,8 +60,11 @@ type CommonContainer struct {
 	ImageID         image.ID `json:"Image"`
 	NetworkSettings *network.Settings
 	LogPath         string
-	Name            string
-	Driver          string
+	// LogDroppedMessages is the number of log messages dropped by the
+	// previous runs of the container, in the non-blocking logging mode.
+	LogDroppedMessages int64
+	Name               string
+	Driver             string
 	// MountLabel contains the options for the 'mount' command
 	MountLabel             string
 	ProcessLabel           string
@@ -69,6 +76,12 @@ type CommonContainer struct {
 	command                *execdriver.Command
 	monitor                *containerMonitor
 	execCommands           *exec.Store
+	// secrets are only kept in memory, so they are not saved with the
+	// container, and they are dropped once it exits.
+	secrets map[string][]byte
+	// hasSecrets is set if the container was created with secrets, whose
+	// mount point is not committed.
+	hasSecrets bool
 	// logDriver for closing
 	logDriver logger.Logger
 	logCopier *logger.Copier
@@ -320,7 +333,95 @@ func (container *Container) StartLogger(cfg runconfig.LogConfig) (logger.Logger,
 			return nil, err
 		}
 	}
-	return c(ctx)
+	// Set logging file for "local"
+	if cfg.Type == local.Name {
+		ctx.LogPath, err = container.getRootResourcePath(fmt.Sprintf("%s-local.log", container.ID))
+		if err != nil {
+			return nil, err
+		}
+	}
+	l, err := c(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The logs of the drivers which can't read them back are read from a
+	// local cache.
+	if _, ok := l.(logger.LogReader); !ok && cache.Enabled(cfg.Config) {
+		ctx.LogPath, err = container.logCachePath()
+		var cached logger.Logger
+		if err == nil {
+			cached, err = cache.WithLocalCache(l, ctx)
+		}
+		if err != nil {
+			l.Close()
+			return nil, err
+		}
+		l = cached
+	}
+
+	if s, ok := cfg.Config[logger.MultilinePatternOpt]; ok {
+		pattern, err := regexp.Compile(s)
+		if err != nil {
+			l.Close()
+			return nil, err
+		}
+		l = logger.NewMultilineLogger(l, pattern)
+	}
+
+	if cfg.Config[logger.ModeOpt] == logger.ModeNonBlocking {
+		maxSize := int64(-1)
+		if s, ok := cfg.Config[logger.MaxBufferSizeOpt]; ok {
+			maxSize, err = units.RAMInBytes(s)
+			if err != nil {
+				l.Close()
+				return nil, err
+			}
+		}
+		l = logger.NewRingLogger(l, maxSize)
+	}
+	return l, nil
+}
+
+// logCachePath returns the path of the local cache of the logs of the
+// container.
+func (container *Container) logCachePath() (string, error) {
+	return container.getRootResourcePath(fmt.Sprintf("%s-cache.log", container.ID))
+}
+
+// openLogCache opens the local cache of the logs of the container, to read
+// them when the container is not running, without creating the logging
+// driver, which can be a remote service. It returns nil if the logs are not
+// cached, which is the case for the logging drivers which can read them back.
+func (container *Container) openLogCache(cfg runconfig.LogConfig) (logger.Logger, error) {
+	if !cache.Enabled(cfg.Config) {
+		return nil, nil
+	}
+	path, err := container.logCachePath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return cache.OpenLocalCache(logger.Context{
+		Config:      cfg.Config,
+		ContainerID: container.ID,
+		LogPath:     path,
+	})
+}
+
+// logDroppedMessages returns the number of log messages dropped in the
+// non-blocking logging mode since the container was created.
+func (container *Container) logDroppedMessages() int64 {
+	dropped := container.LogDroppedMessages
+	if container.logDriver != nil {
+		dropped += logger.DroppedMessages(container.logDriver)
+	}
+	return dropped
 }
 
 func (container *Container) getProcessLabel() string {
@@ -52,6 +52,7 @@ type Container struct {
 	// Fields below here are platform specific.
 	activeLinks     map[string]*links.Link
 	AppArmorProfile string
+	SeccompProfile  string
 	HostnamePath    string
 	HostsPath       string
 	ShmPath         string
@@ -297,6 +298,7 @@ func (daemon *Daemon) populateCommand(c *Container, env []string) error {
 		BlkioWeightDevice: weightDevices,
 		OomKillDisable:    c.hostConfig.OomKillDisable,
 		MemorySwappiness:  -1,
+		PidsLimit:         c.hostConfig.PidsLimit,
 	}
 
 	if c.hostConfig.MemorySwappiness != nil {
@@ -345,9 +347,11 @@ func (daemon *Daemon) populateCommand(c *Container, env []string) error {
 		GIDMapping:         gidMap,
 		GroupAdd:           c.hostConfig.GroupAdd,
 		Ipc:                ipc,
+		LiveRestore:        daemon.configStore.LiveRestore,
 		Pid:                pid,
 		ReadonlyRootfs:     c.hostConfig.ReadonlyRootfs,
 		RemappedRoot:       remappedRoot,
+		SeccompProfile:     c.SeccompProfile,
 		UIDMapping:         uidMap,
 		UTS:                uts,
 	}
@@ -355,6 +359,24 @@ func (daemon *Daemon) populateCommand(c *Container, env []string) error {
 	return nil
 }
 
+// updateCommandResources refreshes the mutable resources of the execdriver
+// command from the container hostconfig.
+func updateCommandResources(c *execdriver.Command, hostConfig *runconfig.HostConfig) {
+	if c == nil || c.Resources == nil {
+		return
+	}
+	c.Resources.BlkioWeight = hostConfig.BlkioWeight
+	c.Resources.CPUShares = hostConfig.CPUShares
+	c.Resources.CPUPeriod = hostConfig.CPUPeriod
+	c.Resources.CPUQuota = hostConfig.CPUQuota
+	c.Resources.CpusetCpus = hostConfig.CpusetCpus
+	c.Resources.CpusetMems = hostConfig.CpusetMems
+	c.Resources.Memory = hostConfig.Memory
+	c.Resources.MemorySwap = hostConfig.MemorySwap
+	c.Resources.MemoryReservation = hostConfig.MemoryReservation
+	c.Resources.KernelMemory = hostConfig.KernelMemory
+}
+
 func mergeDevices(defaultDevices, userDevices []*configs.Device) []*configs.Device {
 	if len(userDevices) == 0 {
 		return defaultDevices
@@ -440,6 +462,83 @@ func (container *Container) buildHostnameFile() error {
 }
 
 func (daemon *Daemon) buildSandboxOptions(container *Container, n libnetwork.Network) ([]libnetwork.SandboxOption, error) {
+	sboxOptions, err := daemon.buildSandboxConfigOptions(container)
+	if err != nil {
+		return nil, err
+	}
+
+	// Link feature is supported only for the default bridge network.
+	// return if this call to build join options is not for default bridge network
+	if n.Name() != "bridge" {
+		return sboxOptions, nil
+	}
+
+	ep, _ := container.getEndpointInNetwork(n)
+	if ep == nil {
+		return sboxOptions, nil
+	}
+
+	var childEndpoints, parentEndpoints []string
+
+	children, err := daemon.children(container.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	for linkAlias, child := range children {
+		if !isLinkable(child) {
+			return nil, fmt.Errorf("Cannot link to %s, as it does not belong to the default network", child.Name)
+		}
+		_, alias := path.Split(linkAlias)
+		// allow access to the linked container via the alias, real name, and container hostname
+		aliasList := alias + " " + child.Config.Hostname
+		// only add the name if alias isn't equal to the name
+		if alias != child.Name[1:] {
+			aliasList = aliasList + " " + child.Name[1:]
+		}
+		sboxOptions = append(sboxOptions, libnetwork.OptionExtraHost(aliasList, child.NetworkSettings.Networks["bridge"].IPAddress))
+		cEndpoint, _ := child.getEndpointInNetwork(n)
+		if cEndpoint != nil && cEndpoint.ID() != "" {
+			childEndpoints = append(childEndpoints, cEndpoint.ID())
+		}
+	}
+
+	bridgeSettings := container.NetworkSettings.Networks["bridge"]
+	refs := daemon.containerGraph().RefPaths(container.ID)
+	for _, ref := range refs {
+		if ref.ParentID == "0" {
+			continue
+		}
+
+		c, err := daemon.Get(ref.ParentID)
+		if err != nil {
+			logrus.Error(err)
+		}
+
+		if c != nil && !daemon.configStore.DisableBridge && container.hostConfig.NetworkMode.IsPrivate() {
+			logrus.Debugf("Update /etc/hosts of %s for alias %s with ip %s", c.ID, ref.Name, bridgeSettings.IPAddress)
+			sboxOptions = append(sboxOptions, libnetwork.OptionParentUpdate(c.ID, ref.Name, bridgeSettings.IPAddress))
+			if ep.ID() != "" {
+				parentEndpoints = append(parentEndpoints, ep.ID())
+			}
+		}
+	}
+
+	linkOptions := options.Generic{
+		netlabel.GenericData: options.Generic{
+			"ParentEndpoints": parentEndpoints,
+			"ChildEndpoints":  childEndpoints,
+		},
+	}
+
+	sboxOptions = append(sboxOptions, libnetwork.OptionGeneric(linkOptions))
+
+	return sboxOptions, nil
+}
+
+// buildSandboxConfigOptions returns the options of the network sandbox of a
+// container which don't depend on the networks it is connected to.
+func (daemon *Daemon) buildSandboxConfigOptions(container *Container) ([]libnetwork.SandboxOption, error) {
 	var (
 		sboxOptions []libnetwork.SandboxOption
 		err         error
@@ -520,72 +619,6 @@ func (daemon *Daemon) buildSandboxOptions(container *Container, n libnetwork.Net
 		sboxOptions = append(sboxOptions, libnetwork.OptionExtraHost(parts[0], parts[1]))
 	}
 
-	// Link feature is supported only for the default bridge network.
-	// return if this call to build join options is not for default bridge network
-	if n.Name() != "bridge" {
-		return sboxOptions, nil
-	}
-
-	ep, _ := container.getEndpointInNetwork(n)
-	if ep == nil {
-		return sboxOptions, nil
-	}
-
-	var childEndpoints, parentEndpoints []string
-
-	children, err := daemon.children(container.Name)
-	if err != nil {
-		return nil, err
-	}
-
-	for linkAlias, child := range children {
-		if !isLinkable(child) {
-			return nil, fmt.Errorf("Cannot link to %s, as it does not belong to the default network", child.Name)
-		}
-		_, alias := path.Split(linkAlias)
-		// allow access to the linked container via the alias, real name, and container hostname
-		aliasList := alias + " " + child.Config.Hostname
-		// only add the name if alias isn't equal to the name
-		if alias != child.Name[1:] {
-			aliasList = aliasList + " " + child.Name[1:]
-		}
-		sboxOptions = append(sboxOptions, libnetwork.OptionExtraHost(aliasList, child.NetworkSettings.Networks["bridge"].IPAddress))
-		cEndpoint, _ := child.getEndpointInNetwork(n)
-		if cEndpoint != nil && cEndpoint.ID() != "" {
-			childEndpoints = append(childEndpoints, cEndpoint.ID())
-		}
-	}
-
-	bridgeSettings := container.NetworkSettings.Networks["bridge"]
-	refs := daemon.containerGraph().RefPaths(container.ID)
-	for _, ref := range refs {
-		if ref.ParentID == "0" {
-			continue
-		}
-
-		c, err := daemon.Get(ref.ParentID)
-		if err != nil {
-			logrus.Error(err)
-		}
-
-		if c != nil && !daemon.configStore.DisableBridge && container.hostConfig.NetworkMode.IsPrivate() {
-			logrus.Debugf("Update /etc/hosts of %s for alias %s with ip %s", c.ID, ref.Name, bridgeSettings.IPAddress)
-			sboxOptions = append(sboxOptions, libnetwork.OptionParentUpdate(c.ID, ref.Name, bridgeSettings.IPAddress))
-			if ep.ID() != "" {
-				parentEndpoints = append(parentEndpoints, ep.ID())
-			}
-		}
-	}
-
-	linkOptions := options.Generic{
-		netlabel.GenericData: options.Generic{
-			"ParentEndpoints": parentEndpoints,
-			"ChildEndpoints":  childEndpoints,
-		},
-	}
-
-	sboxOptions = append(sboxOptions, libnetwork.OptionGeneric(linkOptions))
-
 	return sboxOptions, nil
 }
 
@@ -1046,6 +1079,7 @@ func (daemon *Daemon) connectToNetwork(container *Container, idOrName string, up
 		return derr.ErrorCodeJoinInfo.WithArgs(err)
 	}
 
+	daemon.LogNetworkEventWithAttributes(n, "connect", map[string]string{"container": container.ID})
 	return nil
 }
 
@@ -1087,6 +1121,19 @@ func (daemon *Daemon) initializeNetworking(container *Container) error {
 	return container.buildHostnameFile()
 }
 
+// restoreNetworking checks that the network sandbox of a container which kept
+// running while the daemon was down was restored, along with its endpoints.
+func (daemon *Daemon) restoreNetworking(container *Container) error {
+	if container.hostConfig.NetworkMode.IsContainer() || container.Config.NetworkDisabled {
+		return nil
+	}
+
+	if _, err := daemon.netController.SandboxByID(container.NetworkSettings.SandboxID); err != nil {
+		return fmt.Errorf("could not restore the network sandbox of container %s: %v", container.ID, err)
+	}
+	return nil
+}
+
 // called from the libcontainer pre-start hook to set the network
 // namespace configuration linkage to the libnetwork "sandbox" entity
 func (daemon *Daemon) setNetworkNamespaceKey(containerID string, pid int) error {
@@ -1161,8 +1208,12 @@ func (daemon *Daemon) releaseNetwork(container *Container) {
 
 	sid := container.NetworkSettings.SandboxID
 	networks := container.NetworkSettings.Networks
+	var connected []libnetwork.Network
 	for n := range networks {
 		networks[n] = &network.EndpointSettings{}
+		if nw, err := daemon.FindNetwork(n); err == nil {
+			connected = append(connected, nw)
+		}
 	}
 
 	container.NetworkSettings = &network.Settings{Networks: networks}
@@ -1180,6 +1231,10 @@ func (daemon *Daemon) releaseNetwork(container *Container) {
 	if err := sb.Delete(); err != nil {
 		logrus.Errorf("Error deleting sandbox id %s for container %s: %v", sid, container.ID, err)
 	}
+
+	for _, nw := range connected {
+		daemon.LogNetworkEventWithAttributes(nw, "disconnect", map[string]string{"container": container.ID})
+	}
 }
 
 // DisconnectFromNetwork disconnects a container from a network
@@ -1309,6 +1364,21 @@ func (container *Container) networkMounts() []execdriver.Mount {
 	return mounts
 }
 
+// tmpfsMounts returns the tmpfs mounts requested for the container. They
+// are mounted inside the container's mount namespace only, so their
+// contents never reach the container's rw layer.
+func (container *Container) tmpfsMounts() []execdriver.Mount {
+	var mounts []execdriver.Mount
+	for dest, data := range container.hostConfig.Tmpfs {
+		mounts = append(mounts, execdriver.Mount{
+			Source:      "tmpfs",
+			Destination: dest,
+			Data:        data,
+		})
+	}
+	return mounts
+}
+
 func (container *Container) copyImagePathContent(v volume.Volume, destination string) error {
 	rootfs, err := symlink.FollowSymlinkInScope(filepath.Join(container.basefs, destination), container.basefs)
 	if err != nil {
@@ -1341,8 +1411,19 @@ func (container *Container) mqueuePath() (string, error) {
 	return container.getRootResourcePath("mqueue")
 }
 
+// canLiveRestore returns whether the container can keep running while the
+// daemon is down. Containers with a tty and containers started before live
+// restore was enabled are stopped with the daemon.
+func (container *Container) canLiveRestore() bool {
+	return container.command != nil && container.command.LiveRestore && !container.Config.Tty
+}
+
 func (container *Container) hasMountFor(path string) bool {
 	_, exists := container.MountPoints[path]
+	if exists {
+		return true
+	}
+	_, exists = container.hostConfig.Tmpfs[path]
 	return exists
 }
 
@@ -3,11 +3,13 @@
 package daemon
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/docker/docker/daemon/execdriver"
 	derr "github.com/docker/docker/errors"
 	"github.com/docker/docker/layer"
+	"github.com/docker/docker/runconfig"
 	"github.com/docker/docker/volume"
 	"github.com/docker/libnetwork"
 )
@@ -41,6 +43,10 @@ func (daemon *Daemon) initializeNetworking(container *Container) error {
 	return nil
 }
 
+func (daemon *Daemon) restoreNetworking(container *Container) error {
+	return nil
+}
+
 // ConnectToNetwork connects a container to the network
 func (daemon *Daemon) ConnectToNetwork(container *Container, idOrName string) error {
 	return nil
@@ -146,6 +152,15 @@ func (daemon *Daemon) populateCommand(c *Container, env []string) error {
 	return nil
 }
 
+// updateCommandResources refreshes the mutable resources of the execdriver
+// command from the container hostconfig.
+func updateCommandResources(c *execdriver.Command, hostConfig *runconfig.HostConfig) {
+	if c == nil || c.Resources == nil {
+		return
+	}
+	c.Resources.CPUShares = hostConfig.CPUShares
+}
+
 // getSize returns real size & virtual size
 func (daemon *Daemon) getSize(container *Container) (int64, int64) {
 	// TODO Windows
@@ -183,6 +198,12 @@ func (daemon *Daemon) setupIpcDirs(container *Container) error {
 func (container *Container) unmountIpcMounts(unmount func(pth string) error) {
 }
 
+// canLiveRestore returns whether the container can keep running while the
+// daemon is down, which is not supported on Windows.
+func (container *Container) canLiveRestore() bool {
+	return false
+}
+
 func detachMounted(path string) error {
 	return nil
 }
@@ -191,6 +212,26 @@ func (container *Container) ipcMounts() []execdriver.Mount {
 	return nil
 }
 
+func (container *Container) tmpfsMounts() []execdriver.Mount {
+	return nil
+}
+
+// setupSecrets returns an error if the container has secrets, which are not
+// supported on Windows.
+func (daemon *Daemon) setupSecrets(container *Container) error {
+	if len(container.secrets) > 0 {
+		return fmt.Errorf("Build secrets are not supported on Windows")
+	}
+	return nil
+}
+
+func (container *Container) unmountSecrets(unmount func(pth string) error) {
+}
+
+func (container *Container) secretsMounts() []execdriver.Mount {
+	return nil
+}
+
 func getDefaultRouteMtu() (int, error) {
 	return -1, errSystemNotSupported
 }
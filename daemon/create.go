@@ -18,6 +18,9 @@ type ContainerCreateConfig struct {
 	Config          *runconfig.Config
 	HostConfig      *runconfig.HostConfig
 	AdjustCPUShares bool
+	// Secrets are mounted in the container the first time it runs, and
+	// are never written to disk or committed.
+	Secrets map[string][]byte
 }
 
 // ContainerCreate takes configs and creates a container.
@@ -74,6 +77,8 @@ func (daemon *Daemon) create(params *ContainerCreateConfig) (retC *Container, re
 	if container, err = daemon.newContainer(params.Name, params.Config, imgID); err != nil {
 		return nil, err
 	}
+	container.secrets = params.Secrets
+	container.hasSecrets = len(params.Secrets) > 0
 	defer func() {
 		if retErr != nil {
 			if err := daemon.rm(container, false); err != nil {
@@ -138,6 +143,9 @@ func (daemon *Daemon) VolumeCreate(name, driverName string, opts map[string]stri
 		name = stringid.GenerateNonCryptoID()
 	}
 
+	_, err := daemon.volumes.Get(name)
+	exists := err == nil
+
 	v, err := daemon.volumes.Create(name, driverName, opts)
 	if err != nil {
 		return nil, err
@@ -147,5 +155,9 @@ func (daemon *Daemon) VolumeCreate(name, driverName string, opts map[string]stri
 	if (driverName != "" && v.DriverName() != driverName) || (driverName == "" && v.DriverName() != volume.DefaultDriverName) {
 		return nil, derr.ErrorVolumeNameTaken.WithArgs(name, v.DriverName())
 	}
+
+	if !exists {
+		daemon.LogVolumeEvent(v.Name(), "create", map[string]string{"driver": v.DriverName()})
+	}
 	return volumeToAPIType(v), nil
 }
@@ -30,6 +30,10 @@ func (daemon *Daemon) createContainerPlatformSpecificSettings(container *Contain
 		if container.isDestinationMounted(destination) {
 			continue
 		}
+		// Skip volumes that are replaced by a tmpfs mount.
+		if _, exists := hostConfig.Tmpfs[destination]; exists {
+			continue
+		}
 		path, err := container.GetResourcePath(destination)
 		if err != nil {
 			return err
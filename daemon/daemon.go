@@ -22,6 +22,7 @@ import (
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api"
 	"github.com/docker/docker/api/types"
+	eventtypes "github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/cliconfig"
 	"github.com/docker/docker/daemon/events"
 	"github.com/docker/docker/daemon/exec"
@@ -43,7 +44,6 @@ import (
 	"github.com/docker/docker/pkg/fileutils"
 	"github.com/docker/docker/pkg/graphdb"
 	"github.com/docker/docker/pkg/idtools"
-	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/pkg/mount"
 	"github.com/docker/docker/pkg/namesgenerator"
 	"github.com/docker/docker/pkg/nat"
@@ -141,6 +141,7 @@ type Daemon struct {
 	netController             libnetwork.NetworkController
 	volumes                   *store.VolumeStore
 	discoveryWatcher          discovery.Watcher
+	discovery                 *daemonDiscovery
 	root                      string
 	shutdown                  bool
 	uidMaps                   []idtools.IDMap
@@ -236,24 +237,13 @@ func (daemon *Daemon) Register(container *Container) error {
 	// we'll waste time if we update it for every container
 	daemon.idIndex.Add(container.ID)
 
-	if container.IsRunning() {
-		logrus.Debugf("killing old running container %s", container.ID)
-		// Set exit code to 128 + SIGKILL (9) to properly represent unsuccessful exit
-		container.setStoppedLocking(&execdriver.ExitStatus{ExitCode: 137})
-		// use the current driver and ensure that the container is dead x.x
-		cmd := &execdriver.Command{
-			CommonCommand: execdriver.CommonCommand{
-				ID: container.ID,
-			},
-		}
-		daemon.execDriver.Terminate(cmd)
-
+	// with live restore, running containers are reattached once all the
+	// containers are registered
+	if container.IsRunning() && !daemon.configStore.LiveRestore {
+		daemon.terminateStaleContainer(container)
 		container.unmountIpcMounts(mount.Unmount)
-
+		container.unmountSecrets(mount.Unmount)
 		daemon.Unmount(container)
-		if err := container.toDiskLocking(); err != nil {
-			logrus.Errorf("Error saving stopped state to disk: %v", err)
-		}
 	}
 
 	if err := daemon.prepareMountPoints(container); err != nil {
@@ -263,6 +253,25 @@ func (daemon *Daemon) Register(container *Container) error {
 	return nil
 }
 
+// terminateStaleContainer kills a container left running by a previous
+// instance of the daemon, and marks it as stopped.
+func (daemon *Daemon) terminateStaleContainer(container *Container) {
+	logrus.Debugf("killing old running container %s", container.ID)
+	// Set exit code to 128 + SIGKILL (9) to properly represent unsuccessful exit
+	container.setStoppedLocking(&execdriver.ExitStatus{ExitCode: 137})
+	// use the current driver and ensure that the container is dead x.x
+	cmd := &execdriver.Command{
+		CommonCommand: execdriver.CommonCommand{
+			ID: container.ID,
+		},
+	}
+	daemon.execDriver.Terminate(cmd)
+
+	if err := container.toDiskLocking(); err != nil {
+		logrus.Errorf("Error saving stopped state to disk: %v", err)
+	}
+}
+
 func (daemon *Daemon) ensureName(container *Container) error {
 	if container.Name == "" {
 		name, err := daemon.generateNewName(container.ID)
@@ -333,7 +342,12 @@ func (daemon *Daemon) restore() error {
 		}
 	}
 
-	group := sync.WaitGroup{}
+	var (
+		group     = sync.WaitGroup{}
+		mu        sync.Mutex
+		running   []*Container
+		restarted []*Container
+	)
 	for _, c := range containers {
 		group.Add(1)
 
@@ -354,16 +368,64 @@ func (daemon *Daemon) restore() error {
 				return
 			}
 
+			mu.Lock()
+			defer mu.Unlock()
+			// the container kept running while the daemon was down
+			if container.IsRunning() {
+				running = append(running, container)
+				return
+			}
+
 			// check the restart policy on the containers and restart any container with
 			// the restart policy of "always"
 			if daemon.configStore.AutoRestart && container.shouldRestart() {
-				logrus.Debugf("Starting container %s", container.ID)
+				restarted = append(restarted, container)
+			}
+		}(c.container, c.registered)
+	}
+	group.Wait()
+
+	// the networking of the containers which kept running is restored
+	// instead of being cleaned up
+	daemon.netController, err = daemon.initNetworkController(daemon.configStore, daemon.activeSandboxes(running))
+	if err != nil {
+		return fmt.Errorf("Error initializing network controller: %v", err)
+	}
+
+	for _, c := range running {
+		group.Add(1)
+
+		go func(container *Container) {
+			defer group.Done()
+
+			logrus.Debugf("Restoring running container %s", container.ID)
+			if err := daemon.restoreRunningContainer(container); err != nil {
+				logrus.Errorf("Failed to restore running container %s: %v", container.ID, err)
+				daemon.terminateStaleContainer(container)
 
-				if err := daemon.containerStart(container); err != nil {
-					logrus.Errorf("Failed to start container %s: %s", container.ID, err)
+				if daemon.configStore.AutoRestart && container.shouldRestart() {
+					logrus.Debugf("Starting container %s", container.ID)
+
+					if err := daemon.containerStart(container); err != nil {
+						logrus.Errorf("Failed to start container %s: %s", container.ID, err)
+					}
 				}
 			}
-		}(c.container, c.registered)
+		}(c)
+	}
+	group.Wait()
+
+	for _, c := range restarted {
+		group.Add(1)
+
+		go func(container *Container) {
+			defer group.Done()
+
+			logrus.Debugf("Starting container %s", container.ID)
+			if err := daemon.containerStart(container); err != nil {
+				logrus.Errorf("Failed to start container %s: %s", container.ID, err)
+			}
+		}(c)
 	}
 	group.Wait()
 
@@ -536,36 +598,19 @@ func (daemon *Daemon) GetByName(name string) (*Container, error) {
 // GetEventFilter returns a filters.Filter for a set of filters
 func (daemon *Daemon) GetEventFilter(filter filters.Args) *events.Filter {
 	// incoming container filter can be name, id or partial id, convert to
-	// a full container id
+	// a full container id. Names of containers that don't exist anymore are
+	// kept, so that they are matched against the name attribute of the events.
 	for i, cn := range filter["container"] {
-		c, err := daemon.Get(cn)
-		if err != nil {
-			filter["container"][i] = ""
-		} else {
+		if c, err := daemon.Get(cn); err == nil {
 			filter["container"][i] = c.ID
 		}
 	}
-	return events.NewFilter(filter, daemon.GetLabels)
+	return events.NewFilter(filter)
 }
 
-// SubscribeToEvents returns the currently record of events, a channel to stream new events from, and a function to cancel the stream of events.
-func (daemon *Daemon) SubscribeToEvents() ([]*jsonmessage.JSONMessage, chan interface{}, func()) {
-	return daemon.EventsService.Subscribe()
-}
-
-// GetLabels for a container or image id
-func (daemon *Daemon) GetLabels(id string) map[string]string {
-	// TODO: TestCase
-	container := daemon.containers.Get(id)
-	if container != nil {
-		return container.Config.Labels
-	}
-
-	img, err := daemon.GetImage(id)
-	if err == nil {
-		return img.ContainerConfig.Labels
-	}
-	return nil
+// SubscribeToEvents calls replay for the recorded events that happened between since and until, and returns a channel to stream new events from, and a function to cancel the stream of events.
+func (daemon *Daemon) SubscribeToEvents(since, until time.Time, replay func(eventtypes.Message) error) (chan interface{}, func(), error) {
+	return daemon.EventsService.SubscribeSince(since, until, replay)
 }
 
 // children returns all child containers of the container with the
@@ -766,7 +811,10 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 		return nil, err
 	}
 
-	eventsService := events.New()
+	eventsService, err := events.NewWithJournal(filepath.Join(config.Root, "events"))
+	if err != nil {
+		return nil, err
+	}
 
 	tagStore, err := tag.NewTagStore(filepath.Join(imageRoot, "repositories.json"))
 	if err != nil {
@@ -790,19 +838,15 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 			return nil, fmt.Errorf("discovery advertise parsing failed (%v)", err)
 		}
 		config.ClusterAdvertise = advertise
-		d.discoveryWatcher, err = initDiscovery(config.ClusterStore, config.ClusterAdvertise, config.ClusterOpts)
+		d.discovery, err = initDiscovery(config.ClusterStore, config.ClusterAdvertise, config.ClusterOpts)
 		if err != nil {
 			return nil, fmt.Errorf("discovery initialization failed (%v)", err)
 		}
+		d.discoveryWatcher = d.discovery
 	} else if config.ClusterAdvertise != "" {
 		return nil, fmt.Errorf("invalid cluster configuration. --cluster-advertise must be accompanied by --cluster-store configuration")
 	}
 
-	d.netController, err = d.initNetworkController(config)
-	if err != nil {
-		return nil, fmt.Errorf("Error initializing network controller: %v", err)
-	}
-
 	graphdbPath := filepath.Join(config.Root, "linkgraph.db")
 	graph, err := graphdb.NewSqliteConn(graphdbPath)
 	if err != nil {
@@ -846,8 +890,11 @@ func NewDaemon(config *Config, registryService *registry.Service) (daemon *Daemo
 	d.uidMaps = uidMaps
 	d.gidMaps = gidMaps
 
-	if err := d.cleanupMounts(); err != nil {
-		return nil, err
+	// the mounts of the containers which kept running are still in use
+	if !config.LiveRestore {
+		if err := d.cleanupMounts(); err != nil {
+			return nil, err
+		}
 	}
 
 	go d.execCommandGC()
@@ -901,6 +948,8 @@ func (daemon *Daemon) shutdownContainer(c *Container) error {
 // Shutdown stops the daemon.
 func (daemon *Daemon) Shutdown() error {
 	daemon.shutdown = true
+	// keptRunning is set when containers are left running for live restore
+	keptRunning := false
 	if daemon.containers != nil {
 		group := sync.WaitGroup{}
 		logrus.Debug("starting clean shutdown of all containers...")
@@ -908,6 +957,11 @@ func (daemon *Daemon) Shutdown() error {
 			if !container.IsRunning() {
 				continue
 			}
+			if daemon.configStore.LiveRestore && container.canLiveRestore() {
+				logrus.Debugf("keeping %s running", container.ID)
+				keptRunning = true
+				continue
+			}
 			logrus.Debugf("stopping %s", container.ID)
 			group.Add(1)
 			go func(c *Container) {
@@ -922,8 +976,9 @@ func (daemon *Daemon) Shutdown() error {
 		group.Wait()
 	}
 
-	// trigger libnetwork Stop only if it's initialized
-	if daemon.netController != nil {
+	// trigger libnetwork Stop only if it's initialized, and leave the
+	// networking of the containers kept running untouched
+	if daemon.netController != nil && !keptRunning {
 		daemon.netController.Stop()
 	}
 
@@ -933,12 +988,23 @@ func (daemon *Daemon) Shutdown() error {
 		}
 	}
 
-	if daemon.driver != nil {
+	// the graph driver is not cleaned up when containers are kept running,
+	// so that the mounts of their root filesystems are left in place
+	if daemon.driver != nil && !keptRunning {
 		if err := daemon.driver.Cleanup(); err != nil {
 			logrus.Errorf("Error during graph storage driver.Cleanup(): %v", err)
 		}
 	}
 
+	if daemon.EventsService != nil {
+		if err := daemon.EventsService.Close(); err != nil {
+			logrus.Errorf("Error closing the events journal: %v", err)
+		}
+	}
+
+	if keptRunning {
+		return nil
+	}
 	if err := daemon.cleanupMounts(); err != nil {
 		return err
 	}
@@ -1000,6 +1066,17 @@ func (daemon *Daemon) Run(c *Container, pipes *execdriver.Pipes, startCallback e
 	return daemon.execDriver.Run(c.command, pipes, hooks)
 }
 
+// Restore reattaches to a container which kept running while the daemon
+// was down.
+func (daemon *Daemon) Restore(c *Container, pipes *execdriver.Pipes, startCallback execdriver.DriverCallback) (execdriver.ExitStatus, error) {
+	// the network namespace of the container is still linked to its
+	// restored sandbox
+	hooks := execdriver.Hooks{
+		Start: startCallback,
+	}
+	return daemon.execDriver.Restore(c.command, pipes, hooks)
+}
+
 func (daemon *Daemon) kill(c *Container, sig int) error {
 	return daemon.execDriver.Kill(c.command, sig)
 }
@@ -1036,24 +1113,27 @@ func (daemon *Daemon) TagImage(newTag reference.Named, imageName string, force b
 	if err != nil {
 		return err
 	}
-	daemon.EventsService.Log("tag", newTag.String(), "")
-	return daemon.tagStore.Add(newTag, imageID, force)
+	if err := daemon.tagStore.Add(newTag, imageID, force); err != nil {
+		return err
+	}
+	daemon.LogImageEvent(newTag.String(), newTag.String(), "tag")
+	return nil
 }
 
 // PullImage initiates a pull operation. image is the repository name to pull, and
 // tag may be either empty, or indicate a specific tag to pull.
 func (daemon *Daemon) PullImage(ref reference.Named, metaHeaders map[string][]string, authConfig *cliconfig.AuthConfig, outStream io.Writer) error {
 	imagePullConfig := &distribution.ImagePullConfig{
-		MetaHeaders:     metaHeaders,
-		AuthConfig:      authConfig,
-		OutStream:       outStream,
-		RegistryService: daemon.RegistryService,
-		EventsService:   daemon.EventsService,
-		MetadataStore:   daemon.distributionMetadataStore,
-		LayerStore:      daemon.layerStore,
-		ImageStore:      daemon.imageStore,
-		TagStore:        daemon.tagStore,
-		Pool:            daemon.distributionPool,
+		MetaHeaders:      metaHeaders,
+		AuthConfig:       authConfig,
+		OutStream:        outStream,
+		RegistryService:  daemon.RegistryService,
+		ImageEventLogger: daemon.LogImageEvent,
+		MetadataStore:    daemon.distributionMetadataStore,
+		LayerStore:       daemon.layerStore,
+		ImageStore:       daemon.imageStore,
+		TagStore:         daemon.tagStore,
+		Pool:             daemon.distributionPool,
 	}
 
 	return distribution.Pull(ref, imagePullConfig)
@@ -1072,16 +1152,16 @@ func (daemon *Daemon) ExportImage(names []string, outStream io.Writer) error {
 // PushImage initiates a push operation on the repository named localName.
 func (daemon *Daemon) PushImage(ref reference.Named, metaHeaders map[string][]string, authConfig *cliconfig.AuthConfig, outStream io.Writer) error {
 	imagePushConfig := &distribution.ImagePushConfig{
-		MetaHeaders:     metaHeaders,
-		AuthConfig:      authConfig,
-		OutStream:       outStream,
-		RegistryService: daemon.RegistryService,
-		EventsService:   daemon.EventsService,
-		MetadataStore:   daemon.distributionMetadataStore,
-		LayerStore:      daemon.layerStore,
-		ImageStore:      daemon.imageStore,
-		TagStore:        daemon.tagStore,
-		TrustKey:        daemon.trustKey,
+		MetaHeaders:      metaHeaders,
+		AuthConfig:       authConfig,
+		OutStream:        outStream,
+		RegistryService:  daemon.RegistryService,
+		ImageEventLogger: daemon.LogImageEvent,
+		MetadataStore:    daemon.distributionMetadataStore,
+		LayerStore:       daemon.layerStore,
+		ImageStore:       daemon.imageStore,
+		TagStore:         daemon.tagStore,
+		TrustKey:         daemon.trustKey,
 	}
 
 	return distribution.Push(ref, imagePushConfig)
@@ -1306,6 +1386,12 @@ func (daemon *Daemon) GetUIDGIDMaps() ([]idtools.IDMap, []idtools.IDMap) {
 	return daemon.uidMaps, daemon.gidMaps
 }
 
+// BuilderRoot returns the directory in which the builder keeps its data,
+// such as the build contexts of the build sessions.
+func (daemon *Daemon) BuilderRoot() string {
+	return filepath.Join(daemon.root, "builder")
+}
+
 // GetRemappedUIDGID returns the current daemon's uid and gid values
 // if user namespaces are in use for this daemon instance.  If not
 // this function will return "real" root values of 0, 0.
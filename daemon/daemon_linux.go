@@ -12,7 +12,7 @@ import (
 	"github.com/docker/docker/pkg/mount"
 )
 
-// cleanupMounts umounts shm/mqueue mounts for old containers
+// cleanupMounts umounts shm/mqueue and secrets mounts for old containers
 func (daemon *Daemon) cleanupMounts() error {
 	logrus.Debugf("Cleaning up old shm/mqueue mounts: start.")
 	f, err := os.Open("/proc/self/mountinfo")
@@ -37,7 +37,7 @@ func (daemon *Daemon) cleanupMountsFromReader(reader io.Reader, unmount func(tar
 			logrus.Debugf("Mount base: %v, repository %s", fields[4], daemon.repository)
 			mnt := fields[4]
 			mountBase := filepath.Base(mnt)
-			if mountBase == "mqueue" || mountBase == "shm" {
+			if mountBase == "mqueue" || mountBase == "shm" || mountBase == "secrets" {
 				logrus.Debugf("Unmounting %v", mnt)
 				if err := unmount(mnt); err != nil {
 					logrus.Error(err)
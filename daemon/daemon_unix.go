@@ -64,7 +64,7 @@ func parseSecurityOpt(container *Container, config *runconfig.HostConfig) error
 	)
 
 	for _, opt := range config.SecurityOpt {
-		con := strings.SplitN(opt, ":", 2)
+		con := runconfig.SplitSecurityOpt(opt)
 		if len(con) == 1 {
 			return fmt.Errorf("Invalid --security-opt: %q", opt)
 		}
@@ -73,6 +73,8 @@ func parseSecurityOpt(container *Container, config *runconfig.HostConfig) error
 			labelOpts = append(labelOpts, con[1])
 		case "apparmor":
 			container.AppArmorProfile = con[1]
+		case "seccomp":
+			container.SeccompProfile = con[1]
 		default:
 			return fmt.Errorf("Invalid --security-opt: %q", opt)
 		}
@@ -241,6 +243,27 @@ func verifyPlatformContainerSettings(daemon *Daemon, hostConfig *runconfig.HostC
 		logrus.Warnf("Your kernel does not support Block I/O weight_device. Weight-device discarded.")
 		hostConfig.BlkioWeightDevice = []*pblkiodev.WeightDevice{}
 	}
+	for dest := range hostConfig.Tmpfs {
+		if !filepath.IsAbs(dest) {
+			return warnings, fmt.Errorf("Invalid tmpfs destination path: '%s' mount path must be absolute.", dest)
+		}
+	}
+	if hostConfig.PidsLimit < -1 {
+		return warnings, fmt.Errorf("Invalid value: %d, the pids limit must be -1 (unlimited) or greater.", hostConfig.PidsLimit)
+	}
+	if hostConfig.PidsLimit != 0 && !sysInfo.PidsLimit {
+		warnings = append(warnings, "Your kernel does not support pids limit capabilities. Pids limit discarded.")
+		logrus.Warnf("Your kernel does not support pids limit capabilities. Pids limit discarded.")
+		hostConfig.PidsLimit = 0
+	}
+	if !supportsSeccomp {
+		for _, opt := range hostConfig.SecurityOpt {
+			con := runconfig.SplitSecurityOpt(opt)
+			if len(con) == 2 && con[0] == "seccomp" && con[1] != "unconfined" {
+				return warnings, fmt.Errorf("Seccomp is not supported by this daemon, a seccomp profile cannot be used.")
+			}
+		}
+	}
 	if hostConfig.OomKillDisable && !sysInfo.OomKillDisable {
 		hostConfig.OomKillDisable = false
 		return warnings, fmt.Errorf("Your kernel does not support oom kill disable.")
@@ -291,6 +314,9 @@ func configureKernelSecuritySupport(config *Config, driverName string) error {
 	} else {
 		selinuxSetDisabled()
 	}
+	if !supportsSeccomp {
+		logrus.Warn("Docker was built without seccomp support, containers run without a seccomp profile")
+	}
 	return nil
 }
 
@@ -341,11 +367,30 @@ func (daemon *Daemon) networkOptions(dconfig *Config) ([]nwconfig.Option, error)
 	return options, nil
 }
 
-func (daemon *Daemon) initNetworkController(config *Config) (libnetwork.NetworkController, error) {
+// activeSandboxes returns the options of the network sandboxes of the
+// containers which kept running while the daemon was down, by sandbox ID.
+func (daemon *Daemon) activeSandboxes(running []*Container) map[string]interface{} {
+	sandboxes := make(map[string]interface{})
+	for _, c := range running {
+		if c.NetworkSettings == nil || c.NetworkSettings.SandboxID == "" {
+			continue
+		}
+		options, err := daemon.buildSandboxConfigOptions(c)
+		if err != nil {
+			logrus.Warnf("Failed to build the network sandbox options of container %s: %v", c.ID, err)
+			continue
+		}
+		sandboxes[c.NetworkSettings.SandboxID] = options
+	}
+	return sandboxes
+}
+
+func (daemon *Daemon) initNetworkController(config *Config, activeSandboxes map[string]interface{}) (libnetwork.NetworkController, error) {
 	netOptions, err := daemon.networkOptions(config)
 	if err != nil {
 		return nil, err
 	}
+	netOptions = append(netOptions, nwconfig.OptionActiveSandboxes(activeSandboxes))
 
 	controller, err := libnetwork.New(netOptions...)
 	if err != nil {
@@ -363,8 +408,11 @@ func (daemon *Daemon) initNetworkController(config *Config) (libnetwork.NetworkC
 	}
 
 	if !config.DisableBridge {
-		// Initialize default driver "bridge"
-		if err := initBridgeDriver(controller, config); err != nil {
+		// Initialize default driver "bridge", unless containers kept
+		// running on the default bridge network, which is then reused
+		if n, err := controller.NetworkByName("bridge"); err == nil && len(activeSandboxes) > 0 {
+			logrus.Infof("Reusing the default bridge network %s of the restored containers, its configuration is not updated", n.ID())
+		} else if err := initBridgeDriver(controller, config); err != nil {
 			return nil, err
 		}
 	}
@@ -103,7 +103,12 @@ func isBridgeNetworkDisabled(config *Config) bool {
 	return false
 }
 
-func (daemon *Daemon) initNetworkController(config *Config) (libnetwork.NetworkController, error) {
+// activeSandboxes is a no-op on Windows.
+func (daemon *Daemon) activeSandboxes(running []*Container) map[string]interface{} {
+	return nil
+}
+
+func (daemon *Daemon) initNetworkController(config *Config, activeSandboxes map[string]interface{}) (libnetwork.NetworkController, error) {
 	// Set the name of the virtual switch if not specified by -b on daemon start
 	if config.Bridge.VirtualSwitchName == "" {
 		config.Bridge.VirtualSwitchName = defaultVirtualSwitch
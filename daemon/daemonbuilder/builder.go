@@ -31,6 +31,9 @@ type Docker struct {
 	OutOld      io.Writer
 	AuthConfigs map[string]cliconfig.AuthConfig
 	Archiver    *archive.Archiver
+	// Secrets are the build secrets by ID, which are mounted in the
+	// containers created with CreateWithSecrets while they run.
+	Secrets map[string][]byte
 }
 
 // ensure Docker implements builder.Docker
@@ -86,11 +89,22 @@ func (d Docker) Container(id string) (*daemon.Container, error) {
 
 // Create creates a new Docker container and returns potential warnings
 func (d Docker) Create(cfg *runconfig.Config, hostCfg *runconfig.HostConfig) (*daemon.Container, []string, error) {
+	return d.create(cfg, hostCfg, nil)
+}
+
+// CreateWithSecrets creates a new Docker container in which the build
+// secrets are mounted while it runs, and returns potential warnings.
+func (d Docker) CreateWithSecrets(cfg *runconfig.Config, hostCfg *runconfig.HostConfig) (*daemon.Container, []string, error) {
+	return d.create(cfg, hostCfg, d.Secrets)
+}
+
+func (d Docker) create(cfg *runconfig.Config, hostCfg *runconfig.HostConfig, secrets map[string][]byte) (*daemon.Container, []string, error) {
 	ccr, err := d.Daemon.ContainerCreate(&daemon.ContainerCreateConfig{
 		Name:            "",
 		Config:          cfg,
 		HostConfig:      hostCfg,
 		AdjustCPUShares: true,
+		Secrets:         secrets,
 	})
 	if err != nil {
 		return nil, nil, err
@@ -113,6 +127,12 @@ func (d Docker) Commit(name string, cfg *daemon.ContainerCommitConfig) (string,
 	return d.Daemon.Commit(name, cfg)
 }
 
+// SquashImage creates a new image from the image `id`, in which the layers
+// added on top of the image `parent` are squashed into a single layer.
+func (d Docker) SquashImage(id, parent string) (string, error) {
+	return d.Daemon.SquashImage(id, parent)
+}
+
 // Retain retains an image avoiding it to be removed or overwritten until a corresponding Release() call.
 func (d Docker) Retain(sessionID, imgID string) {
 	// FIXME: This will be solved with tags in client-side builder
@@ -211,6 +231,21 @@ func (d Docker) GetCachedImage(imgID string, cfg *runconfig.Config) (string, err
 	return cache.ID().String(), nil
 }
 
+// GetCachedImageFrom returns a reference to an image whose parent equals
+// `parent`, which was created with the runconfig `cfg` in the history of one
+// of the images `sources`.
+func (d Docker) GetCachedImageFrom(sources []string, imgID string, cfg, imageCfg *runconfig.Config) (string, error) {
+	ids := make([]image.ID, len(sources))
+	for i, source := range sources {
+		ids[i] = image.ID(source)
+	}
+	cache, err := d.Daemon.ImageGetCachedFrom(ids, image.ID(imgID), cfg, imageCfg)
+	if cache == nil || err != nil {
+		return "", err
+	}
+	return cache.ID().String(), nil
+}
+
 // Kill stops the container execution abruptly.
 func (d Docker) Kill(container *daemon.Container) error {
 	return d.Daemon.Kill(container)
@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"sync"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
@@ -18,18 +19,64 @@ const (
 	defaultDiscoveryTTL = 60 * time.Second
 )
 
+// daemonDiscovery registers the daemon against the discovery backend. The
+// backend and the advertised address can be changed when the configuration of
+// the daemon is reloaded.
+type daemonDiscovery struct {
+	mu      sync.Mutex
+	backend discovery.Backend
+	// stopCh stops the registration loop of the current backend
+	stopCh chan struct{}
+}
+
 // initDiscovery initialized the nodes discovery subsystem by connecting to the specified backend
 // and start a registration loop to advertise the current node under the specified address.
-func initDiscovery(backend, address string, clusterOpts map[string]string) (discovery.Backend, error) {
+func initDiscovery(backend, address string, clusterOpts map[string]string) (*daemonDiscovery, error) {
+	d := &daemonDiscovery{}
+	if err := d.reload(backend, address, clusterOpts); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Watch implements discovery.Watcher by watching the current backend.
+func (d *daemonDiscovery) Watch(stopCh <-chan struct{}) (<-chan discovery.Entries, <-chan error) {
+	d.mu.Lock()
+	backend := d.backend
+	d.mu.Unlock()
+	return backend.Watch(stopCh)
+}
+
+// reload connects to the specified backend, and advertises the current node
+// under the specified address there instead of the previous backend.
+func (d *daemonDiscovery) reload(backend, address string, clusterOpts map[string]string) error {
 	discoveryBackend, err := discovery.New(backend, defaultDiscoveryHeartbeat, defaultDiscoveryTTL, clusterOpts)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopCh != nil {
+		close(d.stopCh)
 	}
+	d.backend = discoveryBackend
+	d.stopCh = make(chan struct{})
 
 	// We call Register() on the discovery backend in a loop for the whole lifetime of the daemon,
 	// but we never actually Watch() for nodes appearing and disappearing for the moment.
-	go registrationLoop(discoveryBackend, address)
-	return discoveryBackend, nil
+	go registrationLoop(discoveryBackend, address, d.stopCh)
+	return nil
+}
+
+// stop stops advertising the current node.
+func (d *daemonDiscovery) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopCh != nil {
+		close(d.stopCh)
+		d.stopCh = nil
+	}
 }
 
 func registerAddr(backend discovery.Backend, addr string) {
@@ -39,11 +86,18 @@ func registerAddr(backend discovery.Backend, addr string) {
 }
 
 // registrationLoop registers the current node against the discovery backend using the specified
-// address. The function never returns, as registration against the backend comes with a TTL and
-// requires regular heartbeats.
-func registrationLoop(discoveryBackend discovery.Backend, address string) {
+// address. The function only returns when stopCh is closed, as registration against the backend
+// comes with a TTL and requires regular heartbeats.
+func registrationLoop(discoveryBackend discovery.Backend, address string, stopCh chan struct{}) {
 	registerAddr(discoveryBackend, address)
-	for range time.Tick(defaultDiscoveryHeartbeat) {
-		registerAddr(discoveryBackend, address)
+	ticker := time.NewTicker(defaultDiscoveryHeartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			registerAddr(discoveryBackend, address)
+		case <-stopCh:
+			return
+		}
 	}
 }
@@ -4,34 +4,54 @@ import (
 	"sync"
 	"time"
 
-	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/Sirupsen/logrus"
+	eventtypes "github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/pkg/pubsub"
 )
 
-const eventsLimit = 64
+const (
+	eventsLimit = 64
+	// untilReplayLimit is the maximum number of events replayed from the
+	// journal when until is set without since.
+	untilReplayLimit = 1024
+)
 
-// Events is pubsub channel for *jsonmessage.JSONMessage
+// Events is pubsub channel for events generated by the engine.
 type Events struct {
-	mu     sync.Mutex
-	events []*jsonmessage.JSONMessage
-	pub    *pubsub.Publisher
+	mu      sync.Mutex
+	events  []eventtypes.Message
+	pub     *pubsub.Publisher
+	journal *journal
 }
 
 // New returns new *Events instance
 func New() *Events {
 	return &Events{
-		events: make([]*jsonmessage.JSONMessage, 0, eventsLimit),
+		events: make([]eventtypes.Message, 0, eventsLimit),
 		pub:    pubsub.NewPublisher(100*time.Millisecond, 1024),
 	}
 }
 
+// NewWithJournal returns a new *Events instance which also appends the
+// events to a rotated journal in the root directory, so that they can be
+// replayed after a restart of the daemon.
+func NewWithJournal(root string) (*Events, error) {
+	j, err := newJournal(root)
+	if err != nil {
+		return nil, err
+	}
+	e := New()
+	e.journal = j
+	return e, nil
+}
+
 // Subscribe adds new listener to events, returns slice of 64 stored
 // last events, a channel in which you can expect new events (in form
 // of interface{}, so you need type assertion), and a function to call
 // to stop the stream of events.
-func (e *Events) Subscribe() ([]*jsonmessage.JSONMessage, chan interface{}, func()) {
+func (e *Events) Subscribe() ([]eventtypes.Message, chan interface{}, func()) {
 	e.mu.Lock()
-	current := make([]*jsonmessage.JSONMessage, len(e.events))
+	current := make([]eventtypes.Message, len(e.events))
 	copy(current, e.events)
 	l := e.pub.Subscribe()
 	e.mu.Unlock()
@@ -42,6 +62,64 @@ func (e *Events) Subscribe() ([]*jsonmessage.JSONMessage, chan interface{}, func
 	return current, l, cancel
 }
 
+// SubscribeSince adds new listener to events, like Subscribe, and calls
+// replay, in order, for the events that happened between since and until
+// before returning. The events are read from the journal when there is one,
+// or from the last 64 stored events otherwise. When until is set without
+// since, only the last untilReplayLimit events before until are replayed. No
+// events are replayed when both since and until are zero, and a zero until
+// means no upper bound. If replay or the reading of the journal fails, the
+// listener is evicted and the error is returned.
+func (e *Events) SubscribeSince(since, until time.Time, replay func(eventtypes.Message) error) (chan interface{}, func(), error) {
+	e.mu.Lock()
+	var (
+		snapshot *journalReader
+		current  []eventtypes.Message
+	)
+	if !since.IsZero() || !until.IsZero() {
+		if e.journal != nil {
+			var err error
+			if snapshot, err = e.journal.snapshot(); err != nil {
+				logrus.Errorf("Error opening the events journal: %v", err)
+			}
+		}
+		if snapshot == nil {
+			for _, ev := range e.events {
+				if inTimeRange(ev, since, until) {
+					current = append(current, ev)
+				}
+			}
+		}
+	}
+	l := e.pub.Subscribe()
+	e.mu.Unlock()
+
+	cancel := func() {
+		e.Evict(l)
+	}
+
+	var err error
+	if snapshot != nil {
+		limit := 0
+		if since.IsZero() {
+			limit = untilReplayLimit
+		}
+		err = snapshot.replayEvents(since, until, limit, replay)
+		snapshot.Close()
+	} else {
+		for _, ev := range current {
+			if err = replay(ev); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	return l, cancel, nil
+}
+
 // Evict evicts listener from pubsub
 func (e *Events) Evict(l chan interface{}) {
 	e.pub.Evict(l)
@@ -49,9 +127,27 @@ func (e *Events) Evict(l chan interface{}) {
 
 // Log broadcasts event to listeners. Each listener has 100 millisecond for
 // receiving event or it will be skipped.
-func (e *Events) Log(action, id, from string) {
+func (e *Events) Log(action, eventType string, actor eventtypes.Actor) {
 	now := time.Now().UTC()
-	jm := &jsonmessage.JSONMessage{Status: action, ID: id, From: from, Time: now.Unix(), TimeNano: now.UnixNano()}
+	jm := eventtypes.Message{
+		Action:   action,
+		Type:     eventType,
+		Actor:    actor,
+		Time:     now.Unix(),
+		TimeNano: now.UnixNano(),
+	}
+
+	// fill deprecated fields for container and images
+	switch eventType {
+	case eventtypes.ContainerEventType:
+		jm.ID = actor.ID
+		jm.Status = action
+		jm.From = actor.Attributes["image"]
+	case eventtypes.ImageEventType:
+		jm.ID = actor.ID
+		jm.Status = action
+	}
+
 	e.mu.Lock()
 	if len(e.events) == cap(e.events) {
 		// discard oldest event
@@ -60,10 +156,27 @@ func (e *Events) Log(action, id, from string) {
 	} else {
 		e.events = append(e.events, jm)
 	}
+	if e.journal != nil {
+		if err := e.journal.write(jm); err != nil {
+			logrus.Errorf("Error writing event to the journal: %v", err)
+		}
+	}
 	e.mu.Unlock()
 	e.pub.Publish(jm)
 }
 
+// Close closes the events journal, if any.
+func (e *Events) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.journal == nil {
+		return nil
+	}
+	err := e.journal.close()
+	e.journal = nil
+	return err
+}
+
 // SubscribersCount returns number of event listeners
 func (e *Events) SubscribersCount() int {
 	return e.pub.Len()
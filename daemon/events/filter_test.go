@@ -0,0 +1,68 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/pkg/parsers/filters"
+)
+
+func TestFilterInclude(t *testing.T) {
+	container := events.Message{
+		Type:   events.ContainerEventType,
+		Action: "start",
+		Actor: events.Actor{
+			ID:         "0123456789ab",
+			Attributes: map[string]string{"image": "busybox:latest", "name": "web", "com.example": "yes"},
+		},
+	}
+	network := events.Message{
+		Type:   events.NetworkEventType,
+		Action: "connect",
+		Actor: events.Actor{
+			ID:         "fedcba987654",
+			Attributes: map[string]string{"name": "backend", "type": "bridge", "container": "0123456789ab"},
+		},
+	}
+	volume := events.Message{
+		Type:   events.VolumeEventType,
+		Action: "create",
+		Actor: events.Actor{
+			ID:         "data",
+			Attributes: map[string]string{"driver": "local"},
+		},
+	}
+
+	cases := []struct {
+		filter   filters.Args
+		ev       events.Message
+		included bool
+	}{
+		{filters.Args{}, network, true},
+		{filters.Args{"type": {"container"}}, container, true},
+		{filters.Args{"type": {"container"}}, network, false},
+		{filters.Args{"type": {"volume", "network"}}, network, true},
+		{filters.Args{"event": {"connect"}}, network, true},
+		{filters.Args{"event": {"disconnect"}}, network, false},
+		{filters.Args{"container": {"web"}}, container, true},
+		{filters.Args{"container": {"0123"}}, container, true},
+		{filters.Args{"container": {"db"}}, container, false},
+		{filters.Args{"network": {"backend"}}, network, true},
+		{filters.Args{"network": {"fedcba"}}, network, true},
+		{filters.Args{"network": {"frontend"}}, network, false},
+		{filters.Args{"volume": {"data"}}, volume, true},
+		{filters.Args{"volume": {"data"}}, network, false},
+		{filters.Args{"image": {"busybox"}}, container, true},
+		{filters.Args{"image": {"busybox:latest"}}, container, true},
+		{filters.Args{"image": {"ubuntu"}}, container, false},
+		{filters.Args{"label": {"com.example=yes"}}, container, true},
+		{filters.Args{"label": {"com.example=no"}}, container, false},
+		{filters.Args{"label": {"com.example"}}, volume, false},
+	}
+
+	for _, c := range cases {
+		if included := NewFilter(c.filter).Include(c.ev); included != c.included {
+			t.Fatalf("Expected Include to be %v for %v with filter %v", c.included, c.ev, c.filter)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	eventtypes "github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/daemon/logger/loggerutils"
+)
+
+const (
+	journalFileName = "events.log"
+	// journalMaxSize is the maximum size of each journal file.
+	journalMaxSize = 10 * 1024 * 1024
+	// journalMaxFiles is the number of journal files kept, including the
+	// one currently written.
+	journalMaxFiles = 3
+)
+
+// journal appends events to a set of rotated files, one JSON encoded
+// message per line. It is not safe for concurrent use, the callers
+// serialize the access to it.
+type journal struct {
+	writer *loggerutils.RotateFileWriter
+}
+
+func newJournal(root string) (*journal, error) {
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(root, journalFileName)
+	if err := terminateLastLine(path); err != nil {
+		return nil, err
+	}
+	writer, err := loggerutils.NewRotateFileWriter(path, journalMaxSize, journalMaxFiles)
+	if err != nil {
+		return nil, err
+	}
+	return &journal{writer: writer}, nil
+}
+
+// terminateLastLine appends a new line to the journal file when the daemon
+// stopped in the middle of a write, so that the next events are not appended
+// to the partial line.
+func terminateLastLine(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	st, err := f.Stat()
+	if err != nil || st.Size() == 0 {
+		return err
+	}
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, st.Size()-1); err != nil {
+		return err
+	}
+	if last[0] == '\n' {
+		return nil
+	}
+	_, err = f.WriteAt([]byte{'\n'}, st.Size())
+	return err
+}
+
+func (j *journal) write(ev eventtypes.Message) error {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = j.writer.Write(append(b, '\n'))
+	return err
+}
+
+// snapshot opens the journal files, from the oldest to the newest, and
+// returns a reader limited to their current content, so that the events
+// written after the snapshot are not read back.
+func (j *journal) snapshot() (*journalReader, error) {
+	path := j.writer.LogPath()
+	files := make([]string, 0, journalMaxFiles)
+	for i := journalMaxFiles - 1; i > 0; i-- {
+		files = append(files, path+"."+strconv.Itoa(i))
+	}
+	files = append(files, path)
+
+	r := &journalReader{}
+	var readers []io.Reader
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			r.Close()
+			return nil, err
+		}
+		st, err := f.Stat()
+		if err != nil {
+			f.Close()
+			r.Close()
+			return nil, err
+		}
+		r.files = append(r.files, f)
+		readers = append(readers, io.LimitReader(f, st.Size()))
+	}
+	r.Reader = bufio.NewReader(io.MultiReader(readers...))
+	return r, nil
+}
+
+func (j *journal) close() error {
+	return j.writer.Close()
+}
+
+// journalReader reads the events of a journal snapshot.
+type journalReader struct {
+	*bufio.Reader
+	files []*os.File
+}
+
+// replayEvents calls fn, in order, for the events of the snapshot that
+// happened between since and until. A zero until means no upper bound. The
+// journal is read as a stream, and the reading stops at the first event after
+// until. If limit is not 0, only the last limit events are passed to fn, once
+// the events up to until are read. Lines that can't be decoded, like the last
+// line of a journal interrupted by a crash, are skipped.
+func (r *journalReader) replayEvents(since, until time.Time, limit int, fn func(eventtypes.Message) error) error {
+	var (
+		last  []eventtypes.Message // ring of the last events, with a limit
+		count int
+	)
+	if limit > 0 {
+		last = make([]eventtypes.Message, 0, limit)
+	}
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			var ev eventtypes.Message
+			if jsonErr := json.Unmarshal(line, &ev); jsonErr != nil {
+				logrus.Debugf("Skipping invalid entry in the events journal: %v", jsonErr)
+			} else if !until.IsZero() && eventTime(ev).After(until) {
+				break
+			} else if inTimeRange(ev, since, until) {
+				switch {
+				case limit == 0:
+					if err := fn(ev); err != nil {
+						return err
+					}
+				case len(last) < limit:
+					last = append(last, ev)
+				default:
+					last[count%limit] = ev
+				}
+				count++
+			}
+		}
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+	}
+
+	if limit == 0 {
+		return nil
+	}
+	start := 0
+	if count > limit {
+		start = count % limit
+	}
+	for i := range last {
+		if err := fn(last[(start+i)%len(last)]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the journal files.
+func (r *journalReader) Close() error {
+	for _, f := range r.files {
+		f.Close()
+	}
+	return nil
+}
+
+// inTimeRange reports whether ev happened between since and until. A zero
+// until means no upper bound.
+func inTimeRange(ev eventtypes.Message, since, until time.Time) bool {
+	t := eventTime(ev)
+	if t.Before(since) {
+		return false
+	}
+	return until.IsZero() || !t.After(until)
+}
+
+// eventTime returns the time of ev, with the precision of its TimeNano when
+// it is set.
+func eventTime(ev eventtypes.Message) time.Time {
+	if ev.TimeNano == 0 {
+		return time.Unix(ev.Time, 0)
+	}
+	return time.Unix(0, ev.TimeNano)
+}
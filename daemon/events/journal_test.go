@@ -0,0 +1,231 @@
+package events
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	eventtypes "github.com/docker/docker/api/types/events"
+)
+
+// subscribeSince subscribes to the events of e, and returns the events
+// replayed between since and until.
+func subscribeSince(t *testing.T, e *Events, since, until time.Time) ([]eventtypes.Message, chan interface{}, func()) {
+	var evs []eventtypes.Message
+	l, cancel, err := e.SubscribeSince(since, until, func(ev eventtypes.Message) error {
+		evs = append(evs, ev)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return evs, l, cancel
+}
+
+func TestJournalReplay(t *testing.T) {
+	root, err := ioutil.TempDir("", "events-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	e, err := NewWithJournal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < eventsLimit+16; i++ {
+		e.Log(fmt.Sprintf("action_%d", i), eventtypes.ContainerEventType, eventtypes.Actor{ID: "cont"})
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a crash in the middle of a write leaves a partial line
+	f, err := os.OpenFile(filepath.Join(root, journalFileName), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"status":"trunc`)
+	f.Close()
+
+	// the events are still available after a restart
+	e, err = NewWithJournal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	e.Log("restarted", eventtypes.DaemonEventType, eventtypes.Actor{ID: "daemon"})
+
+	current, l, cancel := subscribeSince(t, e, time.Unix(0, 0), time.Time{})
+	defer cancel()
+	if len(current) != eventsLimit+17 {
+		t.Fatalf("Expected %d events, got %d", eventsLimit+17, len(current))
+	}
+	if current[0].Action != "action_0" {
+		t.Fatalf("Expected the first event to be action_0, got %s", current[0].Action)
+	}
+	if current[len(current)-1].Action != "restarted" {
+		t.Fatalf("Expected the last event to be restarted, got %s", current[len(current)-1].Action)
+	}
+
+	// events logged after the subscription are only sent to the listener
+	e.Log("live", eventtypes.DaemonEventType, eventtypes.Actor{ID: "daemon"})
+	select {
+	case msg := <-l:
+		if ev := msg.(eventtypes.Message); ev.Action != "live" {
+			t.Fatalf("Expected the live event, got %s", ev.Action)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for broadcasted message")
+	}
+}
+
+func TestJournalReplayTimeRange(t *testing.T) {
+	root, err := ioutil.TempDir("", "events-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	e, err := NewWithJournal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	e.Log("before", eventtypes.ContainerEventType, eventtypes.Actor{ID: "cont"})
+	time.Sleep(10 * time.Millisecond)
+	since := time.Now()
+	e.Log("inside", eventtypes.ContainerEventType, eventtypes.Actor{ID: "cont"})
+	until := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	e.Log("after", eventtypes.ContainerEventType, eventtypes.Actor{ID: "cont"})
+
+	current, _, cancel := subscribeSince(t, e, since, until)
+	cancel()
+	if len(current) != 1 || current[0].Action != "inside" {
+		t.Fatalf("Expected only the inside event, got %v", current)
+	}
+
+	current, _, cancel = subscribeSince(t, e, time.Time{}, until)
+	cancel()
+	if len(current) != 2 || current[0].Action != "before" {
+		t.Fatalf("Expected the events up to until, got %v", current)
+	}
+
+	current, _, cancel = subscribeSince(t, e, time.Time{}, time.Time{})
+	cancel()
+	if len(current) != 0 {
+		t.Fatalf("Expected no events without since or until, got %v", current)
+	}
+
+	// the journal is read for an until alone too, after a restart
+	e.Close()
+	e, err = NewWithJournal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	current, _, cancel = subscribeSince(t, e, time.Time{}, until)
+	cancel()
+	if len(current) != 2 || current[0].Action != "before" || current[1].Action != "inside" {
+		t.Fatalf("Expected the events of the journal up to until, got %v", current)
+	}
+}
+
+func TestJournalReplayUntilLimit(t *testing.T) {
+	root, err := ioutil.TempDir("", "events-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	e, err := NewWithJournal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	for i := 0; i < untilReplayLimit+10; i++ {
+		e.Log(fmt.Sprintf("action_%d", i), eventtypes.ContainerEventType, eventtypes.Actor{ID: "cont"})
+	}
+
+	// only the last events before until are replayed without since
+	current, _, cancel := subscribeSince(t, e, time.Time{}, time.Now())
+	cancel()
+	if len(current) != untilReplayLimit {
+		t.Fatalf("Expected %d events, got %d", untilReplayLimit, len(current))
+	}
+	if current[0].Action != "action_10" || current[len(current)-1].Action != fmt.Sprintf("action_%d", untilReplayLimit+9) {
+		t.Fatalf("Expected the last events in order, got %s to %s", current[0].Action, current[len(current)-1].Action)
+	}
+
+	// the limit doesn't apply with since
+	current, _, cancel = subscribeSince(t, e, time.Unix(0, 1), time.Now())
+	cancel()
+	if len(current) != untilReplayLimit+10 {
+		t.Fatalf("Expected %d events, got %d", untilReplayLimit+10, len(current))
+	}
+
+	// an error of the replay is returned, and the listener evicted
+	replayErr := fmt.Errorf("replay error")
+	if _, _, err := e.SubscribeSince(time.Unix(0, 1), time.Time{}, func(eventtypes.Message) error { return replayErr }); err != replayErr {
+		t.Fatalf("Expected the replay error, got %v", err)
+	}
+	if n := e.SubscribersCount(); n != 0 {
+		t.Fatalf("Expected no subscribers, got %d", n)
+	}
+}
+
+func TestJournalRotate(t *testing.T) {
+	root, err := ioutil.TempDir("", "events-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	j, err := newJournal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.close()
+
+	// fill more than the first file, so that it is rotated
+	ev := eventtypes.Message{Action: "fill", Time: 1, TimeNano: 1}
+	ev.Actor.ID = strings.Repeat("a", 1024)
+	for i := 0; i < journalMaxSize/1024+10; i++ {
+		if err := j.write(ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ev.Action = "last"
+	if err := j.write(ev); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, journalFileName+".1")); err != nil {
+		t.Fatalf("Expected the journal to be rotated: %v", err)
+	}
+
+	r, err := j.snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	var evs []eventtypes.Message
+	err = r.replayEvents(time.Unix(0, 0), time.Time{}, 0, func(ev eventtypes.Message) error {
+		evs = append(evs, ev)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evs) != journalMaxSize/1024+11 {
+		t.Fatalf("Expected %d events across the rotated files, got %d", journalMaxSize/1024+11, len(evs))
+	}
+	if evs[len(evs)-1].Action != "last" {
+		t.Fatalf("Expected the last event to be read last, got %s", evs[len(evs)-1].Action)
+	}
+}
@@ -258,6 +258,9 @@ func (d *Daemon) containerExec(container *Container, ec *exec.Config) error {
 				c.Close()
 			}
 		}
+		ec.Lock()
+		ec.Pid = pid
+		ec.Unlock()
 		ec.Close()
 		return nil
 	}
@@ -19,6 +19,7 @@ type Config struct {
 	ID            string
 	Running       bool
 	ExitCode      int
+	Pid           int // pid of the process on the host, once it is started
 	ProcessConfig *execdriver.ProcessConfig
 	OpenStdin     bool
 	OpenStderr    bool
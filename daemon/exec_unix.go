@@ -3,6 +3,8 @@
 package daemon
 
 import (
+	"syscall"
+
 	"github.com/docker/docker/daemon/execdriver"
 	"github.com/docker/docker/runconfig"
 )
@@ -18,3 +20,8 @@ func setPlatformSpecificExecProcessConfig(config *runconfig.ExecConfig, containe
 	pc.User = user
 	pc.Privileged = config.Privileged
 }
+
+// killExecProcess kills the process of an exec instance.
+func killExecProcess(pid int) error {
+	return syscall.Kill(pid, syscall.SIGKILL)
+}
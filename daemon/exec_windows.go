@@ -1,6 +1,8 @@
 package daemon
 
 import (
+	"os"
+
 	"github.com/docker/docker/daemon/execdriver"
 	"github.com/docker/docker/runconfig"
 )
@@ -9,3 +11,12 @@ import (
 // ProcessConfig structure. This is a no-op on Windows
 func setPlatformSpecificExecProcessConfig(config *runconfig.ExecConfig, container *Container, pc *execdriver.ProcessConfig) {
 }
+
+// killExecProcess kills the process of an exec instance.
+func killExecProcess(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Kill()
+}
@@ -59,6 +59,11 @@ type Driver interface {
 	// the exit code. It's the last stage on Docker side for running a container.
 	Run(c *Command, pipes *Pipes, hooks Hooks) (ExitStatus, error)
 
+	// Restore reattaches to the process of a container which kept running
+	// while the daemon was down, blocks until the process exits and returns
+	// the exit code. It returns ErrNotRunning if the process is gone.
+	Restore(c *Command, pipes *Pipes, hooks Hooks) (ExitStatus, error)
+
 	// Exec executes the process in an existing container, blocks until the
 	// process exits and returns the exit code.
 	Exec(c *Command, processConfig *ProcessConfig, pipes *Pipes, hooks Hooks) (int, error)
@@ -91,6 +96,9 @@ type Driver interface {
 	// Stats returns resource stats for a running container
 	Stats(id string) (*ResourceStats, error)
 
+	// Update updates resource configs for a running container.
+	Update(c *Command) error
+
 	// SupportsHooks refers to the driver capability to exploit pre/post hook functionality
 	SupportsHooks() bool
 }
@@ -110,6 +118,7 @@ type ResourceStats struct {
 	Read        time.Time `json:"read"`
 	MemoryLimit int64     `json:"memory_limit"`
 	SystemUsage uint64    `json:"system_usage"`
+	PidsCurrent uint64    `json:"pids_current"`
 }
 
 // CommonProcessConfig is the common platform agnostic part of the ProcessConfig
@@ -28,6 +28,7 @@ type Mount struct {
 	Writable    bool   `json:"writable"`
 	Private     bool   `json:"private"`
 	Slave       bool   `json:"slave"`
+	Data        string `json:"data"`
 }
 
 // Resources contains all resource configs for a driver.
@@ -47,6 +48,7 @@ type Resources struct {
 	Rlimits           []*ulimit.Rlimit         `json:"rlimits"`
 	OomKillDisable    bool                     `json:"oom_kill_disable"`
 	MemorySwappiness  int64                    `json:"memory_swappiness"`
+	PidsLimit         int64                    `json:"pids_limit"`
 }
 
 // ProcessConfig is the platform specific structure that describes a process
@@ -113,9 +115,11 @@ type Command struct {
 	GIDMapping         []idtools.IDMap   `json:"gidmapping"`
 	GroupAdd           []string          `json:"group_add"`
 	Ipc                *Ipc              `json:"ipc"`
+	LiveRestore        bool              `json:"live_restore"` // Keep the container running when the daemon exits.
 	Pid                *Pid              `json:"pid"`
 	ReadonlyRootfs     bool              `json:"readonly_rootfs"`
 	RemappedRoot       *User             `json:"remap_root"`
+	SeccompProfile     string            `json:"seccomp_profile"`
 	UIDMapping         []idtools.IDMap   `json:"uidmapping"`
 	UTS                *UTS              `json:"uts"`
 }
@@ -161,6 +165,7 @@ func SetupCgroups(container *configs.Config, c *Command) error {
 		container.Cgroups.Memory = c.Resources.Memory
 		container.Cgroups.MemoryReservation = c.Resources.MemoryReservation
 		container.Cgroups.MemorySwap = c.Resources.MemorySwap
+		container.Cgroups.KernelMemory = c.Resources.KernelMemory
 		container.Cgroups.CpusetCpus = c.Resources.CpusetCpus
 		container.Cgroups.CpusetMems = c.Resources.CpusetMems
 		container.Cgroups.CpuPeriod = c.Resources.CPUPeriod
@@ -169,6 +174,7 @@ func SetupCgroups(container *configs.Config, c *Command) error {
 		container.Cgroups.BlkioWeightDevice = c.Resources.BlkioWeightDevice
 		container.Cgroups.OomKillDisable = c.Resources.OomKillDisable
 		container.Cgroups.MemorySwappiness = c.Resources.MemorySwappiness
+		container.Cgroups.PidsLimit = c.Resources.PidsLimit
 	}
 
 	return nil
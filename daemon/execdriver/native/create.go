@@ -8,6 +8,7 @@ import (
 	"syscall"
 
 	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/pkg/mount"
 
 	"github.com/opencontainers/runc/libcontainer/apparmor"
 	"github.com/opencontainers/runc/libcontainer/configs"
@@ -79,6 +80,10 @@ func (d *Driver) createContainer(c *execdriver.Command, hooks execdriver.Hooks)
 		container.AppArmorProfile = c.AppArmorProfile
 	}
 
+	if err := d.setupSeccomp(container, c); err != nil {
+		return nil, err
+	}
+
 	if err := execdriver.SetupCgroups(container, c); err != nil {
 		return nil, err
 	}
@@ -248,6 +253,22 @@ func (d *Driver) setPrivileged(container *configs.Config) (err error) {
 	return nil
 }
 
+// setupSeccomp sets the seccomp profile of the container: the default
+// profile, unless the container is privileged or has its own profile.
+func (d *Driver) setupSeccomp(container *configs.Config, c *execdriver.Command) (err error) {
+	switch c.SeccompProfile {
+	case "":
+		if !c.ProcessConfig.Privileged {
+			container.Seccomp = getDefaultSeccompProfile()
+		}
+	case "unconfined":
+		container.Seccomp = nil
+	default:
+		container.Seccomp, err = loadSeccompProfile(c.SeccompProfile)
+	}
+	return err
+}
+
 func (d *Driver) setCapabilities(container *configs.Config, c *execdriver.Command) (err error) {
 	container.Capabilities, err = execdriver.TweakCapabilities(container.Capabilities, c.CapAdd, c.CapDrop)
 	return err
@@ -288,6 +309,28 @@ func (d *Driver) setupMounts(container *configs.Config, c *execdriver.Command) e
 	container.Mounts = defaultMounts
 
 	for _, m := range c.Mounts {
+		if m.Source == "tmpfs" {
+			var (
+				data  = "size=65536k"
+				flags = syscall.MS_NOEXEC | syscall.MS_NOSUID | syscall.MS_NODEV
+				err   error
+			)
+			if m.Data != "" {
+				flags, data, err = mount.ParseTmpfsOptions(m.Data)
+				if err != nil {
+					return err
+				}
+			}
+			container.Mounts = append(container.Mounts, &configs.Mount{
+				Source:      m.Source,
+				Destination: m.Destination,
+				Data:        data,
+				Device:      "tmpfs",
+				Flags:       flags,
+			})
+			continue
+		}
+
 		flags := syscall.MS_BIND | syscall.MS_REC
 		if !m.Writable {
 			flags |= syscall.MS_RDONLY
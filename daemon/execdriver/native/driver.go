@@ -143,8 +143,13 @@ func (d *Driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, hooks execd
 		User: c.ProcessConfig.User,
 	}
 
-	if err := setupPipes(container, &c.ProcessConfig, p, pipes); err != nil {
-		return execdriver.ExitStatus{ExitCode: -1}, err
+	// a container with a tty can't outlive the daemon, as its console is
+	// closed when the daemon exits
+	liveRestore := c.LiveRestore && !c.ProcessConfig.Tty
+	if !liveRestore {
+		if err := setupPipes(container, &c.ProcessConfig, p, pipes); err != nil {
+			return execdriver.ExitStatus{ExitCode: -1}, err
+		}
 	}
 
 	cont, err := d.factory.Create(c.ID, container)
@@ -161,9 +166,22 @@ func (d *Driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, hooks execd
 		d.cleanContainer(c.ID)
 	}()
 
+	var fio *fifoIO
+	if liveRestore {
+		// the fifos live in the state directory of the container, which
+		// exists once the container is created
+		if fio, err = setupFifos(filepath.Join(d.root, c.ID), container, &c.ProcessConfig, p, pipes); err != nil {
+			return execdriver.ExitStatus{ExitCode: -1}, err
+		}
+		defer fio.Close()
+	}
+
 	if err := cont.Start(p); err != nil {
 		return execdriver.ExitStatus{ExitCode: -1}, err
 	}
+	if fio != nil {
+		fio.Close()
+	}
 
 	oom := notifyOnOOM(cont)
 	if hooks.Start != nil {
@@ -192,10 +210,95 @@ func (d *Driver) Run(c *execdriver.Command, pipes *execdriver.Pipes, hooks execd
 	}
 	cont.Destroy()
 	destroyed = true
+	if fio != nil {
+		fio.Wait()
+	}
 	_, oomKill := <-oom
 	return execdriver.ExitStatus{ExitCode: utils.ExitStatus(ps.Sys().(syscall.WaitStatus)), OOMKilled: oomKill}, nil
 }
 
+// Restore implements the exec driver Driver interface,
+// it reattaches to a container started with live restore enabled by a
+// previous instance of the daemon.
+func (d *Driver) Restore(c *execdriver.Command, pipes *execdriver.Pipes, hooks execdriver.Hooks) (execdriver.ExitStatus, error) {
+	dir := filepath.Join(d.root, c.ID)
+	cont, err := d.factory.Load(c.ID)
+	if err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+	state, err := cont.State()
+	if err == nil {
+		var startTime string
+		startTime, err = system.GetProcessStartTime(state.InitProcessPid)
+		if err == nil && startTime != state.InitProcessStartTime {
+			err = execdriver.ErrNotRunning
+		}
+	}
+	if err != nil {
+		// the container exited while the daemon was down
+		cont.Destroy()
+		d.cleanContainer(c.ID)
+		return execdriver.ExitStatus{ExitCode: -1}, execdriver.ErrNotRunning
+	}
+	if _, err := os.Stat(filepath.Join(dir, stdoutFifo)); err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, fmt.Errorf("container %s was not started with live restore enabled", c.ID)
+	}
+
+	pid := state.InitProcessPid
+	for _, hook := range hooks.PreStart {
+		if err := hook(&c.ProcessConfig, pid, nil); err != nil {
+			return execdriver.ExitStatus{ExitCode: -1}, err
+		}
+	}
+	fio, err := attachFifos(dir, &c.ProcessConfig, pipes)
+	if err != nil {
+		return execdriver.ExitStatus{ExitCode: -1}, err
+	}
+
+	d.Lock()
+	d.activeContainers[c.ID] = cont
+	d.Unlock()
+
+	oom := notifyOnOOM(cont)
+	if hooks.Start != nil {
+		hooks.Start(&c.ProcessConfig, pid, oom)
+	}
+
+	// the container process is not a child of the daemon anymore, so it
+	// can't be waited for and its exit status is unknown
+	waitForProcessExit(pid, state.InitProcessStartTime)
+	if err := cont.Destroy(); err != nil {
+		logrus.Warnf("Failed to destroy container %s: %v", c.ID, err)
+	}
+	d.cleanContainer(c.ID)
+	fio.Wait()
+	_, oomKill := <-oom
+	exitCode := restoredExitCode
+	if oomKill {
+		// the process was killed by the kernel
+		exitCode = 128 + int(syscall.SIGKILL)
+	}
+	return execdriver.ExitStatus{ExitCode: exitCode, OOMKilled: oomKill}, nil
+}
+
+// restoredExitCode is the exit code reported for a restored container, as
+// its exit status is unknown: the process was reparented to init when the
+// previous daemon exited, so the daemon can't become its subreaper. It isn't
+// 0, so that the exit is treated as a failure by the restart policies rather
+// than a crash being reported as a success.
+const restoredExitCode = 255
+
+// waitForProcessExit polls until the process identified by its pid and
+// start time exits.
+func waitForProcessExit(pid int, startTime string) {
+	for {
+		if t, err := system.GetProcessStartTime(pid); err != nil || t != startTime {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
 // notifyOnOOM returns a channel that signals if the container received an OOM notification
 // for any process. If it is unable to subscribe to OOM notifications then a closed
 // channel is returned as it will be non-blocking and return the correct result when read.
@@ -387,9 +490,26 @@ func (d *Driver) Stats(id string) (*execdriver.ResourceStats, error) {
 		Stats:       stats,
 		Read:        now,
 		MemoryLimit: memoryLimit,
+		PidsCurrent: stats.CgroupStats.PidsStats.Current,
 	}, nil
 }
 
+// Update implements the exec driver Driver interface,
+// it applies the resources of the command to the live cgroups.
+func (d *Driver) Update(c *execdriver.Command) error {
+	d.Lock()
+	cont := d.activeContainers[c.ID]
+	d.Unlock()
+	if cont == nil {
+		return execdriver.ErrNotRunning
+	}
+	config := cont.Config()
+	if err := execdriver.SetupCgroups(&config, c); err != nil {
+		return err
+	}
+	return cont.Set(config)
+}
+
 // TtyConsole implements the exec driver Terminal interface.
 type TtyConsole struct {
 	console libcontainer.Console
@@ -0,0 +1,178 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/opencontainers/runc/libcontainer"
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+// The stdio of a container started with live restore enabled goes through
+// named pipes in the state directory of the container instead of anonymous
+// pipes, so that the container keeps running when the daemon exits and its
+// output can be attached again by the next instance of the daemon.
+const (
+	stdinFifo  = "stdin"
+	stdoutFifo = "stdout"
+	stderrFifo = "stderr"
+)
+
+// fifoIO holds the stdio fifos of a container.
+type fifoIO struct {
+	// files are the ends of the fifos passed to the container process,
+	// which are closed in the daemon once the process is started.
+	files []*os.File
+	// copying tracks the copies of the output of the container.
+	copying sync.WaitGroup
+}
+
+// setupFifos creates the stdio fifos of the container in dir and connects
+// them to the process and to the pipes of the daemon.
+func setupFifos(dir string, container *configs.Config, processConfig *execdriver.ProcessConfig, p *libcontainer.Process, pipes *execdriver.Pipes) (_ *fifoIO, err error) {
+	rootuid, err := container.HostUID()
+	if err != nil {
+		return nil, err
+	}
+	rootgid, err := container.HostGID()
+	if err != nil {
+		return nil, err
+	}
+
+	fio := &fifoIO{}
+	term := &execdriver.StdConsole{}
+	defer func() {
+		if err != nil {
+			fio.Close()
+			term.Close()
+		}
+	}()
+
+	for _, name := range []string{stdinFifo, stdoutFifo, stderrFifo} {
+		path := filepath.Join(dir, name)
+		if err := syscall.Mkfifo(path, 0600); err != nil {
+			return nil, err
+		}
+		if err := os.Chown(path, rootuid, rootgid); err != nil {
+			return nil, err
+		}
+	}
+
+	// The container holds the output fifos open for reading and writing,
+	// so that writing to them never fails while there is no daemon reading.
+	for _, out := range []struct {
+		name string
+		dst  *io.Writer
+		w    io.Writer
+	}{
+		{stdoutFifo, &p.Stdout, pipes.Stdout},
+		{stderrFifo, &p.Stderr, pipes.Stderr},
+	} {
+		path := filepath.Join(dir, out.name)
+		f, err := os.OpenFile(path, os.O_RDWR, 0)
+		if err != nil {
+			return nil, err
+		}
+		fio.files = append(fio.files, f)
+		*out.dst = f
+
+		r, err := openFifo(path, syscall.O_RDONLY)
+		if err != nil {
+			return nil, err
+		}
+		term.Closers = append(term.Closers, r)
+		fio.copy(out.w, r)
+	}
+
+	if pipes.Stdin != nil {
+		path := filepath.Join(dir, stdinFifo)
+		r, err := openFifo(path, syscall.O_RDONLY)
+		if err != nil {
+			return nil, err
+		}
+		fio.files = append(fio.files, r)
+		p.Stdin = r
+
+		w, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return nil, err
+		}
+		go func() {
+			io.Copy(w, pipes.Stdin)
+			w.Close()
+		}()
+	}
+	processConfig.Terminal = term
+	return fio, nil
+}
+
+// attachFifos connects the output fifos of a running container to the pipes
+// of the daemon. The stdin of the container was closed when the previous
+// daemon exited, so it is not attached again.
+func attachFifos(dir string, processConfig *execdriver.ProcessConfig, pipes *execdriver.Pipes) (*fifoIO, error) {
+	fio := &fifoIO{}
+	term := &execdriver.StdConsole{}
+	for _, out := range []struct {
+		name string
+		w    io.Writer
+	}{
+		{stdoutFifo, pipes.Stdout},
+		{stderrFifo, pipes.Stderr},
+	} {
+		r, err := openFifo(filepath.Join(dir, out.name), syscall.O_RDONLY)
+		if err != nil {
+			term.Close()
+			return nil, err
+		}
+		term.Closers = append(term.Closers, r)
+		fio.copy(out.w, r)
+	}
+	processConfig.Terminal = term
+	return fio, nil
+}
+
+// openFifo opens a fifo without waiting for the other end to be opened, and
+// returns it in blocking mode.
+func openFifo(path string, flag int) (*os.File, error) {
+	fd, err := syscall.Open(path, flag|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: path, Err: err}
+	}
+	if err := syscall.SetNonblock(fd, false); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	return os.NewFile(uintptr(fd), path), nil
+}
+
+func (fio *fifoIO) copy(w io.Writer, r io.Reader) {
+	if w == nil {
+		w = ioutil.Discard
+	}
+	fio.copying.Add(1)
+	go func() {
+		io.Copy(w, r)
+		fio.copying.Done()
+	}()
+}
+
+// Close closes the ends of the fifos held for the container process.
+func (fio *fifoIO) Close() error {
+	for _, f := range fio.files {
+		f.Close()
+	}
+	fio.files = nil
+	return nil
+}
+
+// Wait waits until all the output of the container has been copied.
+func (fio *fifoIO) Wait() {
+	fio.copying.Wait()
+}
@@ -0,0 +1,154 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+// loadSeccompProfile parses a JSON seccomp profile and converts it to the
+// libcontainer configuration.
+func loadSeccompProfile(body string) (*configs.Seccomp, error) {
+	var config types.Seccomp
+	if err := json.Unmarshal([]byte(body), &config); err != nil {
+		return nil, fmt.Errorf("Decoding seccomp profile failed: %v", err)
+	}
+
+	return setupSeccomp(&config)
+}
+
+// setupSeccomp converts a seccomp profile to the libcontainer configuration.
+func setupSeccomp(config *types.Seccomp) (*configs.Seccomp, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	// No default action specified, no syscalls listed, assume seccomp disabled
+	if config.DefaultAction == "" && len(config.Syscalls) == 0 {
+		return nil, nil
+	}
+
+	newConfig := &configs.Seccomp{
+		Syscalls: []*configs.Syscall{},
+	}
+
+	// if len(config.Architectures) == 0 then libseccomp will figure out the architecture to use
+	for _, arch := range config.Architectures {
+		newArch, err := convertArch(arch)
+		if err != nil {
+			return nil, err
+		}
+		newConfig.Architectures = append(newConfig.Architectures, newArch)
+	}
+
+	defaultAction, err := convertAction(config.DefaultAction)
+	if err != nil {
+		return nil, err
+	}
+	newConfig.DefaultAction = defaultAction
+
+	for _, call := range config.Syscalls {
+		if call == nil || call.Name == "" {
+			return nil, fmt.Errorf("Invalid seccomp profile: syscall without a name")
+		}
+
+		newCall := &configs.Syscall{
+			Name: call.Name,
+			Args: []*configs.Arg{},
+		}
+		if newCall.Action, err = convertAction(call.Action); err != nil {
+			return nil, err
+		}
+
+		for _, arg := range call.Args {
+			newOp, err := convertOperator(arg.Op)
+			if err != nil {
+				return nil, err
+			}
+			newCall.Args = append(newCall.Args, &configs.Arg{
+				Index:    arg.Index,
+				Value:    arg.Value,
+				ValueTwo: arg.ValueTwo,
+				Op:       newOp,
+			})
+		}
+
+		newConfig.Syscalls = append(newConfig.Syscalls, newCall)
+	}
+
+	return newConfig, nil
+}
+
+// convertArch converts a seccomp architecture name, as found in profiles, to
+// the name used by libseccomp.
+func convertArch(arch types.Arch) (string, error) {
+	switch arch {
+	case types.ArchX86:
+		return "x86", nil
+	case types.ArchX86_64:
+		return "amd64", nil
+	case types.ArchX32:
+		return "x32", nil
+	case types.ArchARM:
+		return "arm", nil
+	case types.ArchAARCH64:
+		return "arm64", nil
+	case types.ArchMIPS:
+		return "mips", nil
+	case types.ArchMIPS64:
+		return "mips64", nil
+	case types.ArchMIPS64N32:
+		return "mips64n32", nil
+	case types.ArchMIPSEL:
+		return "mipsel", nil
+	case types.ArchMIPSEL64:
+		return "mipsel64", nil
+	case types.ArchMIPSEL64N32:
+		return "mipsel64n32", nil
+	default:
+		return "", fmt.Errorf("Invalid seccomp architecture %q", arch)
+	}
+}
+
+// convertAction converts a seccomp rule action to its libcontainer value.
+func convertAction(action types.Action) (configs.Action, error) {
+	switch action {
+	case types.ActKill:
+		return configs.Kill, nil
+	case types.ActErrno:
+		return configs.Errno, nil
+	case types.ActTrap:
+		return configs.Trap, nil
+	case types.ActAllow:
+		return configs.Allow, nil
+	default:
+		return 0, fmt.Errorf("Invalid seccomp action %q", action)
+	}
+}
+
+// convertOperator converts a seccomp argument comparison operator to its
+// libcontainer value.
+func convertOperator(op types.Operator) (configs.Operator, error) {
+	switch op {
+	case types.OpNotEqual:
+		return configs.NotEqualTo, nil
+	case types.OpLessThan:
+		return configs.LessThan, nil
+	case types.OpLessEqual:
+		return configs.LessThanOrEqualTo, nil
+	case types.OpEqualTo:
+		return configs.EqualTo, nil
+	case types.OpGreaterEqual:
+		return configs.GreaterThanOrEqualTo, nil
+	case types.OpGreaterThan:
+		return configs.GreaterThan, nil
+	case types.OpMaskedEqual:
+		return configs.MaskEqualTo, nil
+	default:
+		return 0, fmt.Errorf("Invalid seccomp operator %q", op)
+	}
+}
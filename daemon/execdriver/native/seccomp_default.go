@@ -0,0 +1,412 @@
+// +build linux,cgo,seccomp
+
+package native
+
+import (
+	"runtime"
+	"syscall"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api/types"
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+// allowedSyscalls are the syscalls allowed by the default profile, without
+// any restriction on their arguments. The syscalls that are not available on
+// the architecture of the kernel are ignored.
+var allowedSyscalls = []string{
+	"accept",
+	"accept4",
+	"access",
+	"alarm",
+	"arch_prctl",
+	"bind",
+	"brk",
+	"capget",
+	"capset",
+	"chdir",
+	"chmod",
+	"chown",
+	"chown32",
+	"chroot",
+	"clock_getres",
+	"clock_gettime",
+	"clock_nanosleep",
+	"close",
+	"connect",
+	"creat",
+	"dup",
+	"dup2",
+	"dup3",
+	"epoll_create",
+	"epoll_create1",
+	"epoll_ctl",
+	"epoll_ctl_old",
+	"epoll_pwait",
+	"epoll_wait",
+	"epoll_wait_old",
+	"eventfd",
+	"eventfd2",
+	"execve",
+	"execveat",
+	"exit",
+	"exit_group",
+	"faccessat",
+	"fadvise64",
+	"fadvise64_64",
+	"fallocate",
+	"fanotify_init",
+	"fanotify_mark",
+	"fchdir",
+	"fchmod",
+	"fchmodat",
+	"fchown",
+	"fchown32",
+	"fchownat",
+	"fcntl",
+	"fcntl64",
+	"fdatasync",
+	"fgetxattr",
+	"flistxattr",
+	"flock",
+	"fork",
+	"fremovexattr",
+	"fsetxattr",
+	"fstat",
+	"fstat64",
+	"fstatat64",
+	"fstatfs",
+	"fstatfs64",
+	"fsync",
+	"ftruncate",
+	"ftruncate64",
+	"futex",
+	"futimesat",
+	"getcpu",
+	"getcwd",
+	"getdents",
+	"getdents64",
+	"getegid",
+	"getegid32",
+	"geteuid",
+	"geteuid32",
+	"getgid",
+	"getgid32",
+	"getgroups",
+	"getgroups32",
+	"getitimer",
+	"getpeername",
+	"getpgid",
+	"getpgrp",
+	"getpid",
+	"getppid",
+	"getpriority",
+	"getrandom",
+	"getresgid",
+	"getresgid32",
+	"getresuid",
+	"getresuid32",
+	"getrlimit",
+	"get_robust_list",
+	"getrusage",
+	"getsid",
+	"getsockname",
+	"getsockopt",
+	"get_thread_area",
+	"gettid",
+	"gettimeofday",
+	"getuid",
+	"getuid32",
+	"getxattr",
+	"inotify_add_watch",
+	"inotify_init",
+	"inotify_init1",
+	"inotify_rm_watch",
+	"io_cancel",
+	"ioctl",
+	"io_destroy",
+	"io_getevents",
+	"ioprio_get",
+	"ioprio_set",
+	"io_setup",
+	"io_submit",
+	"ipc",
+	"kill",
+	"lchown",
+	"lchown32",
+	"lgetxattr",
+	"link",
+	"linkat",
+	"listen",
+	"listxattr",
+	"llistxattr",
+	"_llseek",
+	"lremovexattr",
+	"lseek",
+	"lsetxattr",
+	"lstat",
+	"lstat64",
+	"madvise",
+	"memfd_create",
+	"mincore",
+	"mkdir",
+	"mkdirat",
+	"mknod",
+	"mknodat",
+	"mlock",
+	"mlockall",
+	"mmap",
+	"mmap2",
+	"mprotect",
+	"mq_getsetattr",
+	"mq_notify",
+	"mq_open",
+	"mq_timedreceive",
+	"mq_timedsend",
+	"mq_unlink",
+	"mremap",
+	"msgctl",
+	"msgget",
+	"msgrcv",
+	"msgsnd",
+	"msync",
+	"munlock",
+	"munlockall",
+	"munmap",
+	"nanosleep",
+	"newfstatat",
+	"_newselect",
+	"open",
+	"openat",
+	"pause",
+	"pipe",
+	"pipe2",
+	"poll",
+	"ppoll",
+	"prctl",
+	"pread64",
+	"preadv",
+	"prlimit64",
+	"pselect6",
+	"pwrite64",
+	"pwritev",
+	"read",
+	"readahead",
+	"readlink",
+	"readlinkat",
+	"readv",
+	"recv",
+	"recvfrom",
+	"recvmmsg",
+	"recvmsg",
+	"remap_file_pages",
+	"removexattr",
+	"rename",
+	"renameat",
+	"renameat2",
+	"rmdir",
+	"rt_sigaction",
+	"rt_sigpending",
+	"rt_sigprocmask",
+	"rt_sigqueueinfo",
+	"rt_sigreturn",
+	"rt_sigsuspend",
+	"rt_sigtimedwait",
+	"rt_tgsigqueueinfo",
+	"sched_getaffinity",
+	"sched_getattr",
+	"sched_getparam",
+	"sched_get_priority_max",
+	"sched_get_priority_min",
+	"sched_getscheduler",
+	"sched_rr_get_interval",
+	"sched_setaffinity",
+	"sched_setattr",
+	"sched_setparam",
+	"sched_setscheduler",
+	"sched_yield",
+	"seccomp",
+	"select",
+	"semctl",
+	"semget",
+	"semop",
+	"semtimedop",
+	"send",
+	"sendfile",
+	"sendfile64",
+	"sendmmsg",
+	"sendmsg",
+	"sendto",
+	"setdomainname",
+	"setfsgid",
+	"setfsgid32",
+	"setfsuid",
+	"setfsuid32",
+	"setgid",
+	"setgid32",
+	"setgroups",
+	"setgroups32",
+	"sethostname",
+	"setitimer",
+	"setpgid",
+	"setpriority",
+	"setregid",
+	"setregid32",
+	"setresgid",
+	"setresgid32",
+	"setresuid",
+	"setresuid32",
+	"setreuid",
+	"setreuid32",
+	"setrlimit",
+	"set_robust_list",
+	"setsid",
+	"setsockopt",
+	"set_thread_area",
+	"set_tid_address",
+	"setuid",
+	"setuid32",
+	"setxattr",
+	"shmat",
+	"shmctl",
+	"shmdt",
+	"shmget",
+	"shutdown",
+	"sigaltstack",
+	"signalfd",
+	"signalfd4",
+	"sigreturn",
+	"socket",
+	"socketcall",
+	"socketpair",
+	"splice",
+	"stat",
+	"stat64",
+	"statfs",
+	"statfs64",
+	"symlink",
+	"symlinkat",
+	"sync",
+	"sync_file_range",
+	"syncfs",
+	"sysinfo",
+	"syslog",
+	"tee",
+	"tgkill",
+	"time",
+	"timer_create",
+	"timer_delete",
+	"timerfd_create",
+	"timerfd_gettime",
+	"timerfd_settime",
+	"timer_getoverrun",
+	"timer_gettime",
+	"timer_settime",
+	"times",
+	"tkill",
+	"truncate",
+	"truncate64",
+	"ugetrlimit",
+	"umask",
+	"uname",
+	"unlink",
+	"unlinkat",
+	"utime",
+	"utimensat",
+	"utimes",
+	"vfork",
+	"vhangup",
+	"vmsplice",
+	"wait4",
+	"waitid",
+	"waitpid",
+	"write",
+	"writev",
+
+	// arm specific
+	"arm_fadvise64_64",
+	"arm_sync_file_range",
+	"breakpoint",
+	"cacheflush",
+	"set_tls",
+}
+
+// cloneNamespaceFlags are the clone flags creating new namespaces, which
+// require CAP_SYS_ADMIN and are refused by the default profile.
+const cloneNamespaceFlags = syscall.CLONE_NEWNS | syscall.CLONE_NEWUTS | syscall.CLONE_NEWIPC |
+	syscall.CLONE_NEWUSER | syscall.CLONE_NEWPID | syscall.CLONE_NEWNET
+
+// defaultArchitectures returns the architectures the default profile
+// applies to, so that compatibility mode binaries can run as well.
+func defaultArchitectures() []types.Arch {
+	switch runtime.GOARCH {
+	case "amd64":
+		return []types.Arch{types.ArchX86_64, types.ArchX86, types.ArchX32}
+	case "arm64":
+		return []types.Arch{types.ArchAARCH64, types.ArchARM}
+	default:
+		return nil
+	}
+}
+
+// defaultProfile returns the seccomp profile applied to containers which
+// don't specify their own. It denies the syscalls that are not explicitly
+// allowed with EPERM.
+func defaultProfile() *types.Seccomp {
+	syscalls := make([]*types.Syscall, 0, len(allowedSyscalls)+4)
+	for _, name := range allowedSyscalls {
+		syscalls = append(syscalls, &types.Syscall{
+			Name:   name,
+			Action: types.ActAllow,
+			Args:   []*types.Arg{},
+		})
+	}
+
+	// clone is allowed, as long as it doesn't create new namespaces
+	syscalls = append(syscalls, &types.Syscall{
+		Name:   "clone",
+		Action: types.ActAllow,
+		Args: []*types.Arg{
+			{
+				Index:    0,
+				Value:    cloneNamespaceFlags,
+				ValueTwo: 0,
+				Op:       types.OpMaskedEqual,
+			},
+		},
+	})
+
+	// personality is only allowed for the default and 32 bits execution
+	// domains, and to query the current one.
+	for _, persona := range []uint64{0x0, 0x0008, 0xffffffff} {
+		syscalls = append(syscalls, &types.Syscall{
+			Name:   "personality",
+			Action: types.ActAllow,
+			Args: []*types.Arg{
+				{
+					Index: 0,
+					Value: persona,
+					Op:    types.OpEqualTo,
+				},
+			},
+		})
+	}
+
+	return &types.Seccomp{
+		DefaultAction: types.ActErrno,
+		Architectures: defaultArchitectures(),
+		Syscalls:      syscalls,
+	}
+}
+
+// getDefaultSeccompProfile returns the libcontainer configuration of the
+// default seccomp profile.
+func getDefaultSeccompProfile() *configs.Seccomp {
+	config, err := setupSeccomp(defaultProfile())
+	if err != nil {
+		// The default profile is known to be valid
+		logrus.Errorf("Failed to setup the default seccomp profile: %v", err)
+		return nil
+	}
+	return config
+}
@@ -0,0 +1,78 @@
+// +build linux,cgo
+
+package native
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+func TestLoadSeccompProfile(t *testing.T) {
+	profile := `{
+		"defaultAction": "SCMP_ACT_ERRNO",
+		"architectures": ["SCMP_ARCH_X86_64", "SCMP_ARCH_X86"],
+		"syscalls": [
+			{"name": "read", "action": "SCMP_ACT_ALLOW"},
+			{"name": "personality", "action": "SCMP_ACT_ALLOW", "args": [
+				{"index": 0, "value": 8, "op": "SCMP_CMP_EQ"},
+				{"index": 1, "value": 255, "valueTwo": 4, "op": "SCMP_CMP_MASKED_EQ"}
+			]}
+		]
+	}`
+	config, err := loadSeccompProfile(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.DefaultAction != configs.Errno {
+		t.Fatalf("Expected the default action %v, got %v", configs.Errno, config.DefaultAction)
+	}
+	if len(config.Architectures) != 2 || config.Architectures[0] != "amd64" || config.Architectures[1] != "x86" {
+		t.Fatalf("Unexpected architectures %v", config.Architectures)
+	}
+	if len(config.Syscalls) != 2 {
+		t.Fatalf("Expected 2 syscalls, got %d", len(config.Syscalls))
+	}
+	read := config.Syscalls[0]
+	if read.Name != "read" || read.Action != configs.Allow || len(read.Args) != 0 {
+		t.Fatalf("Unexpected syscall %+v", read)
+	}
+	personality := config.Syscalls[1]
+	if personality.Name != "personality" || personality.Action != configs.Allow || len(personality.Args) != 2 {
+		t.Fatalf("Unexpected syscall %+v", personality)
+	}
+	if arg := personality.Args[0]; arg.Index != 0 || arg.Value != 8 || arg.Op != configs.EqualTo {
+		t.Fatalf("Unexpected argument %+v", arg)
+	}
+	if arg := personality.Args[1]; arg.Index != 1 || arg.Value != 255 || arg.ValueTwo != 4 || arg.Op != configs.MaskEqualTo {
+		t.Fatalf("Unexpected argument %+v", arg)
+	}
+}
+
+func TestLoadSeccompProfileInvalid(t *testing.T) {
+	for _, profile := range []string{
+		`{"defaultAction": "SCMP_ACT_ERRNO"`,
+		`{"defaultAction": "SCMP_ACT_LOG"}`,
+		`{"defaultAction": "SCMP_ACT_ERRNO", "architectures": ["SCMP_ARCH_PPC"]}`,
+		`{"defaultAction": "SCMP_ACT_ERRNO", "syscalls": [{"action": "SCMP_ACT_ALLOW"}]}`,
+		`{"defaultAction": "SCMP_ACT_ERRNO", "syscalls": [{"name": "read", "action": "SCMP_ACT_NOTIFY"}]}`,
+		`{"defaultAction": "SCMP_ACT_ERRNO", "syscalls": [{"name": "read", "action": "SCMP_ACT_ALLOW", "args": [{"index": 0, "op": "SCMP_CMP_IN"}]}]}`,
+	} {
+		if _, err := loadSeccompProfile(profile); err == nil {
+			t.Fatalf("Expected an error for the profile %s", profile)
+		}
+	}
+}
+
+func TestSetupSeccompDisabled(t *testing.T) {
+	for _, profile := range []*types.Seccomp{nil, {}} {
+		config, err := setupSeccomp(profile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config != nil {
+			t.Fatalf("Expected no seccomp configuration for %+v, got %+v", profile, config)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+// +build windows
+
+package windows
+
+import (
+	"github.com/docker/docker/daemon/execdriver"
+)
+
+// Restore implements the exec driver Driver interface.
+func (d *Driver) Restore(c *execdriver.Command, pipes *execdriver.Pipes, hooks execdriver.Hooks) (execdriver.ExitStatus, error) {
+	// Containers are not kept running while the daemon is down on Windows.
+	return execdriver.ExitStatus{ExitCode: -1}, execdriver.ErrNotRunning
+}
@@ -0,0 +1,14 @@
+// +build windows
+
+package windows
+
+import (
+	"github.com/docker/docker/daemon/execdriver"
+)
+
+// Update implements the exec driver Driver interface.
+func (d *Driver) Update(c *execdriver.Command) error {
+	// Updating resources isn't supported on Windows, but the hostconfig
+	// is still updated so the new values are used on the next start.
+	return nil
+}
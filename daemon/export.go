@@ -2,6 +2,8 @@ package daemon
 
 import (
 	"io"
+	"path/filepath"
+	"strings"
 
 	derr "github.com/docker/docker/errors"
 	"github.com/docker/docker/pkg/archive"
@@ -34,11 +36,23 @@ func (daemon *Daemon) containerExport(container *Container) (archive.Archive, er
 		return nil, err
 	}
 
+	// The mount points of the tmpfs mounts are left out of the export.
+	mountpoints, err := daemon.tmpfsMountpoints(container)
+	if err != nil {
+		daemon.Unmount(container)
+		return nil, err
+	}
+	var excludes []string
+	for _, m := range mountpoints {
+		excludes = append(excludes, strings.TrimPrefix(filepath.Clean(m), "/"))
+	}
+
 	uidMaps, gidMaps := daemon.GetUIDGIDMaps()
 	archive, err := archive.TarWithOptions(container.basefs, &archive.TarOptions{
-		Compression: archive.Uncompressed,
-		UIDMaps:     uidMaps,
-		GIDMaps:     gidMaps,
+		Compression:     archive.Uncompressed,
+		ExcludePatterns: excludes,
+		UIDMaps:         uidMaps,
+		GIDMaps:         gidMaps,
 	})
 	if err != nil {
 		daemon.Unmount(container)
@@ -0,0 +1,339 @@
+package daemon
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/runconfig"
+)
+
+const (
+	// Longest healthcheck probe output message to store. Longer messages will be truncated.
+	maxOutputLen = 4096
+
+	// Default interval between probe runs (from the end of the first to the start of the second).
+	// Also the time before the first probe.
+	defaultProbeInterval = 30 * time.Second
+
+	// The maximum length of time a single probe run should take. If the probe takes longer
+	// than this, the check is considered to have failed.
+	defaultProbeTimeout = 30 * time.Second
+
+	// Default number of consecutive failures of the health check
+	// for the container to be considered unhealthy.
+	defaultProbeRetries = 3
+
+	// Maximum number of entries to record
+	maxLogEntries = 5
+)
+
+const (
+	// Exit status codes that can be returned by the probe command.
+
+	exitStatusHealthy   = 0 // Container is healthy
+	exitStatusUnhealthy = 1 // Container is unhealthy
+)
+
+// Health holds the current health state of a container, along with the
+// channel used to stop its monitor.
+type Health struct {
+	types.Health
+	stop chan struct{} // Closed to stop the monitor
+}
+
+// String returns a human-readable description of the health-check state
+func (s *Health) String() string {
+	if s.stop == nil {
+		return "no healthcheck"
+	}
+	switch s.Status {
+	case types.Starting:
+		return "health: starting"
+	default: // Healthy and Unhealthy are clear on their own
+		return s.Status
+	}
+}
+
+// openMonitorChannel creates and returns a new monitor channel. If there already is one,
+// it returns nil.
+func (s *Health) openMonitorChannel() chan struct{} {
+	if s.stop != nil {
+		logrus.Debugf("openMonitorChannel: monitor already open")
+		return nil
+	}
+	logrus.Debugf("openMonitorChannel")
+	s.stop = make(chan struct{})
+	return s.stop
+}
+
+// closeMonitorChannel closes any existing monitor channel.
+func (s *Health) closeMonitorChannel() {
+	if s.stop != nil {
+		logrus.Debugf("closeMonitorChannel: stopping the health monitor")
+		// The monitor checks this channel while holding the container
+		// lock, so no further updates are made to c.State.Health once
+		// it is closed.
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+// createProbe creates the exec instance running the healthcheck test of the
+// container.
+func (daemon *Daemon) createProbe(c *Container, config *runconfig.HealthConfig) (string, error) {
+	cmdSlice := config.Test[1:]
+	if config.Test[0] == "CMD-SHELL" {
+		cmdSlice = append(getShell(c.Config), cmdSlice...)
+	}
+
+	return daemon.ContainerExecCreate(&runconfig.ExecConfig{
+		Container:    c.ID,
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          cmdSlice,
+	})
+}
+
+// runProbe runs the exec instance of the healthcheck test of the container,
+// and returns its result.
+func (daemon *Daemon) runProbe(c *Container, execID string) (*types.HealthcheckResult, error) {
+	output := &limitedBuffer{}
+	if err := daemon.ContainerExecStart(execID, nil, output, output); err != nil {
+		return nil, err
+	}
+	ec := daemon.execCommands.Get(execID)
+	if ec == nil {
+		return nil, fmt.Errorf("Healthcheck for container %s has no exec instance", c.ID)
+	}
+	ec.Lock()
+	exitCode := ec.ExitCode
+	ec.Unlock()
+	// Note: Go's json package will handle invalid UTF-8 for us
+	return &types.HealthcheckResult{
+		End:      time.Now(),
+		ExitCode: exitCode,
+		Output:   output.String(),
+	}, nil
+}
+
+// killProbe kills the process of the exec instance of a healthcheck test, if
+// it is still running.
+func (daemon *Daemon) killProbe(execID string) error {
+	ec := daemon.execCommands.Get(execID)
+	if ec == nil {
+		return nil
+	}
+	ec.Lock()
+	pid, running := ec.Pid, ec.Running
+	ec.Unlock()
+	if !running || pid == 0 {
+		return nil
+	}
+	return killExecProcess(pid)
+}
+
+// handleProbeResult updates the container's health state with the result
+// of a probe. The event is logged when the status changes.
+func (daemon *Daemon) handleProbeResult(c *Container, result *types.HealthcheckResult, stop chan struct{}) {
+	c.Lock()
+	defer c.Unlock()
+
+	// probe may have been cancelled while waiting on lock. Ignore result then
+	select {
+	case <-stop:
+		return
+	default:
+	}
+
+	retries := c.Config.Healthcheck.Retries
+	if retries <= 0 {
+		retries = defaultProbeRetries
+	}
+
+	h := c.State.Health
+	oldStatus := h.Status
+
+	if len(h.Log) >= maxLogEntries {
+		h.Log = append(h.Log[len(h.Log)+1-maxLogEntries:], result)
+	} else {
+		h.Log = append(h.Log, result)
+	}
+
+	if result.ExitCode == exitStatusHealthy {
+		h.FailingStreak = 0
+		h.Status = types.Healthy
+	} else {
+		// Failure (including invalid exit code)
+		h.FailingStreak++
+		if h.FailingStreak >= retries {
+			h.Status = types.Unhealthy
+		}
+		// Else we're starting or healthy. Stay in that state.
+	}
+
+	if oldStatus != h.Status {
+		daemon.LogContainerEvent(c, "health_status: "+h.Status)
+	}
+}
+
+// monitor runs the probe periodically until stop is closed.
+func (daemon *Daemon) monitor(c *Container, stop chan struct{}) {
+	config := c.Config.Healthcheck
+	probeInterval := timeoutWithDefault(config.Interval, defaultProbeInterval)
+	probeTimeout := timeoutWithDefault(config.Timeout, defaultProbeTimeout)
+	for {
+		select {
+		case <-stop:
+			logrus.Debugf("Stop healthcheck monitoring for container %s (received while idle)", c.ID)
+			return
+		case <-time.After(probeInterval):
+			logrus.Debugf("Running health check for container %s ...", c.ID)
+			startTime := time.Now()
+			results := make(chan *types.HealthcheckResult, 1)
+			execID, err := daemon.createProbe(c, config)
+			go func(err error) {
+				var result *types.HealthcheckResult
+				if err == nil {
+					result, err = daemon.runProbe(c, execID)
+				}
+				if err != nil {
+					logrus.Warnf("Health check for container %s error: %v", c.ID, err)
+					result = &types.HealthcheckResult{
+						ExitCode: -1,
+						Output:   err.Error(),
+						End:      time.Now(),
+					}
+				}
+				result.Start = startTime
+				logrus.Debugf("Health check for container %s done (exitCode=%d)", c.ID, result.ExitCode)
+				results <- result
+			}(err)
+			select {
+			case <-stop:
+				logrus.Debugf("Stop healthcheck monitoring for container %s (received while probing)", c.ID)
+				return
+			case result := <-results:
+				daemon.handleProbeResult(c, result, stop)
+			case <-time.After(probeTimeout):
+				logrus.Debugf("Health check for container %s taking too long", c.ID)
+				daemon.handleProbeResult(c, &types.HealthcheckResult{
+					ExitCode: -1,
+					Output:   fmt.Sprintf("Health check exceeded timeout (%v)", probeTimeout),
+					Start:    startTime,
+					End:      time.Now(),
+				}, stop)
+				// Kill the probe so that hung probes don't pile up, and
+				// go on with the next interval without waiting for it.
+				if err := daemon.killProbe(execID); err != nil {
+					logrus.Warnf("Error killing the health check of container %s: %v", c.ID, err)
+				}
+			}
+		}
+	}
+}
+
+// initHealthMonitor starts the health monitor of the container, if it has a
+// healthcheck. It is called by the container monitor each time the process
+// of the container starts, so it first stops any previous health monitor.
+func (daemon *Daemon) initHealthMonitor(c *Container) {
+	// This is needed in case we're auto-restarting
+	daemon.stopHealthchecks(c)
+
+	if c.Config.Healthcheck == nil {
+		return
+	}
+
+	test := c.Config.Healthcheck.Test
+	if len(test) == 0 || test[0] == "NONE" {
+		return
+	}
+
+	h := &Health{}
+	h.Status = types.Starting
+	c.State.Health = h
+
+	stop := h.openMonitorChannel()
+	if stop != nil {
+		go daemon.monitor(c, stop)
+	}
+}
+
+// stopHealthchecks stops the health monitor of the container, if any. The
+// last known health state is kept so that it can still be inspected.
+func (daemon *Daemon) stopHealthchecks(c *Container) {
+	h := c.State.Health
+	if h != nil {
+		h.closeMonitorChannel()
+	}
+}
+
+// timeoutWithDefault returns the configured duration, or the default if
+// it is not set.
+func timeoutWithDefault(configuredValue time.Duration, defaultValue time.Duration) time.Duration {
+	if configuredValue == 0 {
+		return defaultValue
+	}
+	return configuredValue
+}
+
+// getShell returns the shell used to run CMD-SHELL healthchecks, which is
+// the shell set with the SHELL instruction of the image, if any.
+func getShell(config *runconfig.Config) []string {
+	if config != nil && config.Shell.Len() > 0 {
+		return config.Shell.Slice()
+	}
+	if runtime.GOOS != "windows" {
+		return []string{"/bin/sh", "-c"}
+	}
+	return []string{"cmd", "/S", "/C"}
+}
+
+// limitedBuffer is a thread-safe buffer that discards everything written
+// to it after the first maxOutputLen bytes.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	mu        sync.Mutex
+	truncated bool // indicates that data has been lost
+}
+
+// Write appends data to the buffer, up to maxOutputLen bytes.
+func (b *limitedBuffer) Write(data []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bufLen := b.buf.Len()
+	dataLen := len(data)
+	keep := minInt(maxOutputLen-bufLen, dataLen)
+	if keep > 0 {
+		b.buf.Write(data[:keep])
+	}
+	if keep < dataLen {
+		b.truncated = true
+	}
+	return dataLen, nil
+}
+
+// String returns the contents of the buffer, with "..." appended if it overflowed.
+func (b *limitedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	out := b.buf.String()
+	if b.truncated {
+		out = out + "..."
+	}
+	return strings.TrimSpace(out)
+}
+
+func minInt(x, y int) int {
+	if x < y {
+		return x
+	}
+	return y
+}
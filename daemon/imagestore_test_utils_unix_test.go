@@ -0,0 +1,112 @@
+// +build !windows
+
+package daemon
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/docker/docker/daemon/graphdriver"
+	"github.com/docker/docker/daemon/graphdriver/vfs"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/archive"
+)
+
+func init() {
+	graphdriver.ApplyUncompressedLayer = archive.UnpackLayer
+	vfs.CopyWithTar = archive.CopyWithTar
+}
+
+// newImageStoreTestDaemon returns a daemon with only a layer store and an
+// image store, backed by the vfs driver in a temporary directory, and the
+// func removing them.
+func newImageStoreTestDaemon(t *testing.T) (*Daemon, func()) {
+	root, err := ioutil.TempDir("", "docker-imagestore-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	driver, err := graphdriver.GetDriver("vfs", filepath.Join(root, "vfs"), nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fms, err := layer.NewFSMetadataStore(filepath.Join(root, "layers"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ls, err := layer.NewStore(fms, driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ifs, err := image.NewFSStoreBackend(filepath.Join(root, "images"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	is, err := image.NewImageStore(ifs, ls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Daemon{layerStore: ls, imageStore: is}, func() {
+		driver.Cleanup()
+		os.RemoveAll(root)
+	}
+}
+
+// createTestImage creates an image with a layer for each set of
+// changes made by the funcs on top of parent.
+func createTestImage(t *testing.T, daemon *Daemon, parent *image.Image, changes ...func(root string) error) *image.Image {
+	rootFS := image.NewRootFS()
+	var history []image.History
+	if parent != nil {
+		rootFS.DiffIDs = append(rootFS.DiffIDs, parent.RootFS.DiffIDs...)
+		history = append(history, parent.History...)
+	}
+	for i, change := range changes {
+		name := "imagestore-test"
+		rw, err := daemon.layerStore.Mount(name, rootFS.ChainID(), "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path, err := rw.Path()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := change(path); err != nil {
+			t.Fatal(err)
+		}
+		ts, err := rw.TarStream()
+		if err != nil {
+			t.Fatal(err)
+		}
+		l, err := daemon.layerStore.Register(ts, rootFS.ChainID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		rootFS.Append(l.DiffID())
+		history = append(history, image.History{CreatedBy: "change " + strconv.Itoa(i)})
+		daemon.releaseSquashMount(name)
+	}
+	config, err := json.Marshal(&image.Image{RootFS: rootFS, History: history})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := daemon.imageStore.Create(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := daemon.imageStore.Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func writeTestFile(name, content string) func(string) error {
+	return func(root string) error {
+		return ioutil.WriteFile(filepath.Join(root, name), []byte(content), 0644)
+	}
+}
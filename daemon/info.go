@@ -59,6 +59,12 @@ func (daemon *Daemon) SystemInfo() (*types.Info, error) {
 
 	sysInfo := sysinfo.New(true)
 
+	// The labels and the cluster settings can be changed by a configuration reload
+	config := daemon.config()
+	config.reloadLock.Lock()
+	labels, clusterStore, clusterAdvertise := config.Labels, config.ClusterStore, config.ClusterAdvertise
+	config.reloadLock.Unlock()
+
 	v := &types.Info{
 		ID:                 daemon.ID,
 		Containers:         len(daemon.List()),
@@ -86,12 +92,12 @@ func (daemon *Daemon) SystemInfo() (*types.Info, error) {
 		InitPath:           initPath,
 		NCPU:               runtime.NumCPU(),
 		MemTotal:           meminfo.MemTotal,
-		DockerRootDir:      daemon.config().Root,
-		Labels:             daemon.config().Labels,
+		DockerRootDir:      config.Root,
+		Labels:             labels,
 		ExperimentalBuild:  utils.ExperimentalBuild(),
 		ServerVersion:      dockerversion.Version,
-		ClusterStore:       daemon.config().ClusterStore,
-		ClusterAdvertise:   daemon.config().ClusterAdvertise,
+		ClusterStore:       clusterStore,
+		ClusterAdvertise:   clusterAdvertise,
 		HTTPProxy:          getProxyEnv("http_proxy"),
 		HTTPSProxy:         getProxyEnv("https_proxy"),
 		NoProxy:            getProxyEnv("no_proxy"),
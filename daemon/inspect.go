@@ -130,21 +130,28 @@ func (daemon *Daemon) getInspectData(container *Container, size bool) (*types.Co
 		FinishedAt: container.State.FinishedAt.Format(time.RFC3339Nano),
 	}
 
+	if container.State.Health != nil {
+		health := container.State.Health.Health
+		health.Log = append([]*types.HealthcheckResult(nil), health.Log...)
+		containerState.Health = &health
+	}
+
 	contJSONBase := &types.ContainerJSONBase{
-		ID:           container.ID,
-		Created:      container.Created.Format(time.RFC3339Nano),
-		Path:         container.Path,
-		Args:         container.Args,
-		State:        containerState,
-		Image:        container.ImageID.String(),
-		LogPath:      container.LogPath,
-		Name:         container.Name,
-		RestartCount: container.RestartCount,
-		Driver:       container.Driver,
-		MountLabel:   container.MountLabel,
-		ProcessLabel: container.ProcessLabel,
-		ExecIDs:      container.getExecIDs(),
-		HostConfig:   &hostConfig,
+		ID:                 container.ID,
+		Created:            container.Created.Format(time.RFC3339Nano),
+		Path:               container.Path,
+		Args:               container.Args,
+		State:              containerState,
+		Image:              container.ImageID.String(),
+		LogPath:            container.LogPath,
+		LogDroppedMessages: container.logDroppedMessages(),
+		Name:               container.Name,
+		RestartCount:       container.RestartCount,
+		Driver:             container.Driver,
+		MountLabel:         container.MountLabel,
+		ProcessLabel:       container.ProcessLabel,
+		ExecIDs:            container.getExecIDs(),
+		HostConfig:         &hostConfig,
 	}
 
 	var (
@@ -64,7 +64,10 @@ func (daemon *Daemon) killWithSignal(container *Container, sig int) error {
 		return err
 	}
 
-	daemon.LogContainerEvent(container, "kill")
+	attributes := map[string]string{
+		"signal": fmt.Sprintf("%d", sig),
+	}
+	daemon.LogContainerEventWithAttributes(container, "kill", attributes)
 	return nil
 }
 
@@ -156,6 +156,14 @@ func (daemon *Daemon) foldFilter(config *ContainersConfig) (*listContext, error)
 		}
 	}
 
+	if i, ok := psFilters["health"]; ok {
+		for _, value := range i {
+			if !isValidHealthString(value) {
+				return nil, errors.New("Unrecognised filter value for health")
+			}
+		}
+	}
+
 	var beforeContFilter, sinceContFilter *Container
 	if i, ok := psFilters["before"]; ok {
 		for _, value := range i {
@@ -296,6 +304,11 @@ func includeContainerInList(container *Container, ctx *listContext) iterationAct
 		return excludeContainer
 	}
 
+	// Do not include container if its health doesn't match the filter
+	if !ctx.filters.Match("health", container.State.healthString()) {
+		return excludeContainer
+	}
+
 	if ctx.ancestorFilter {
 		if len(ctx.images) == 0 {
 			return excludeContainer
@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/plugins/logdriver"
+)
+
+// pluginAdapter is a Logger sending the messages of a container to a log
+// driver plugin.
+type pluginAdapter struct {
+	driverName string
+	plugin     logPlugin
+	streamPath string
+	logInfo    Context
+	capability Capability
+
+	// mu serializes the writes of the messages to the stream
+	mu     sync.Mutex
+	stream io.WriteCloser
+	enc    *logdriver.LogEntryEncoder
+}
+
+func (a *pluginAdapter) Log(msg *Message) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.enc.Encode(&logdriver.LogEntry{
+		Source:   msg.Source,
+		TimeNano: msg.Timestamp.UnixNano(),
+		Line:     msg.Line,
+		Partial:  msg.Partial,
+	})
+}
+
+func (a *pluginAdapter) Name() string {
+	return a.driverName
+}
+
+// Close closes the stream, so that the plugin reads the end of the stream
+// once it read the last messages, and tells the plugin to stop logging.
+func (a *pluginAdapter) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.stream.Close(); err != nil {
+		logrus.Errorf("Error closing the stream of log driver plugin %s: %v", a.driverName, err)
+	}
+	err := a.plugin.StopLogging(a.streamPath)
+	if err := os.Remove(a.streamPath); err != nil && !os.IsNotExist(err) {
+		logrus.Errorf("Error removing the stream of log driver plugin %s: %v", a.driverName, err)
+	}
+	return err
+}
+
+// pluginAdapterWithRead is a pluginAdapter for the plugins which can read
+// the logs back.
+type pluginAdapterWithRead struct {
+	*pluginAdapter
+}
+
+func (a *pluginAdapterWithRead) ReadLogs(config ReadConfig) *LogWatcher {
+	watcher := NewLogWatcher()
+
+	go func() {
+		defer close(watcher.Msg)
+
+		stream, err := a.plugin.ReadLogs(a.logInfo, config)
+		if err != nil {
+			watcher.Err <- err
+			return
+		}
+
+		// Closing the stream interrupts the read of the next entry.
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-watcher.WatchClose():
+			case <-done:
+			}
+			stream.Close()
+		}()
+
+		dec := logdriver.NewLogEntryDecoder(stream)
+		for {
+			var entry logdriver.LogEntry
+			if err := dec.Decode(&entry); err != nil {
+				if err != io.EOF {
+					select {
+					case <-watcher.WatchClose():
+					default:
+						watcher.Err <- err
+					}
+				}
+				return
+			}
+
+			msg := &Message{
+				ContainerID: a.logInfo.ContainerID,
+				Line:        entry.Line,
+				Source:      entry.Source,
+				Timestamp:   time.Unix(0, entry.TimeNano).UTC(),
+				Partial:     entry.Partial,
+			}
+			if !msg.Partial {
+				msg.Line = append(msg.Line, '\n')
+			}
+			select {
+			case watcher.Msg <- msg:
+			case <-watcher.WatchClose():
+				return
+			}
+		}
+	}()
+
+	return watcher
+}
@@ -53,6 +53,7 @@ type logStream struct {
 	lock          sync.RWMutex
 	closed        bool
 	sequenceToken *string
+	partial       logger.PartialJoiner
 }
 
 type api interface {
@@ -161,8 +162,12 @@ func (l *logStream) Name() string {
 	return name
 }
 
-// Log submits messages for logging by an instance of the awslogs logging driver
+// Log submits messages for logging by an instance of the awslogs logging
+// driver, once the long lines split in partial messages are joined.
 func (l *logStream) Log(msg *logger.Message) error {
+	if msg = l.partial.Join(msg); msg == nil {
+		return nil
+	}
 	l.lock.RLock()
 	defer l.lock.RUnlock()
 	if !l.closed {
@@ -176,6 +181,9 @@ func (l *logStream) Close() error {
 	l.lock.Lock()
 	defer l.lock.Unlock()
 	if !l.closed {
+		for _, msg := range l.partial.Flush() {
+			l.messages <- msg
+		}
 		close(l.messages)
 	}
 	l.closed = true
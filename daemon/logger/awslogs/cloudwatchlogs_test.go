@@ -361,6 +361,57 @@ func TestCollectBatchSimple(t *testing.T) {
 	}
 }
 
+func TestCollectBatchPartial(t *testing.T) {
+	mockClient := newMockClient()
+	stream := &logStream{
+		client:        mockClient,
+		logGroupName:  groupName,
+		logStreamName: streamName,
+		sequenceToken: aws.String(sequenceToken),
+		messages:      make(chan *logger.Message),
+	}
+	mockClient.putLogEventsResult <- &putLogEventsResult{
+		successResult: &cloudwatchlogs.PutLogEventsOutput{
+			NextSequenceToken: aws.String(nextSequenceToken),
+		},
+	}
+	ticks := make(chan time.Time)
+	newTicker = func(_ time.Duration) *time.Ticker {
+		return &time.Ticker{
+			C: ticks,
+		}
+	}
+
+	go stream.collectBatch()
+
+	// the partial messages of a long line are sent as a single event
+	stream.Log(&logger.Message{
+		Line:      []byte(logline),
+		Source:    "stdout",
+		Timestamp: time.Time{},
+		Partial:   true,
+	})
+	stream.Log(&logger.Message{
+		Line:      []byte(logline),
+		Source:    "stdout",
+		Timestamp: time.Time{},
+	})
+
+	ticks <- time.Time{}
+	stream.Close()
+
+	argument := <-mockClient.putLogEventsArgument
+	if argument == nil {
+		t.Fatal("Expected non-nil PutLogEventsInput")
+	}
+	if len(argument.LogEvents) != 1 {
+		t.Fatalf("Expected LogEvents to contain 1 element, but contains %d", len(argument.LogEvents))
+	}
+	if *argument.LogEvents[0].Message != logline+logline {
+		t.Errorf("Expected message to be %s but was %s", logline+logline, *argument.LogEvents[0].Message)
+	}
+}
+
 func TestCollectBatchTicker(t *testing.T) {
 	mockClient := newMockClient()
 	stream := &logStream{
@@ -10,6 +10,10 @@ import (
 	"github.com/Sirupsen/logrus"
 )
 
+// maxLineSize is the maximum size of a message. The longer lines are split in
+// several messages, all but the last one being partial.
+const maxLineSize = 16 * 1024
+
 // Copier can copy logs from specified sources to Logger and attach
 // ContainerID and Timestamp.
 // Writes are concurrent, so you need implement some sync in your logger
@@ -41,21 +45,25 @@ func (c *Copier) Run() {
 
 func (c *Copier) copySrc(name string, src io.Reader) {
 	defer c.copyJobs.Done()
-	reader := bufio.NewReader(src)
+	reader := bufio.NewReaderSize(src, maxLineSize)
 
 	for {
-		line, err := reader.ReadBytes('\n')
+		line, err := reader.ReadSlice('\n')
+		// the buffer is full before the end of the line
+		partial := err == bufio.ErrBufferFull
 		line = bytes.TrimSuffix(line, []byte{'\n'})
 
-		// ReadBytes can return full or partial output even when it failed.
+		// ReadSlice can return full or partial output even when it failed.
 		// e.g. it can return a full entry and EOF.
 		if err == nil || len(line) > 0 {
-			if logErr := c.dst.Log(&Message{ContainerID: c.cid, Line: line, Source: name, Timestamp: time.Now().UTC()}); logErr != nil {
+			// the line is only valid until the next read
+			line = append([]byte(nil), line...)
+			if logErr := c.dst.Log(&Message{ContainerID: c.cid, Line: line, Source: name, Timestamp: time.Now().UTC(), Partial: partial}); logErr != nil {
 				logrus.Errorf("Failed to log msg %q for logger %s: %s", line, c.dst.Name(), logErr)
 			}
 		}
 
-		if err != nil {
+		if err != nil && !partial {
 			if err != io.EOF {
 				logrus.Errorf("Error scanning log stream: %s", err)
 			}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"strings"
 	"testing"
 	"time"
 )
@@ -94,3 +95,35 @@ func TestCopier(t *testing.T) {
 		}
 	}
 }
+
+func TestCopierLongLines(t *testing.T) {
+	longLine := strings.Repeat("x", 2*maxLineSize+10)
+	stdout := strings.NewReader(longLine + "\nshort\nend")
+
+	var jsonBuf bytes.Buffer
+	c := NewCopier("cid", map[string]io.Reader{"stdout": stdout}, &TestLoggerJSON{Encoder: json.NewEncoder(&jsonBuf)})
+	c.Run()
+	c.Wait()
+
+	expected := []Message{
+		{Line: []byte(longLine[:maxLineSize]), Partial: true},
+		{Line: []byte(longLine[maxLineSize : 2*maxLineSize]), Partial: true},
+		{Line: []byte(longLine[2*maxLineSize:])},
+		{Line: []byte("short")},
+		{Line: []byte("end")},
+	}
+	dec := json.NewDecoder(&jsonBuf)
+	for _, e := range expected {
+		var msg Message
+		if err := dec.Decode(&msg); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(msg.Line, e.Line) || msg.Partial != e.Partial {
+			t.Fatalf("Expected the message %.20q (%d bytes, partial %v), got %.20q (%d bytes, partial %v)", e.Line, len(e.Line), e.Partial, msg.Line, len(msg.Line), msg.Partial)
+		}
+	}
+	var msg Message
+	if err := dec.Decode(&msg); err != io.EOF {
+		t.Fatalf("Expected no more messages, got %q", msg.Line)
+	}
+}
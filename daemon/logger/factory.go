@@ -3,6 +3,35 @@ package logger
 import (
 	"fmt"
 	"sync"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/units"
+)
+
+// The log options supported for all the logging drivers.
+const (
+	// ModeOpt is the option setting the mode of delivery of the messages
+	// to the logging driver, ModeBlocking or ModeNonBlocking.
+	ModeOpt = "mode"
+	// MaxBufferSizeOpt is the option setting the size of the buffer of
+	// the messages in the non-blocking mode, such as "4m".
+	MaxBufferSizeOpt = "max-buffer-size"
+	// MultilinePatternOpt is the option setting the regular expression
+	// matching the first line of the events written on several lines,
+	// such as "^[^\s]", which are sent as a single message.
+	MultilinePatternOpt = "multiline-pattern"
+)
+
+// The modes of delivery of the messages to the logging driver.
+const (
+	// ModeBlocking delivers the messages directly to the logging driver,
+	// so that a container writing its output blocks if the driver is
+	// slow. It is the default mode.
+	ModeBlocking = "blocking"
+	// ModeNonBlocking buffers the messages in memory, and drops them when
+	// the buffer is full, so that a container is never blocked by its
+	// logging driver.
+	ModeNonBlocking = "non-blocking"
 )
 
 // Creator builds a logging driver instance with given context.
@@ -16,6 +45,11 @@ type logdriverFactory struct {
 	registry     map[string]Creator
 	optValidator map[string]LogOptValidator
 	m            sync.Mutex
+
+	// the options supported for all the drivers, which are not passed to
+	// the validators of the drivers, and their validators
+	globalOpts       map[string]bool
+	globalValidators []LogOptValidator
 }
 
 func (lf *logdriverFactory) register(name string, c Creator) error {
@@ -40,13 +74,35 @@ func (lf *logdriverFactory) registerLogOptValidator(name string, l LogOptValidat
 	return nil
 }
 
-func (lf *logdriverFactory) get(name string) (Creator, error) {
+func (lf *logdriverFactory) registerGlobalLogOptValidator(opts []string, l LogOptValidator) error {
 	lf.m.Lock()
 	defer lf.m.Unlock()
 
+	for _, opt := range opts {
+		if lf.globalOpts[opt] {
+			return fmt.Errorf("logger: log option '%s' is already registered", opt)
+		}
+	}
+	for _, opt := range opts {
+		lf.globalOpts[opt] = true
+	}
+	lf.globalValidators = append(lf.globalValidators, l)
+	return nil
+}
+
+func (lf *logdriverFactory) get(name string) (Creator, error) {
+	lf.m.Lock()
 	c, ok := lf.registry[name]
-	if !ok {
-		return c, fmt.Errorf("logger: no log driver named '%s' is registered", name)
+	lf.m.Unlock()
+	if ok {
+		return c, nil
+	}
+
+	// The driver can be a log driver plugin.
+	c, err := getPlugin(name)
+	if err != nil {
+		logrus.Debugf("logger: error looking up log driver plugin %s: %v", name, err)
+		return nil, fmt.Errorf("logger: no log driver named '%s' is registered", name)
 	}
 	return c, nil
 }
@@ -59,7 +115,12 @@ func (lf *logdriverFactory) getLogOptValidator(name string) LogOptValidator {
 	return c
 }
 
-var factory = &logdriverFactory{registry: make(map[string]Creator), optValidator: make(map[string]LogOptValidator)} // global factory instance
+var factory = &logdriverFactory{
+	registry:         make(map[string]Creator),
+	optValidator:     make(map[string]LogOptValidator),
+	globalOpts:       map[string]bool{ModeOpt: true, MaxBufferSizeOpt: true, MultilinePatternOpt: true},
+	globalValidators: []LogOptValidator{validateModeOpts, validateMultilineOpts},
+} // global factory instance
 
 // RegisterLogDriver registers the given logging driver builder with given logging
 // driver name.
@@ -73,17 +134,65 @@ func RegisterLogOptValidator(name string, l LogOptValidator) error {
 	return factory.registerLogOptValidator(name, l)
 }
 
-// GetLogDriver provides the logging driver builder for a logging driver name.
+// RegisterGlobalLogOptValidator registers the validator of the logging
+// options opts, which are supported for all the logging drivers rather than
+// by a specific one. The validator is passed all the options, and the options
+// opts are not passed to the validator of the logging driver.
+func RegisterGlobalLogOptValidator(opts []string, l LogOptValidator) error {
+	return factory.registerGlobalLogOptValidator(opts, l)
+}
+
+// GetLogDriver provides the logging driver builder for a logging driver name,
+// which is either a logging driver compiled into the daemon, or a LogDriver
+// plugin.
 func GetLogDriver(name string) (Creator, error) {
 	return factory.get(name)
 }
 
 // ValidateLogOpts checks the options for the given log driver. The
-// options supported are specific to the LogDriver implementation.
+// options supported are specific to the LogDriver implementation, except for
+// the options registered with RegisterGlobalLogOptValidator, such as ModeOpt
+// and MultilinePatternOpt, which are supported for all the drivers.
 func ValidateLogOpts(name string, cfg map[string]string) error {
+	factory.m.Lock()
+	globalOpts := factory.globalOpts
+	globalValidators := factory.globalValidators
+	factory.m.Unlock()
+
+	for _, v := range globalValidators {
+		if err := v(cfg); err != nil {
+			return err
+		}
+	}
+
 	l := factory.getLogOptValidator(name)
-	if l != nil {
-		return l(cfg)
+	if l == nil {
+		return nil
+	}
+	driverCfg := make(map[string]string, len(cfg))
+	for k, v := range cfg {
+		if !globalOpts[k] {
+			driverCfg[k] = v
+		}
+	}
+	return l(driverCfg)
+}
+
+// validateModeOpts checks the options of the mode of delivery of the
+// messages.
+func validateModeOpts(cfg map[string]string) error {
+	switch cfg[ModeOpt] {
+	case "", ModeBlocking, ModeNonBlocking:
+	default:
+		return fmt.Errorf("logger: logging mode not supported: %s", cfg[ModeOpt])
+	}
+	if s, ok := cfg[MaxBufferSizeOpt]; ok {
+		if cfg[ModeOpt] != ModeNonBlocking {
+			return fmt.Errorf("logger: %s option is only supported with '%s=%s'", MaxBufferSizeOpt, ModeOpt, ModeNonBlocking)
+		}
+		if _, err := units.RAMInBytes(s); err != nil {
+			return fmt.Errorf("logger: error parsing option %s: %v", MaxBufferSizeOpt, err)
+		}
 	}
 	return nil
 }
@@ -76,6 +76,11 @@ func (f *fluentd) Log(msg *logger.Message) error {
 		"source":         msg.Source,
 		"log":            string(msg.Line),
 	}
+	// the partial messages of a long line are annotated, to be joined by
+	// the fluentd configuration
+	if msg.Partial {
+		data["partial_message"] = "true"
+	}
 	for k, v := range f.extra {
 		data[k] = v
 	}
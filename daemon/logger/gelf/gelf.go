@@ -25,6 +25,7 @@ type gelfLogger struct {
 	ctx      logger.Context
 	hostname string
 	extra    map[string]interface{}
+	partial  logger.PartialJoiner
 }
 
 func init() {
@@ -95,7 +96,16 @@ func New(ctx logger.Context) (logger.Logger, error) {
 	}, nil
 }
 
+// Log sends the message to the GELF endpoint, once the long lines split in
+// partial messages are joined.
 func (s *gelfLogger) Log(msg *logger.Message) error {
+	if msg = s.partial.Join(msg); msg == nil {
+		return nil
+	}
+	return s.send(msg)
+}
+
+func (s *gelfLogger) send(msg *logger.Message) error {
 	// remove trailing and leading whitespace
 	short := bytes.TrimSpace([]byte(msg.Line))
 
@@ -120,6 +130,11 @@ func (s *gelfLogger) Log(msg *logger.Message) error {
 }
 
 func (s *gelfLogger) Close() error {
+	for _, msg := range s.partial.Flush() {
+		if err := s.send(msg); err != nil {
+			logrus.Errorf("Failed to log msg %q for logger %s: %s", msg.Line, name, err)
+		}
+	}
 	return s.writer.Close()
 }
 
@@ -74,11 +74,21 @@ func validateLogOpt(cfg map[string]string) error {
 	return nil
 }
 
+// Log sends the message to the journal. The partial messages of a long line
+// have a CONTAINER_PARTIAL_MESSAGE field, so that the line can be joined.
 func (s *journald) Log(msg *logger.Message) error {
+	vars := s.vars
+	if msg.Partial {
+		vars = make(map[string]string, len(s.vars)+1)
+		for k, v := range s.vars {
+			vars[k] = v
+		}
+		vars["CONTAINER_PARTIAL_MESSAGE"] = "true"
+	}
 	if msg.Source == "stderr" {
-		return journal.Send(string(msg.Line), journal.PriErr, s.vars)
+		return journal.Send(string(msg.Line), journal.PriErr, vars)
 	}
-	return journal.Send(string(msg.Line), journal.PriInfo, s.vars)
+	return journal.Send(string(msg.Line), journal.PriInfo, vars)
 }
 
 func (s *journald) Name() string {
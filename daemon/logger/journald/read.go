@@ -51,6 +51,12 @@ package journald
 //	}
 //	return rc;
 //}
+//static int is_partial(sd_journal *j)
+//{
+//	const void *data;
+//	size_t length;
+//	return sd_journal_get_data(j, "CONTAINER_PARTIAL_MESSAGE", &data, &length) == 0;
+//}
 //static int wait_for_data_or_close(sd_journal *j, int pipefd)
 //{
 //	struct pollfd fds[2];
@@ -143,7 +149,13 @@ drain:
 			}
 			// Set up the time and text of the entry.
 			timestamp := time.Unix(int64(stamp)/1000000, (int64(stamp)%1000000)*1000)
-			line := append(C.GoBytes(unsafe.Pointer(msg), C.int(length)), "\n"...)
+			line := C.GoBytes(unsafe.Pointer(msg), C.int(length))
+			// The partial messages of a long line have no trailing
+			// newline, so that the line is output whole.
+			partial := C.is_partial(j) != 0
+			if !partial {
+				line = append(line, "\n"...)
+			}
 			// Recover the stream name by mapping
 			// from the journal priority back to
 			// the stream that we would have
@@ -158,7 +170,7 @@ drain:
 			}
 			// Send the log message.
 			cid := s.vars["CONTAINER_ID_FULL"]
-			logWatcher.Msg <- &logger.Message{ContainerID: cid, Line: line, Source: source, Timestamp: timestamp}
+			logWatcher.Msg <- &logger.Message{ContainerID: cid, Line: line, Source: source, Timestamp: timestamp, Partial: partial}
 		}
 		// If we're at the end of the journal, we're done (for now).
 		if C.sd_journal_next(j) <= 0 {
@@ -86,14 +86,19 @@ func New(ctx logger.Context) (logger.Logger, error) {
 }
 
 // Log converts logger.Message to jsonlog.JSONLog and serializes it to file.
+// The log of a partial message has no trailing newline.
 func (l *JSONFileLogger) Log(msg *logger.Message) error {
 
 	timestamp, err := timeutils.FastMarshalJSON(msg.Timestamp)
 	if err != nil {
 		return err
 	}
+	line := msg.Line
+	if !msg.Partial {
+		line = append(line, '\n')
+	}
 	err = (&jsonlog.JSONLogs{
-		Log:      append(msg.Line, '\n'),
+		Log:      line,
 		Stream:   msg.Source,
 		Created:  timestamp,
 		RawAttrs: l.extra,
@@ -54,6 +54,46 @@ func TestJSONFileLogger(t *testing.T) {
 	}
 }
 
+func TestJSONFileLoggerPartial(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-logger-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	filename := filepath.Join(tmp, "container.log")
+	l, err := New(logger.Context{LogPath: filename})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	if err := l.Log(&logger.Message{Line: []byte("a long"), Source: "stdout", Partial: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Log(&logger.Message{Line: []byte(" line"), Source: "stdout"}); err != nil {
+		t.Fatal(err)
+	}
+	res, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"log":"a long","stream":"stdout","time":"0001-01-01T00:00:00Z"}
+{"log":" line\n","stream":"stdout","time":"0001-01-01T00:00:00Z"}
+`
+	if string(res) != expected {
+		t.Fatalf("Wrong log content: %q, expected %q", res, expected)
+	}
+
+	watcher := l.(logger.LogReader).ReadLogs(logger.ReadConfig{Tail: -1})
+	var partial []bool
+	for msg := range watcher.Msg {
+		partial = append(partial, msg.Partial)
+	}
+	if len(partial) != 2 || !partial[0] || partial[1] {
+		t.Fatalf("Expected only the first message to be partial, got %v", partial)
+	}
+}
+
 func BenchmarkJSONFileLogger(b *testing.B) {
 	cid := "a7317399f3f857173c6179d44823594f8294678dea9999662e5c625b5a1c7657"
 	tmp, err := ioutil.TempDir("", "docker-logger-")
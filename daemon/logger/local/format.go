@@ -0,0 +1,211 @@
+package local
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/docker/docker/daemon/logger"
+)
+
+// The log files and the index files start with a magic number and the version
+// of their format:
+//
+//	magic      [4]byte     logMagic or indexMagic
+//	version    uint8       formatVersion
+//
+// Files of another format version are not read.
+const (
+	fileHeaderSize = 4 + 1
+	formatVersion  = 1
+)
+
+var (
+	logMagic   = []byte("DLOG")
+	indexMagic = []byte("DIDX")
+)
+
+func fileHeader(magic []byte) []byte {
+	return append(append([]byte(nil), magic...), formatVersion)
+}
+
+// checkFileHeader checks the header read from the file at path.
+func checkFileHeader(hdr []byte, magic []byte, path string) error {
+	if !bytes.Equal(hdr[:len(magic)], magic) {
+		return fmt.Errorf("%s is not a log file of the local logging driver", path)
+	}
+	if hdr[len(magic)] != formatVersion {
+		return fmt.Errorf("unsupported format version %d of %s", hdr[len(magic)], path)
+	}
+	return nil
+}
+
+// readLogHeader reads and checks the header of the log file f.
+func readLogHeader(f *os.File) error {
+	var hdr [fileHeaderSize]byte
+	if _, err := f.ReadAt(hdr[:], 0); err != nil {
+		return err
+	}
+	return checkFileHeader(hdr[:], logMagic, f.Name())
+}
+
+// After its header, a log file is a sequence of records, each holding a
+// message:
+//
+//	size       uint32      size of the message
+//	timestamp  int64       nanoseconds since the epoch
+//	flags      uint8       flagPartial if the message is partial
+//	srclen     uint8       size of the source
+//	source     [srclen]byte
+//	line       []byte      rest of the message, without the trailing newline
+//
+// The integers are big-endian.
+const (
+	recordHeaderSize = 4
+	minMessageSize   = 8 + 1 + 1
+	maxSourceSize    = 255
+
+	flagPartial = 1
+)
+
+var errCorruptedRecord = errors.New("corrupted log record")
+
+// appendRecord appends the record of the message msg to buf.
+func appendRecord(buf []byte, msg *logger.Message) []byte {
+	source := msg.Source
+	if len(source) > maxSourceSize {
+		source = source[:maxSourceSize]
+	}
+	var hdr [recordHeaderSize + 8]byte
+	binary.BigEndian.PutUint32(hdr[:], uint32(minMessageSize+len(source)+len(msg.Line)))
+	binary.BigEndian.PutUint64(hdr[recordHeaderSize:], uint64(msg.Timestamp.UnixNano()))
+	buf = append(buf, hdr[:]...)
+	var flags byte
+	if msg.Partial {
+		flags |= flagPartial
+	}
+	buf = append(buf, flags, byte(len(source)))
+	buf = append(buf, source...)
+	return append(buf, msg.Line...)
+}
+
+// readRecord reads a record from r. The line of the message ends with a newline
+// unless the message is partial. It returns the number of bytes read, and
+// io.ErrUnexpectedEOF if r ends with an incomplete record, which is the case
+// while the record is written.
+func readRecord(r io.Reader) (*logger.Message, int, error) {
+	var hdr [recordHeaderSize]byte
+	if n, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, n, err
+	}
+	size := binary.BigEndian.Uint32(hdr[:])
+	if size < minMessageSize {
+		return nil, recordHeaderSize, errCorruptedRecord
+	}
+	buf := make([]byte, size)
+	if n, err := io.ReadFull(r, buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, recordHeaderSize + n, err
+	}
+
+	sourceSize := int(buf[9])
+	if minMessageSize+sourceSize > len(buf) {
+		return nil, recordHeaderSize + len(buf), errCorruptedRecord
+	}
+	msg := &logger.Message{
+		Timestamp: time.Unix(0, int64(binary.BigEndian.Uint64(buf))).UTC(),
+		Source:    string(buf[minMessageSize : minMessageSize+sourceSize]),
+		Line:      buf[minMessageSize+sourceSize:],
+		Partial:   buf[8]&flagPartial != 0,
+	}
+	if !msg.Partial {
+		msg.Line = append(msg.Line, '\n')
+	}
+	return msg, recordHeaderSize + len(buf), nil
+}
+
+// After its file header, an index file has a header, followed by the entries of
+// the index of a log file, which are written every indexInterval bytes of
+// records:
+//
+//	compressed uint8       1 if the log file is compressed, 0 otherwise
+//	entries    []struct {
+//		timestamp int64    timestamp of the record at offset
+//		count     int64    number of records before the record at offset
+//		offset    int64    offset of the record
+//	}
+//
+// The last entry of the index of a rotated log file marks its end: its
+// timestamp is the one of the last record, and its count the number of records
+// of the log file. A compressed log file is its file header, followed by gzip
+// members, each starting with the record of an entry, and whose offsets are
+// the ones of the index.
+const (
+	indexHeaderSize = fileHeaderSize + 1
+	indexEntrySize  = 3 * 8
+	indexInterval   = 64 * 1024
+)
+
+type indexEntry struct {
+	timestamp int64
+	count     int64
+	offset    int64
+}
+
+func (e indexEntry) marshal() []byte {
+	var buf [indexEntrySize]byte
+	binary.BigEndian.PutUint64(buf[0:], uint64(e.timestamp))
+	binary.BigEndian.PutUint64(buf[8:], uint64(e.count))
+	binary.BigEndian.PutUint64(buf[16:], uint64(e.offset))
+	return buf[:]
+}
+
+func indexHeader(compressed bool) []byte {
+	hdr := fileHeader(indexMagic)
+	if compressed {
+		return append(hdr, 1)
+	}
+	return append(hdr, 0)
+}
+
+// readIndex reads the index file at path. An incomplete entry at the end of
+// the file, which is being written, is ignored, as well as an incomplete
+// header.
+func readIndex(path string) (entries []indexEntry, compressed bool, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, false, err
+	}
+	buf := make([]byte, fi.Size())
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, false, err
+	}
+	buf = buf[:n]
+	if len(buf) < indexHeaderSize {
+		return nil, false, nil
+	}
+	if err := checkFileHeader(buf, indexMagic, path); err != nil {
+		return nil, false, err
+	}
+	compressed = buf[fileHeaderSize] == 1
+	for buf = buf[indexHeaderSize:]; len(buf) >= indexEntrySize; buf = buf[indexEntrySize:] {
+		entries = append(entries, indexEntry{
+			timestamp: int64(binary.BigEndian.Uint64(buf[0:])),
+			count:     int64(binary.BigEndian.Uint64(buf[8:])),
+			offset:    int64(binary.BigEndian.Uint64(buf[16:])),
+		})
+	}
+	return entries, compressed, nil
+}
@@ -0,0 +1,466 @@
+// Package local provides a Logger implementation writing the logs to files on
+// the host server in a compact binary format. The rotated log files are
+// compressed, and the log files are indexed by timestamp, to read the end of
+// the logs, or the logs since a given time, without reading all the files.
+package local
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/daemon/logger/loggerutils"
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/docker/pkg/pubsub"
+	"github.com/docker/docker/pkg/units"
+)
+
+// Name is the name of the local logging driver.
+const Name = "local"
+
+var errClosed = errors.New("the log file is closed")
+
+const (
+	defaultMaxSize  = 20 * 1024 * 1024
+	defaultMaxFiles = 5
+)
+
+// localLogger writes the messages to a log file, which is rotated like the
+// log files of the json-file driver once it reached its maximum size. Each
+// log file is indexed in an index file, and rotated with it. The rotated log
+// file is compressed in the background.
+type localLogger struct {
+	mu       sync.Mutex
+	path     string
+	f        *os.File // nil if the log file couldn't be opened again
+	index    *os.File
+	closed   bool
+	buf      []byte
+	size     int64 // size of the log file
+	count    int64 // number of records of the log file
+	indexed  int64 // offset of the last entry of the index, -1 if it has none
+	last     int64 // timestamp of the last record
+	capacity int64 // maximum size of the log file
+	maxFiles int
+	compress bool
+
+	// number of rotations of the log file, from which the readers find the
+	// log files rotated since they opened them
+	rotations    int
+	notifyRotate *pubsub.Publisher
+	readers      map[*logger.LogWatcher]struct{} // stores the active log followers
+
+	// the compression of the log file rotated once, which replaces it and
+	// its index while holding filesMu, so that the readers holding it
+	// don't open the index of the compressed log file with the
+	// uncompressed one. A rotation waits for it before renaming the files.
+	compressing sync.WaitGroup
+	filesMu     sync.Mutex
+}
+
+func init() {
+	if err := logger.RegisterLogDriver(Name, New); err != nil {
+		logrus.Fatal(err)
+	}
+	if err := logger.RegisterLogOptValidator(Name, ValidateLogOpt); err != nil {
+		logrus.Fatal(err)
+	}
+}
+
+// New creates a local Logger writing to the log file ctx.LogPath, whose
+// index is written next to it. The writing of an existing log file is
+// resumed.
+func New(ctx logger.Context) (logger.Logger, error) {
+	capacity := int64(defaultMaxSize)
+	if s, ok := ctx.Config["max-size"]; ok {
+		var err error
+		capacity, err = units.FromHumanSize(s)
+		if err != nil {
+			return nil, err
+		}
+	}
+	maxFiles := defaultMaxFiles
+	if s, ok := ctx.Config["max-file"]; ok {
+		var err error
+		maxFiles, err = strconv.Atoi(s)
+		if err != nil {
+			return nil, err
+		}
+		if maxFiles < 1 {
+			return nil, fmt.Errorf("max-file cannot be less than 1")
+		}
+	}
+	compress := true
+	if s, ok := ctx.Config["compress"]; ok {
+		var err error
+		compress, err = strconv.ParseBool(s)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	l := &localLogger{
+		path:         ctx.LogPath,
+		capacity:     capacity,
+		maxFiles:     maxFiles,
+		compress:     compress,
+		notifyRotate: pubsub.NewPublisher(0, 1),
+		readers:      make(map[*logger.LogWatcher]struct{}),
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// segmentPath returns the path of the log file rotated i times.
+func segmentPath(path string, i int) string {
+	if i == 0 {
+		return path
+	}
+	return path + "." + strconv.Itoa(i)
+}
+
+// indexPath returns the path of the index of the log file rotated i times.
+func indexPath(path string, i int) string {
+	return segmentPath(path+".idx", i)
+}
+
+// open opens the log file and its index. The records of an existing log file
+// written after the last entry of its index are counted again, and an
+// incomplete record at its end, written when the daemon stopped, is removed.
+// The files are left closed if it fails.
+func (l *localLogger) open() (err error) {
+	l.size, l.count, l.indexed = fileHeaderSize, 0, -1
+	entries, _, err := readIndex(indexPath(l.path, 0))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0640)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err == nil {
+		if fi.Size() < fileHeaderSize {
+			// the log file is new, or its header was not completely written
+			entries = nil
+			if err = f.Truncate(0); err == nil {
+				_, err = f.WriteAt(fileHeader(logMagic), 0)
+			}
+		} else {
+			err = readLogHeader(f)
+		}
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+	index, err := os.OpenFile(indexPath(l.path, 0), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0640)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	l.f, l.index = f, index
+	defer func() {
+		if err != nil {
+			l.closeFiles()
+		}
+	}()
+
+	// the entries past the end of the log file are the ones of a log file
+	// whose rotation failed
+	for len(entries) > 0 && entries[len(entries)-1].offset > fi.Size() {
+		entries = entries[:len(entries)-1]
+	}
+
+	buf := indexHeader(false)
+	from := indexEntry{offset: -1}
+	for _, e := range entries {
+		buf = append(buf, e.marshal()...)
+		from = e
+	}
+	if _, err := index.Write(buf); err != nil {
+		return err
+	}
+
+	l.indexed = from.offset
+	if from.offset >= 0 {
+		l.size, l.count = from.offset, from.count
+	}
+	if _, err := f.Seek(l.size, os.SEEK_SET); err != nil {
+		return err
+	}
+	r := bufio.NewReader(f)
+	for {
+		msg, n, err := readRecord(r)
+		if err != nil {
+			break
+		}
+		timestamp := msg.Timestamp.UnixNano()
+		if err := l.indexRecord(timestamp); err != nil {
+			return err
+		}
+		l.size += int64(n)
+		l.count++
+		l.last = timestamp
+	}
+	if err := f.Truncate(l.size); err != nil {
+		return err
+	}
+	if _, err := f.Seek(l.size, os.SEEK_SET); err != nil {
+		return err
+	}
+	return nil
+}
+
+// closeFiles closes the log file and its index, if they are open.
+func (l *localLogger) closeFiles() error {
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Close()
+	if indexErr := l.index.Close(); err == nil {
+		err = indexErr
+	}
+	l.f, l.index = nil, nil
+	return err
+}
+
+// indexRecord adds the entry of the record of timestamp `timestamp`, written
+// at the end of the log file, to the index if it's the first record of the
+// log file, or if indexInterval bytes were written since the last entry.
+func (l *localLogger) indexRecord(timestamp int64) error {
+	if l.indexed >= 0 && l.size-l.indexed < indexInterval {
+		return nil
+	}
+	if _, err := l.index.Write(indexEntry{timestamp: timestamp, count: l.count, offset: l.size}.marshal()); err != nil {
+		return err
+	}
+	l.indexed = l.size
+	return nil
+}
+
+// Log writes the message to the log file, after rotating it if it reached its
+// maximum size.
+func (l *localLogger) Log(msg *logger.Message) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return errClosed
+	}
+	if l.f == nil {
+		// the log file couldn't be opened again after a rotation
+		if err := l.open(); err != nil {
+			return err
+		}
+	}
+	if l.capacity > 0 && l.size >= l.capacity {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	timestamp := msg.Timestamp.UnixNano()
+	if err := l.indexRecord(timestamp); err != nil {
+		return err
+	}
+	l.buf = appendRecord(l.buf[:0], msg)
+	n, err := l.f.Write(l.buf)
+	l.size += int64(n)
+	if err != nil {
+		return err
+	}
+	l.count++
+	l.last = timestamp
+	return nil
+}
+
+// rotate marks the end of the log file in its index, and rotates the log file
+// and its index, starting the compression of the rotated log file if needed.
+// Nothing is kept if the maximum number of log files is 1. The log file is
+// opened again whether the rotation succeeded or not.
+func (l *localLogger) rotate() error {
+	if _, err := l.index.Write(indexEntry{timestamp: l.last, count: l.count, offset: l.size}.marshal()); err != nil {
+		return err
+	}
+	if err := l.closeFiles(); err != nil {
+		logrus.Warnf("Error closing the log file %s: %v", l.path, err)
+	}
+	if err := l.rotateFiles(); err != nil {
+		// the log file is written again where it was left
+		if err := l.open(); err != nil {
+			logrus.Errorf("Error opening the log file %s again: %v", l.path, err)
+		}
+		return err
+	}
+	l.rotations++
+	l.notifyRotate.Publish(struct{}{})
+
+	if l.compress && l.maxFiles > 1 {
+		l.compressing.Add(1)
+		go l.compressRotated()
+	}
+	return l.open()
+}
+
+// rotateFiles renames the log file and its index, or removes them if the
+// maximum number of log files is 1. It waits for the compression of the
+// previously rotated log file first, as it replaces the files it renames.
+func (l *localLogger) rotateFiles() error {
+	l.compressing.Wait()
+	if l.maxFiles < 2 {
+		if err := os.Remove(l.path); err != nil {
+			return err
+		}
+		return os.Remove(indexPath(l.path, 0))
+	}
+	if err := loggerutils.Rotate(l.path, l.maxFiles); err != nil {
+		return err
+	}
+	return loggerutils.Rotate(indexPath(l.path, 0), l.maxFiles)
+}
+
+// compressRotated compresses the log file rotated once. The rotated log file
+// is kept uncompressed if it fails.
+func (l *localLogger) compressRotated() {
+	defer l.compressing.Done()
+	if err := compressFile(segmentPath(l.path, 1), indexPath(l.path, 1), &l.filesMu); err != nil {
+		logrus.Errorf("Error compressing the rotated log file %s: %v", segmentPath(l.path, 1), err)
+	}
+}
+
+// compressFile compresses the log file at path, with a gzip member for the
+// records of each entry of its index at indexPath, so that the records of an
+// entry can be read without reading the previous ones. The index is updated
+// with the offsets of the members. The compressed files replace the log file
+// and its index while holding mu.
+func compressFile(path, indexPath string, mu sync.Locker) error {
+	entries, _, err := readIndex(indexPath)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := readLogHeader(f); err != nil {
+		return err
+	}
+	tmp, err := os.OpenFile(path+".tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	w := bufio.NewWriter(tmp)
+	counter := ioutils.NewWriteCounter(w)
+	if _, err := counter.Write(fileHeader(logMagic)); err != nil {
+		return err
+	}
+	if len(entries) > 0 {
+		if _, err := f.Seek(entries[0].offset, os.SEEK_SET); err != nil {
+			return err
+		}
+	}
+	index := indexHeader(true)
+	for i, e := range entries {
+		size := e.offset
+		e.offset = counter.Count
+		index = append(index, e.marshal()...)
+		if i == len(entries)-1 {
+			break
+		}
+		gz := gzip.NewWriter(counter)
+		if _, err := io.CopyN(gz, f, entries[i+1].offset-size); err != nil {
+			return err
+		}
+		if err := gz.Close(); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	indexTmp := indexPath + ".tmp"
+	if err := writeFile(indexTmp, index); err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if err := os.Rename(indexTmp, indexPath); err != nil {
+		os.Remove(indexTmp)
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func writeFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Name returns the name of the local logging driver.
+func (l *localLogger) Name() string {
+	return Name
+}
+
+// Close closes the log file and its index, and signals all the readers to
+// stop. It waits for the compression of the rotated log file.
+func (l *localLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.compressing.Wait()
+	l.closed = true
+	err := l.closeFiles()
+	for r := range l.readers {
+		r.Close()
+		delete(l.readers, r)
+	}
+	return err
+}
+
+// ValidateLogOpt looks for the local driver specific log options max-size,
+// max-file and compress.
+func ValidateLogOpt(cfg map[string]string) error {
+	for key, value := range cfg {
+		switch key {
+		case "max-size":
+			if _, err := units.FromHumanSize(value); err != nil {
+				return fmt.Errorf("invalid value for log opt '%s' of local log driver: %s", key, value)
+			}
+		case "max-file":
+			if n, err := strconv.Atoi(value); err != nil || n < 1 {
+				return fmt.Errorf("invalid value for log opt '%s' of local log driver: %s", key, value)
+			}
+		case "compress":
+			if _, err := strconv.ParseBool(value); err != nil {
+				return fmt.Errorf("invalid value for log opt '%s' of local log driver: %s", key, value)
+			}
+		default:
+			return fmt.Errorf("unknown log opt '%s' for local log driver", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,374 @@
+package local
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/daemon/logger"
+)
+
+var testStart = time.Date(2016, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestLogger(t *testing.T, dir string, config map[string]string) *localLogger {
+	l, err := New(logger.Context{Config: config, LogPath: filepath.Join(dir, "container-local.log")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l.(*localLogger)
+}
+
+var padding = bytes.Repeat([]byte("x"), 1000)
+
+// testMessage returns the message i, of about 1KB. The messages are one second
+// apart.
+func testMessage(i int) *logger.Message {
+	msg := &logger.Message{
+		Line:      append([]byte(strconv.Itoa(i)+" "), padding...),
+		Source:    "stdout",
+		Timestamp: testStart.Add(time.Duration(i) * time.Second),
+	}
+	if i%2 == 1 {
+		msg.Source = "stderr"
+	}
+	return msg
+}
+
+// logMessages logs the messages from..to-1.
+func logMessages(t *testing.T, l logger.Logger, from, to int) {
+	for i := from; i < to; i++ {
+		if err := l.Log(testMessage(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readMessages reads the logs, and returns the numbers of their messages.
+func readMessages(t *testing.T, l *localLogger, config logger.ReadConfig) []int {
+	watcher := l.ReadLogs(config)
+	var numbers []int
+	for msg := range watcher.Msg {
+		numbers = append(numbers, checkMessage(t, msg))
+	}
+	select {
+	case err := <-watcher.Err:
+		t.Fatal(err)
+	default:
+	}
+	return numbers
+}
+
+func checkMessage(t *testing.T, msg *logger.Message) int {
+	i, err := strconv.Atoi(string(bytes.SplitN(msg.Line, []byte(" "), 2)[0]))
+	if err != nil {
+		t.Fatalf("Unexpected message %q", msg.Line)
+	}
+	source := "stdout"
+	if i%2 == 1 {
+		source = "stderr"
+	}
+	if msg.Source != source || !msg.Timestamp.Equal(testStart.Add(time.Duration(i)*time.Second)) || !bytes.HasSuffix(msg.Line, []byte("x\n")) {
+		t.Fatalf("Unexpected message %d: %s %s %q", i, msg.Source, msg.Timestamp, msg.Line)
+	}
+	return i
+}
+
+func checkNumbers(t *testing.T, numbers []int, from, to int) {
+	if len(numbers) != to-from {
+		t.Fatalf("Expected the messages %d to %d, got %v", from, to-1, numbers)
+	}
+	for i, n := range numbers {
+		if n != from+i {
+			t.Fatalf("Expected the messages %d to %d, got %v", from, to-1, numbers)
+		}
+	}
+}
+
+func TestLocalLoggerRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-logger-local-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	l := newTestLogger(t, dir, map[string]string{"max-size": "200k", "max-file": "3"})
+	defer l.Close()
+	logMessages(t, l, 0, 1000)
+	l.compressing.Wait()
+
+	// each log file has about 200 messages, and the oldest ones were removed
+	if _, err := os.Stat(segmentPath(l.path, 3)); !os.IsNotExist(err) {
+		t.Fatalf("Expected only 3 log files, got %v", err)
+	}
+	for i := 1; i <= 2; i++ {
+		data, err := ioutil.ReadFile(segmentPath(l.path, i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) > 50*1024 || !bytes.HasPrefix(data, fileHeader(logMagic)) || data[fileHeaderSize] != 0x1f || data[fileHeaderSize+1] != 0x8b {
+			t.Fatalf("Expected the rotated log file %d to be compressed, got %d bytes", i, len(data))
+		}
+		entries, compressed, err := readIndex(indexPath(l.path, i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !compressed || len(entries) < 3 {
+			t.Fatalf("Unexpected index of the rotated log file %d: %v", i, entries)
+		}
+	}
+
+	numbers := readMessages(t, l, logger.ReadConfig{Tail: -1})
+	if len(numbers) == 0 || numbers[len(numbers)-1] != 999 {
+		t.Fatalf("Expected the last messages, got %v", numbers)
+	}
+	checkNumbers(t, numbers, numbers[0], 1000)
+	if numbers[0] < 500 || numbers[0] > 700 {
+		t.Fatalf("Expected the messages of 3 log files, got the messages from %d", numbers[0])
+	}
+}
+
+func TestLocalLoggerTailAndSince(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-logger-local-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, compress := range []string{"true", "false"} {
+		l := newTestLogger(t, dir, map[string]string{"max-size": "200k", "max-file": "10", "compress": compress})
+		logMessages(t, l, 0, 1000)
+
+		checkNumbers(t, readMessages(t, l, logger.ReadConfig{Tail: -1}), 0, 1000)
+		checkNumbers(t, readMessages(t, l, logger.ReadConfig{Tail: 0}), 0, 0)
+		for _, tail := range []int{1, 10, 150, 201, 450, 999, 1000, 2000} {
+			from := 1000 - tail
+			if from < 0 {
+				from = 0
+			}
+			checkNumbers(t, readMessages(t, l, logger.ReadConfig{Tail: tail}), from, 1000)
+		}
+		for _, since := range []int{0, 1, 150, 201, 450, 999} {
+			config := logger.ReadConfig{Tail: -1, Since: testStart.Add(time.Duration(since) * time.Second)}
+			checkNumbers(t, readMessages(t, l, config), since, 1000)
+		}
+		config := logger.ReadConfig{Tail: 100, Since: testStart.Add(950 * time.Second)}
+		checkNumbers(t, readMessages(t, l, config), 950, 1000)
+
+		l.Close()
+		os.RemoveAll(dir)
+		os.MkdirAll(dir, 0755)
+	}
+}
+
+func TestLocalLoggerReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-logger-local-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	l := newTestLogger(t, dir, nil)
+	logMessages(t, l, 0, 100)
+	l.Close()
+
+	// an incomplete record written when the daemon stopped is removed
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write(appendRecord(nil, &logger.Message{Line: []byte("incomplete")})[:10]); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	l = newTestLogger(t, dir, nil)
+	defer l.Close()
+	if l.count != 100 {
+		t.Fatalf("Expected 100 records, got %d", l.count)
+	}
+	logMessages(t, l, 100, 200)
+	checkNumbers(t, readMessages(t, l, logger.ReadConfig{Tail: -1}), 0, 200)
+	checkNumbers(t, readMessages(t, l, logger.ReadConfig{Tail: 10}), 190, 200)
+}
+
+func TestLocalLoggerRotationError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-logger-local-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	l := newTestLogger(t, dir, map[string]string{"max-size": "100k", "max-file": "2"})
+	defer l.Close()
+	// the log file can't be rotated while a directory is in the way
+	if err := os.MkdirAll(filepath.Join(segmentPath(l.path, 1), "dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for ; n < 200; n++ {
+		if err := l.Log(testMessage(n)); err != nil {
+			break
+		}
+	}
+	if n == 200 {
+		t.Fatal("Expected the rotation to fail")
+	}
+	checkNumbers(t, readMessages(t, l, logger.ReadConfig{Tail: -1}), 0, n)
+
+	// the log file is written again, and rotated once it can be
+	if err := os.RemoveAll(segmentPath(l.path, 1)); err != nil {
+		t.Fatal(err)
+	}
+	logMessages(t, l, n, 300)
+	if l.rotations == 0 {
+		t.Fatal("Expected the log file to be rotated")
+	}
+	numbers := readMessages(t, l, logger.ReadConfig{Tail: -1})
+	if len(numbers) == 0 || numbers[len(numbers)-1] != 299 {
+		t.Fatalf("Expected the last messages, got %v", numbers)
+	}
+	checkNumbers(t, numbers, numbers[0], 300)
+}
+
+func TestLocalLoggerFormatVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-logger-local-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	l := newTestLogger(t, dir, nil)
+	logMessages(t, l, 0, 10)
+	l.Close()
+
+	for _, path := range []string{l.path, indexPath(l.path, 0)} {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data[fileHeaderSize-1] = formatVersion + 1
+		if err := ioutil.WriteFile(path, data, 0640); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := New(logger.Context{LogPath: l.path}); err == nil {
+			t.Fatalf("Expected an error for the format version of %s", path)
+		}
+		data[fileHeaderSize-1] = formatVersion
+		if err := ioutil.WriteFile(path, data, 0640); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l = newTestLogger(t, dir, nil)
+	defer l.Close()
+	checkNumbers(t, readMessages(t, l, logger.ReadConfig{Tail: -1}), 0, 10)
+}
+
+func TestLocalLoggerFollow(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-logger-local-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	l := newTestLogger(t, dir, map[string]string{"max-size": "200k"})
+	logMessages(t, l, 0, 10)
+
+	watcher := l.ReadLogs(logger.ReadConfig{Tail: 5, Follow: true})
+	logErr := make(chan error, 1)
+	for i := 5; i < 500; i++ {
+		if i == 10 {
+			// the messages are followed across the rotations of the
+			// log file
+			go func() {
+				for i := 10; i < 500; i++ {
+					if err := l.Log(testMessage(i)); err != nil {
+						logErr <- err
+						return
+					}
+				}
+			}()
+		}
+		select {
+		case msg := <-watcher.Msg:
+			if n := checkMessage(t, msg); n != i {
+				t.Fatalf("Expected the message %d, got %d", i, n)
+			}
+		case err := <-watcher.Err:
+			t.Fatal(err)
+		case err := <-logErr:
+			t.Fatal(err)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("Timeout waiting for the message %d", i)
+		}
+	}
+
+	l.Close()
+	select {
+	case _, ok := <-watcher.Msg:
+		if ok {
+			t.Fatal("Expected no more messages")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Expected the watcher to be closed with the logger")
+	}
+}
+
+func TestRecordPartial(t *testing.T) {
+	var buf []byte
+	buf = appendRecord(buf, &logger.Message{Line: []byte("a long"), Source: "stdout", Timestamp: testStart, Partial: true})
+	buf = appendRecord(buf, &logger.Message{Line: []byte(" line"), Source: "stdout", Timestamp: testStart})
+
+	r := bytes.NewReader(buf)
+	var lines []byte
+	for _, partial := range []bool{true, false} {
+		msg, _, err := readRecord(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.Partial != partial || msg.Source != "stdout" || !msg.Timestamp.Equal(testStart) {
+			t.Fatalf("Unexpected message %q: %s %s partial %v", msg.Line, msg.Source, msg.Timestamp, msg.Partial)
+		}
+		lines = append(lines, msg.Line...)
+	}
+	if string(lines) != "a long line\n" {
+		t.Fatalf("Expected the partial message to have no newline, got %q", lines)
+	}
+}
+
+func TestValidateLogOpt(t *testing.T) {
+	valid := map[string]string{"max-size": "10m", "max-file": "3", "compress": "false"}
+	if err := ValidateLogOpt(valid); err != nil {
+		t.Fatal(err)
+	}
+	for key, value := range map[string]string{"max-size": "ten", "max-file": "0", "compress": "maybe", "labels": "foo"} {
+		if err := ValidateLogOpt(map[string]string{key: value}); err == nil {
+			t.Fatalf("Expected an error for %s=%s", key, value)
+		}
+	}
+}
+
+func BenchmarkLocalLogger(b *testing.B) {
+	dir, err := ioutil.TempDir("", "docker-logger-local-")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	l, err := New(logger.Context{LogPath: filepath.Join(dir, "container-local.log")})
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer l.Close()
+
+	msg := &logger.Message{Line: []byte(fmt.Sprintf("%100s", "line")), Source: "stdout", Timestamp: time.Now()}
+	b.SetBytes(int64(len(msg.Line)))
+	for i := 0; i < b.N; i++ {
+		if err := l.Log(msg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package local
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/pkg/filenotify"
+)
+
+// segment is a log file opened to be read, with its index. The last entry of
+// the index marks the end of the log file.
+type segment struct {
+	f          *os.File
+	entries    []indexEntry
+	compressed bool
+}
+
+// count returns the number of records of the log file.
+func (s *segment) count() int64 {
+	return s.entries[len(s.entries)-1].count
+}
+
+// search returns the last entry of the index for which f is false, or the
+// first one if f is true for all of them.
+func (s *segment) search(f func(e indexEntry) bool) int {
+	i := sort.Search(len(s.entries)-1, func(i int) bool { return f(s.entries[i]) }) - 1
+	if i < 0 {
+		return 0
+	}
+	return i
+}
+
+// reader returns a reader of the records of the log file, from the one of the
+// entry i of the index.
+func (s *segment) reader(i int) (io.Reader, error) {
+	start, end := s.entries[i].offset, s.entries[len(s.entries)-1].offset
+	if start >= end {
+		return strings.NewReader(""), nil
+	}
+	if _, err := s.f.Seek(start, os.SEEK_SET); err != nil {
+		return nil, err
+	}
+	if s.compressed {
+		return gzip.NewReader(bufio.NewReader(s.f))
+	}
+	return bufio.NewReader(io.LimitReader(s.f, end-start)), nil
+}
+
+// openSegments opens the log files, from the one rotated `from` times to the
+// current one. The end of the current log file is the one at the time it is
+// opened. It has to be called with the lock held.
+func (l *localLogger) openSegments(from int) ([]*segment, error) {
+	if from > l.maxFiles-1 {
+		from = l.maxFiles - 1
+	}
+	// the rotated log file being compressed can't be replaced in the meantime
+	l.filesMu.Lock()
+	defer l.filesMu.Unlock()
+	var segments []*segment
+	for i := from; i >= 0; i-- {
+		entries, compressed, err := readIndex(indexPath(l.path, i))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			closeSegments(segments)
+			return nil, err
+		}
+		if i == 0 {
+			entries = append(entries, indexEntry{timestamp: l.last, count: l.count, offset: l.size})
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		f, err := os.Open(segmentPath(l.path, i))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			closeSegments(segments)
+			return nil, err
+		}
+		if err := readLogHeader(f); err != nil {
+			f.Close()
+			closeSegments(segments)
+			return nil, err
+		}
+		segments = append(segments, &segment{f: f, entries: entries, compressed: compressed})
+	}
+	return segments, nil
+}
+
+func closeSegments(segments []*segment) {
+	for _, s := range segments {
+		s.f.Close()
+	}
+}
+
+// seek returns the segment and the entry of its index from which the logs are
+// read, and the number of records to skip from there, for the tail or the
+// since time of the config.
+func seek(segments []*segment, config logger.ReadConfig) (int, int, int64) {
+	if config.Tail >= 0 {
+		remaining := int64(config.Tail)
+		for i := len(segments) - 1; i >= 0; i-- {
+			s := segments[i]
+			if remaining <= s.count() {
+				skip := s.count() - remaining
+				j := s.search(func(e indexEntry) bool { return e.count > skip })
+				return i, j, skip - s.entries[j].count
+			}
+			remaining -= s.count()
+		}
+		return 0, 0, 0
+	}
+	if !config.Since.IsZero() {
+		since := config.Since.UnixNano()
+		// the last log file starting before since
+		i := sort.Search(len(segments), func(i int) bool {
+			return segments[i].count() == 0 || segments[i].entries[0].timestamp > since
+		}) - 1
+		if i < 0 {
+			return 0, 0, 0
+		}
+		j := segments[i].search(func(e indexEntry) bool { return e.timestamp > since })
+		return i, j, 0
+	}
+	return 0, 0, 0
+}
+
+// ReadLogs implements the logger's LogReader interface for the logs
+// created by this driver.
+func (l *localLogger) ReadLogs(config logger.ReadConfig) *logger.LogWatcher {
+	logWatcher := logger.NewLogWatcher()
+
+	go l.readLogs(logWatcher, config)
+	return logWatcher
+}
+
+func (l *localLogger) readLogs(logWatcher *logger.LogWatcher, config logger.ReadConfig) {
+	defer close(logWatcher.Msg)
+
+	l.mu.Lock()
+	segments, err := l.openSegments(l.maxFiles - 1)
+	rotations := l.rotations
+	var notifyRotate chan interface{}
+	if err == nil && config.Follow {
+		notifyRotate = l.notifyRotate.Subscribe()
+		l.readers[logWatcher] = struct{}{}
+	}
+	l.mu.Unlock()
+	if err != nil {
+		logWatcher.Err <- err
+		return
+	}
+	defer func() {
+		closeSegments(segments)
+	}()
+	if config.Follow {
+		defer func() {
+			l.mu.Lock()
+			delete(l.readers, logWatcher)
+			l.mu.Unlock()
+
+			l.notifyRotate.Evict(notifyRotate)
+		}()
+	}
+
+	i, j, skip := seek(segments, config)
+	for {
+		for ; i < len(segments); i++ {
+			r, err := segments[i].reader(j)
+			if err != nil {
+				logWatcher.Err <- err
+				return
+			}
+			if !sendMessages(r, skip, config.Since, logWatcher) {
+				return
+			}
+			j, skip = 0, 0
+		}
+		if !config.Follow {
+			return
+		}
+
+		current := segments[len(segments)-1]
+		if _, err := current.f.Seek(current.entries[len(current.entries)-1].offset, os.SEEK_SET); err != nil {
+			logWatcher.Err <- err
+			return
+		}
+		for {
+			if !followLogs(current.f, logWatcher, notifyRotate, config.Since) {
+				return
+			}
+			l.mu.Lock()
+			// the notification can be the one of a rotation which was
+			// already handled
+			if l.rotations != rotations {
+				break
+			}
+			l.mu.Unlock()
+		}
+
+		// the log file was rotated, the log files written since are
+		// read before following the new one
+		closeSegments(segments)
+		segments, err = l.openSegments(l.rotations - rotations - 1)
+		rotations = l.rotations
+		l.mu.Unlock()
+		if err != nil {
+			segments = nil
+			logWatcher.Err <- err
+			return
+		}
+		i = 0
+	}
+}
+
+// sendMessages sends the messages of the records of r to the watcher, after
+// skipping `skip` records, and the ones before since. It returns false if the
+// reading stopped because of an error, or because the watcher was closed.
+func sendMessages(r io.Reader, skip int64, since time.Time, logWatcher *logger.LogWatcher) bool {
+	for {
+		msg, _, err := readRecord(r)
+		if err != nil {
+			if err == io.EOF {
+				return true
+			}
+			logWatcher.Err <- err
+			return false
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		if !since.IsZero() && msg.Timestamp.Before(since) {
+			continue
+		}
+		select {
+		case logWatcher.Msg <- msg:
+		case <-logWatcher.WatchClose():
+			return false
+		}
+	}
+}
+
+// drainMessages sends the messages of the complete records left in f to the
+// watcher.
+func drainMessages(f *os.File, since time.Time, logWatcher *logger.LogWatcher) {
+	for {
+		msg, _, err := readRecord(f)
+		if err != nil {
+			return
+		}
+		if !since.IsZero() && msg.Timestamp.Before(since) {
+			continue
+		}
+		logWatcher.Msg <- msg
+	}
+}
+
+// followLogs sends the messages of the records written to the current log
+// file f, until the watcher is closed, or until the log file is rotated, in
+// which case it returns true.
+func followLogs(f *os.File, logWatcher *logger.LogWatcher, notifyRotate chan interface{}, since time.Time) bool {
+	fileWatcher, err := filenotify.New()
+	if err != nil {
+		logWatcher.Err <- err
+		return false
+	}
+	defer fileWatcher.Close()
+	if err := fileWatcher.Add(f.Name()); err != nil {
+		logrus.WithField("logger", Name).Warn("falling back to file poller")
+		fileWatcher.Close()
+		fileWatcher = filenotify.NewPollingWatcher()
+		if err := fileWatcher.Add(f.Name()); err != nil {
+			logrus.Errorf("error watching log file for modifications: %v", err)
+			logWatcher.Err <- err
+			return false
+		}
+	}
+
+	for {
+		msg, n, err := readRecord(f)
+		if err == nil {
+			if !since.IsZero() && msg.Timestamp.Before(since) {
+				continue
+			}
+			select {
+			case logWatcher.Msg <- msg:
+				continue
+			case <-logWatcher.WatchClose():
+				logWatcher.Msg <- msg
+				drainMessages(f, since, logWatcher)
+				return false
+			}
+		}
+		if err != io.EOF && err != io.ErrUnexpectedEOF {
+			logWatcher.Err <- err
+			return false
+		}
+
+		// the record is being written, it's read again once it's complete
+		if _, err := f.Seek(int64(-n), os.SEEK_CUR); err != nil {
+			logWatcher.Err <- err
+			return false
+		}
+		select {
+		case <-fileWatcher.Events():
+		case err := <-fileWatcher.Errors():
+			logWatcher.Err <- err
+			return false
+		case <-logWatcher.WatchClose():
+			drainMessages(f, since, logWatcher)
+			return false
+		case <-notifyRotate:
+			// the rotated log file is complete
+			drainMessages(f, since, logWatcher)
+			return true
+		}
+	}
+}
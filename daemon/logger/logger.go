@@ -29,6 +29,10 @@ type Message struct {
 	Line        []byte
 	Source      string
 	Timestamp   time.Time
+	// Partial is set if the message is a fragment of a line longer than
+	// the maximum size of a message, which continues in the next message
+	// of the same source.
+	Partial bool
 }
 
 // Logger is the interface for docker logging drivers.
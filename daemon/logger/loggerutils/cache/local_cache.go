@@ -0,0 +1,135 @@
+// Package cache provides a local cache of the logs of the containers whose
+// logging driver can't read the logs back, so that the logs of the containers
+// can still be read with `docker logs` whatever their logging driver.
+package cache
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/daemon/logger/jsonfilelog"
+	"github.com/docker/docker/pkg/units"
+)
+
+// The log options of the local cache, which are supported for all the
+// logging drivers.
+const (
+	// DisabledOpt disables the local cache when set to "true".
+	DisabledOpt = "cache-disabled"
+	// MaxSizeOpt is the maximum size of a file of the local cache, such as
+	// "20m", before it is rotated.
+	MaxSizeOpt = "cache-max-size"
+	// MaxFileOpt is the maximum number of files of the local cache.
+	MaxFileOpt = "cache-max-file"
+
+	defaultMaxSize = "20m"
+	defaultMaxFile = "5"
+)
+
+func init() {
+	if err := logger.RegisterGlobalLogOptValidator([]string{DisabledOpt, MaxSizeOpt, MaxFileOpt}, ValidateLogOpt); err != nil {
+		logrus.Fatal(err)
+	}
+}
+
+// Enabled returns whether the log options cfg enable the local cache, which is
+// the default.
+func Enabled(cfg map[string]string) bool {
+	disabled, _ := strconv.ParseBool(cfg[DisabledOpt])
+	return !disabled
+}
+
+// loggerWithCache sends the messages both to a logging driver and to the
+// local cache, from which the logs are read back.
+type loggerWithCache struct {
+	l     logger.Logger
+	cache *jsonfilelog.JSONFileLogger
+}
+
+// WithLocalCache returns a Logger sending the messages to the logging driver
+// l, and to a local cache in the json-file format at ctx.LogPath. The logs
+// are read back from the local cache, so that the returned Logger is a
+// LogReader. The size of the cache is set by the log options of ctx.
+func WithLocalCache(l logger.Logger, ctx logger.Context) (logger.Logger, error) {
+	cache, err := jsonfilelog.New(cacheContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &loggerWithCache{l: l, cache: cache.(*jsonfilelog.JSONFileLogger)}, nil
+}
+
+// OpenLocalCache returns a LogReader reading the local cache at ctx.LogPath,
+// to read the logs of a container which is not running without creating its
+// logging driver.
+func OpenLocalCache(ctx logger.Context) (logger.Logger, error) {
+	return jsonfilelog.New(cacheContext(ctx))
+}
+
+// cacheContext returns the context of the json-file logger of the local
+// cache, whose size is set by the log options of ctx.
+func cacheContext(ctx logger.Context) logger.Context {
+	cacheCtx := ctx
+	cacheCtx.Config = map[string]string{
+		"max-size": defaultMaxSize,
+		"max-file": defaultMaxFile,
+	}
+	if v, ok := ctx.Config[MaxSizeOpt]; ok {
+		cacheCtx.Config["max-size"] = v
+	}
+	if v, ok := ctx.Config[MaxFileOpt]; ok {
+		cacheCtx.Config["max-file"] = v
+	}
+	return cacheCtx
+}
+
+// Log sends the message to the local cache and to the logging driver. An
+// error writing to the cache is only logged, so that the messages are still
+// sent to the logging driver.
+func (l *loggerWithCache) Log(msg *logger.Message) error {
+	if err := l.cache.Log(msg); err != nil {
+		logrus.Warnf("Error writing log message to the local cache of %s: %v", l.cache.LogPath(), err)
+	}
+	return l.l.Log(msg)
+}
+
+// Name returns the name of the logging driver.
+func (l *loggerWithCache) Name() string {
+	return l.l.Name()
+}
+
+// ReadLogs reads the logs from the local cache.
+func (l *loggerWithCache) ReadLogs(config logger.ReadConfig) *logger.LogWatcher {
+	return l.cache.ReadLogs(config)
+}
+
+// Close closes the logging driver and the local cache.
+func (l *loggerWithCache) Close() error {
+	err := l.l.Close()
+	if cacheErr := l.cache.Close(); cacheErr != nil {
+		logrus.Warnf("Error closing the local cache of %s: %v", l.cache.LogPath(), cacheErr)
+	}
+	return err
+}
+
+// ValidateLogOpt checks the log options of the local cache.
+func ValidateLogOpt(cfg map[string]string) error {
+	if v, ok := cfg[DisabledOpt]; ok {
+		if _, err := strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("invalid value for log option %s: %s", DisabledOpt, v)
+		}
+	}
+	if v, ok := cfg[MaxSizeOpt]; ok {
+		if _, err := units.FromHumanSize(v); err != nil {
+			return fmt.Errorf("invalid value for log option %s: %s", MaxSizeOpt, v)
+		}
+	}
+	if v, ok := cfg[MaxFileOpt]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid value for log option %s: %s", MaxFileOpt, v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/daemon/logger"
+)
+
+type testLogger struct {
+	lines  []string
+	closed bool
+}
+
+func (l *testLogger) Log(m *logger.Message) error {
+	l.lines = append(l.lines, string(m.Line))
+	return nil
+}
+
+func (l *testLogger) Close() error {
+	l.closed = true
+	return nil
+}
+
+func (l *testLogger) Name() string { return "test" }
+
+func TestWithLocalCache(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-logger-cache-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	driver := &testLogger{}
+	l, err := WithLocalCache(driver, logger.Context{
+		Config:  map[string]string{MaxSizeOpt: "1k", "gelf-address": "udp://127.0.0.1:12201"},
+		LogPath: filepath.Join(tmp, "container-cache.log"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Name() != "test" {
+		t.Fatalf("Expected the name of the logging driver, got %s", l.Name())
+	}
+	for _, line := range []string{"line1", "line2"} {
+		if err := l.Log(&logger.Message{Line: []byte(line), Source: "stdout", Timestamp: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(driver.lines) != 2 {
+		t.Fatalf("Expected the messages to be sent to the logging driver, got %v", driver.lines)
+	}
+
+	reader, ok := l.(logger.LogReader)
+	if !ok {
+		t.Fatal("Expected the logger to be a LogReader")
+	}
+	watcher := reader.ReadLogs(logger.ReadConfig{Tail: -1})
+	var lines []string
+	for msg := range watcher.Msg {
+		lines = append(lines, string(msg.Line))
+	}
+	if len(lines) != 2 || lines[0] != "line1\n" || lines[1] != "line2\n" {
+		t.Fatalf("Expected the messages to be read from the cache, got %q", lines)
+	}
+
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !driver.closed {
+		t.Fatal("Expected the logging driver to be closed")
+	}
+}
+
+func TestOpenLocalCache(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-logger-cache-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	ctx := logger.Context{
+		Config:  map[string]string{MaxSizeOpt: "1k", MaxFileOpt: "3"},
+		LogPath: filepath.Join(tmp, "container-cache.log"),
+	}
+	l, err := WithLocalCache(&testLogger{}, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		if err := l.Log(&logger.Message{Line: []byte("line"), Source: "stdout", Timestamp: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.Close()
+
+	// the logs are read back without the logging driver, including the
+	// rotated files of the cache
+	cached, err := OpenLocalCache(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cached.Close()
+	reader, ok := cached.(logger.LogReader)
+	if !ok {
+		t.Fatal("Expected the cache to be a LogReader")
+	}
+	watcher := reader.ReadLogs(logger.ReadConfig{Tail: -1})
+	n := 0
+	for range watcher.Msg {
+		n++
+	}
+	if n != 20 {
+		t.Fatalf("Expected the 20 messages to be read from the cache, got %d", n)
+	}
+}
+
+func TestValidateLogOpt(t *testing.T) {
+	for _, cfg := range []map[string]string{
+		{},
+		{DisabledOpt: "true"},
+		{MaxSizeOpt: "10m", MaxFileOpt: "3"},
+	} {
+		if err := ValidateLogOpt(cfg); err != nil {
+			t.Fatalf("Unexpected error for %v: %v", cfg, err)
+		}
+	}
+	for _, cfg := range []map[string]string{
+		{DisabledOpt: "maybe"},
+		{MaxSizeOpt: "ten"},
+		{MaxFileOpt: "0"},
+	} {
+		if err := ValidateLogOpt(cfg); err == nil {
+			t.Fatalf("Expected an error for %v", cfg)
+		}
+	}
+
+	if !Enabled(map[string]string{}) || Enabled(map[string]string{DisabledOpt: "true"}) {
+		t.Fatal("Expected the cache to be enabled by default, and disabled with cache-disabled=true")
+	}
+}
@@ -58,7 +58,7 @@ func (w *RotateFileWriter) checkCapacityAndRotate() error {
 		if err := w.f.Close(); err != nil {
 			return err
 		}
-		if err := rotate(name, w.maxFiles); err != nil {
+		if err := Rotate(name, w.maxFiles); err != nil {
 			return err
 		}
 		file, err := os.OpenFile(name, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 06400)
@@ -72,7 +72,10 @@ func (w *RotateFileWriter) checkCapacityAndRotate() error {
 	return nil
 }
 
-func rotate(name string, maxFiles int) error {
+// Rotate renames the file name to name.1, after renaming the previous files
+// name.N to name.N+1, up to maxFiles files. The last file is removed. Nothing
+// is renamed if maxFiles is less than 2.
+func Rotate(name string, maxFiles int) error {
 	if maxFiles < 2 {
 		return nil
 	}
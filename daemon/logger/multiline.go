@@ -0,0 +1,158 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// multilineFlushInterval is the time after which a multiline message is sent
+// to the logging driver if no line was added to it.
+var multilineFlushInterval = time.Second
+
+// MultilineLogger is a Logger grouping the lines of an event written on
+// several lines, such as a Java stack trace, in a single message sent to
+// another Logger. An event starts with a line matching the pattern, and goes
+// on with the lines which don't match it. The partial messages of a long line
+// are joined with it.
+type MultilineLogger struct {
+	l       Logger
+	pattern *regexp.Regexp
+
+	mu      sync.Mutex
+	pending map[string]*multilineMessage // the current event of each source
+	closed  chan struct{}
+	done    chan struct{}
+}
+
+type multilineMessage struct {
+	*Message
+	updated time.Time
+}
+
+type multilineWithReader struct {
+	*MultilineLogger
+}
+
+func (m *multilineWithReader) ReadLogs(cfg ReadConfig) *LogWatcher {
+	reader, ok := m.l.(LogReader)
+	if !ok {
+		// something is wrong if we get here
+		panic("expected log reader")
+	}
+	return reader.ReadLogs(cfg)
+}
+
+// NewMultilineLogger returns a MultilineLogger sending the events starting
+// with a line matching pattern to the Logger driver. The returned Logger is a
+// LogReader if the driver is.
+func NewMultilineLogger(driver Logger, pattern *regexp.Regexp) Logger {
+	m := &MultilineLogger{
+		l:       driver,
+		pattern: pattern,
+		pending: make(map[string]*multilineMessage),
+		closed:  make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go m.run()
+	if _, ok := driver.(LogReader); ok {
+		return &multilineWithReader{m}
+	}
+	return m
+}
+
+// Log adds the message to the current event of its source, or sends the
+// current event to the logging driver if the message starts a new one. The
+// events longer than maxJoinedSize are sent without waiting for their end.
+func (m *MultilineLogger) Log(msg *Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var err error
+	if pending := m.pending[msg.Source]; pending != nil {
+		if pending.Partial || !m.pattern.Match(msg.Line) {
+			if !pending.Partial {
+				pending.Line = append(pending.Line, '\n')
+			}
+			pending.Line = append(pending.Line, msg.Line...)
+			pending.Partial = msg.Partial
+			pending.updated = time.Now()
+			if len(pending.Line) < maxJoinedSize {
+				return nil
+			}
+			delete(m.pending, msg.Source)
+			return m.l.Log(pending.Message)
+		}
+		delete(m.pending, msg.Source)
+		err = m.l.Log(pending.Message)
+	}
+	m.pending[msg.Source] = &multilineMessage{
+		Message: &Message{
+			ContainerID: msg.ContainerID,
+			Line:        append([]byte(nil), msg.Line...),
+			Source:      msg.Source,
+			Timestamp:   msg.Timestamp,
+			Partial:     msg.Partial,
+		},
+		updated: time.Now(),
+	}
+	return err
+}
+
+// Name returns the name of the logging driver.
+func (m *MultilineLogger) Name() string {
+	return m.l.Name()
+}
+
+// Close sends the current events to the logging driver, and closes it.
+func (m *MultilineLogger) Close() error {
+	close(m.closed)
+	<-m.done
+	m.flush(time.Time{})
+	return m.l.Close()
+}
+
+// run sends the events to which no line was added for multilineFlushInterval
+// to the logging driver, until the logger is closed.
+func (m *MultilineLogger) run() {
+	defer close(m.done)
+	ticker := time.NewTicker(multilineFlushInterval / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.flush(time.Now().Add(-multilineFlushInterval))
+		case <-m.closed:
+			return
+		}
+	}
+}
+
+// flush sends the events last updated before `before` to the logging driver,
+// or all of them if before is zero.
+func (m *MultilineLogger) flush(before time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for source, pending := range m.pending {
+		if !before.IsZero() && pending.updated.After(before) {
+			continue
+		}
+		delete(m.pending, source)
+		if err := m.l.Log(pending.Message); err != nil {
+			logrus.Errorf("Failed to log msg %q for logger %s: %s", pending.Line, m.l.Name(), err)
+		}
+	}
+}
+
+// validateMultilineOpts checks the pattern of the multiline messages.
+func validateMultilineOpts(cfg map[string]string) error {
+	if s, ok := cfg[MultilinePatternOpt]; ok {
+		if _, err := regexp.Compile(s); err != nil {
+			return fmt.Errorf("logger: error parsing option %s: %v", MultilinePatternOpt, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestMultilineLogger(t *testing.T) {
+	driver := &blockingLogger{unblock: make(chan struct{})}
+	close(driver.unblock)
+	l := NewMultilineLogger(driver, regexp.MustCompile(`^[^\s]`))
+
+	for _, msg := range []*Message{
+		{Line: []byte("Exception in thread main"), Source: "stdout"},
+		{Line: []byte("\tat Foo.bar(Foo.java:10)"), Source: "stdout"},
+		{Line: []byte("error"), Source: "stderr"},
+		{Line: []byte("\tat Foo.main(Foo.java:5)"), Source: "stdout"},
+		{Line: []byte("a long "), Source: "stdout", Partial: true},
+		{Line: []byte(" line"), Source: "stdout"},
+		{Line: []byte("next"), Source: "stdout"},
+	} {
+		if err := l.Log(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !driver.closed {
+		t.Fatal("Expected the logging driver to be closed")
+	}
+
+	expected := map[string]bool{
+		"Exception in thread main\n\tat Foo.bar(Foo.java:10)\n\tat Foo.main(Foo.java:5)": true,
+		"a long  line": true,
+		"error":        true,
+		"next":         true,
+	}
+	if len(driver.lines) != len(expected) {
+		t.Fatalf("Expected the messages %v, got %q", expected, driver.lines)
+	}
+	for _, line := range driver.lines {
+		if !expected[line] {
+			t.Fatalf("Unexpected message %q", line)
+		}
+	}
+}
+
+func TestMultilineLoggerFlush(t *testing.T) {
+	defer func(interval time.Duration) { multilineFlushInterval = interval }(multilineFlushInterval)
+	multilineFlushInterval = 10 * time.Millisecond
+
+	driver := &blockingLogger{unblock: make(chan struct{})}
+	close(driver.unblock)
+	l := NewMultilineLogger(driver, regexp.MustCompile(`^[^\s]`))
+	defer l.Close()
+
+	if err := l.Log(&Message{Line: []byte("last line"), Source: "stdout"}); err != nil {
+		t.Fatal(err)
+	}
+	for start := time.Now(); time.Since(start) < 10*time.Second; time.Sleep(10 * time.Millisecond) {
+		driver.mu.Lock()
+		lines := driver.lines
+		driver.mu.Unlock()
+		if len(lines) == 1 && lines[0] == "last line" {
+			return
+		}
+	}
+	t.Fatal("Expected the last message to be sent after the flush interval")
+}
+
+func TestValidateLogOptsMultiline(t *testing.T) {
+	if err := ValidateLogOpts("none", map[string]string{MultilinePatternOpt: `^\d{4}-`}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateLogOpts("none", map[string]string{MultilinePatternOpt: `^(`}); err == nil {
+		t.Fatal("Expected an error for an invalid pattern")
+	}
+}
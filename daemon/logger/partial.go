@@ -0,0 +1,60 @@
+package logger
+
+import "sync"
+
+// maxJoinedSize is the maximum size of a line joined from partial messages.
+// The longer lines are still split, in messages of this size.
+const maxJoinedSize = 1024 * 1024
+
+// PartialJoiner joins the partial messages of the lines split by the Copier,
+// for the logging drivers sending each line as a single event. The zero value
+// is ready to use.
+type PartialJoiner struct {
+	mu      sync.Mutex
+	pending map[string]*Message // the incomplete line of each source
+}
+
+// Join returns the message of the whole line ended by msg, or nil if msg is
+// partial, in which case it's kept until the end of its line. The timestamp
+// of the line is the one of its first message.
+func (j *PartialJoiner) Join(msg *Message) *Message {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	pending := j.pending[msg.Source]
+	if pending == nil {
+		if !msg.Partial {
+			return msg
+		}
+		if j.pending == nil {
+			j.pending = make(map[string]*Message)
+		}
+		pending = &Message{
+			ContainerID: msg.ContainerID,
+			Source:      msg.Source,
+			Timestamp:   msg.Timestamp,
+		}
+		j.pending[msg.Source] = pending
+	}
+	pending.Line = append(pending.Line, msg.Line...)
+	pending.Partial = msg.Partial
+	if pending.Partial && len(pending.Line) < maxJoinedSize {
+		return nil
+	}
+	delete(j.pending, msg.Source)
+	return pending
+}
+
+// Flush returns the messages of the incomplete lines, which are still
+// partial.
+func (j *PartialJoiner) Flush() []*Message {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var messages []*Message
+	for source, msg := range j.pending {
+		messages = append(messages, msg)
+		delete(j.pending, source)
+	}
+	return messages
+}
@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestPartialJoiner(t *testing.T) {
+	var j PartialJoiner
+	now := time.Now()
+
+	msg := &Message{Line: []byte("whole"), Source: "stdout"}
+	if joined := j.Join(msg); joined != msg {
+		t.Fatalf("Expected a whole line to be returned as is, got %v", joined)
+	}
+
+	if joined := j.Join(&Message{Line: []byte("first "), Source: "stdout", Timestamp: now, Partial: true}); joined != nil {
+		t.Fatalf("Expected a partial message to be kept, got %q", joined.Line)
+	}
+	if joined := j.Join(&Message{Line: []byte("other"), Source: "stderr"}); joined == nil || string(joined.Line) != "other" {
+		t.Fatalf("Expected the line of another source, got %v", joined)
+	}
+	if joined := j.Join(&Message{Line: []byte("second "), Source: "stdout", Timestamp: now.Add(time.Second), Partial: true}); joined != nil {
+		t.Fatalf("Expected a partial message to be kept, got %q", joined.Line)
+	}
+	joined := j.Join(&Message{Line: []byte("last"), Source: "stdout", Timestamp: now.Add(2 * time.Second)})
+	if joined == nil || string(joined.Line) != "first second last" || joined.Partial || !joined.Timestamp.Equal(now) {
+		t.Fatalf("Expected the joined line, got %v", joined)
+	}
+
+	j.Join(&Message{Line: []byte("incomplete"), Source: "stdout", Partial: true})
+	flushed := j.Flush()
+	if len(flushed) != 1 || string(flushed[0].Line) != "incomplete" || !flushed[0].Partial {
+		t.Fatalf("Expected the incomplete line to be flushed, got %v", flushed)
+	}
+	if flushed := j.Flush(); len(flushed) != 0 {
+		t.Fatalf("Expected nothing left to flush, got %v", flushed)
+	}
+}
+
+func TestPartialJoinerMaxSize(t *testing.T) {
+	var j PartialJoiner
+	chunk := bytes.Repeat([]byte("x"), maxJoinedSize/2)
+	j.Join(&Message{Line: chunk, Source: "stdout", Partial: true})
+	joined := j.Join(&Message{Line: chunk, Source: "stdout", Partial: true})
+	if joined == nil || len(joined.Line) != maxJoinedSize || !joined.Partial {
+		t.Fatal("Expected a partial message of the maximum size")
+	}
+}
@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/pkg/plugins"
+	"github.com/docker/docker/pkg/plugins/logdriver"
+	"github.com/docker/docker/pkg/stringid"
+)
+
+const extName = "LogDriver"
+
+// pluginStreamsDir is the directory of the streams of the messages sent to
+// the log driver plugins.
+var pluginStreamsDir = "/run/docker/logging"
+
+// Capability describes the optional features of a log driver plugin.
+type Capability struct {
+	// ReadLogs is set if the plugin implements /LogDriver.ReadLogs, so
+	// that the logs of the containers can be read back.
+	ReadLogs bool
+}
+
+// logPlugin defines the functions that log driver plugins implement.
+type logPlugin interface {
+	// StartLogging starts sending the messages of the container described
+	// by info to the plugin, through the stream at streamPath.
+	StartLogging(streamPath string, info Context) (err error)
+	// StopLogging stops sending messages through the stream at streamPath.
+	StopLogging(streamPath string) (err error)
+	// Capabilities returns the optional features of the plugin.
+	Capabilities() (capability Capability, err error)
+	// ReadLogs returns the stream of the messages of the container
+	// described by info.
+	ReadLogs(info Context, config ReadConfig) (stream io.ReadCloser, err error)
+}
+
+// getPlugin returns the creator of the loggers of the log driver plugin
+// `name`. The plugin is not waited for if it cannot be located, so that an
+// unknown driver name is rejected immediately.
+func getPlugin(name string) (Creator, error) {
+	pl, err := plugins.GetNoRetry(name, extName)
+	if err != nil {
+		return nil, err
+	}
+	return makePluginCreator(name, &logPluginProxy{pl.Client}), nil
+}
+
+func makePluginCreator(name string, l logPlugin) Creator {
+	return func(ctx Context) (Logger, error) {
+		if err := os.MkdirAll(pluginStreamsDir, 0700); err != nil {
+			return nil, err
+		}
+		a := &pluginAdapter{
+			driverName: name,
+			plugin:     l,
+			streamPath: filepath.Join(pluginStreamsDir, stringid.GenerateNonCryptoID()),
+			logInfo:    ctx,
+		}
+
+		// A plugin which doesn't implement /LogDriver.Capabilities has
+		// none of the optional features.
+		capability, err := l.Capabilities()
+		if err == nil {
+			a.capability = capability
+		}
+
+		stream, err := openPluginStream(a.streamPath)
+		if err != nil {
+			return nil, fmt.Errorf("error creating the stream of log driver plugin %s: %v", name, err)
+		}
+		a.stream = stream
+		a.enc = logdriver.NewLogEntryEncoder(stream)
+
+		if err := l.StartLogging(a.streamPath, ctx); err != nil {
+			stream.Close()
+			os.Remove(a.streamPath)
+			return nil, fmt.Errorf("error starting log driver plugin %s: %v", name, err)
+		}
+
+		if a.capability.ReadLogs {
+			return &pluginAdapterWithRead{a}, nil
+		}
+		return a, nil
+	}
+}
@@ -0,0 +1,25 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// openPluginStream creates the FIFO through which the messages are sent to a
+// log driver plugin, and opens it for writing.
+//
+// The FIFO is opened for reading as well, so that opening it doesn't block
+// until the plugin opens it, and that the messages are buffered by the kernel
+// until the plugin reads them.
+func openPluginStream(path string) (io.WriteCloser, error) {
+	if err := syscall.Mkfifo(path, 0600); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	return f, nil
+}
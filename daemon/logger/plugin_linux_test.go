@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/plugins/logdriver"
+)
+
+// testLogPlugin is a log driver plugin keeping the messages it reads from
+// its stream in memory.
+type testLogPlugin struct {
+	readLogs bool
+	started  chan string
+	done     chan []logdriver.LogEntry
+	entries  []logdriver.LogEntry
+}
+
+func (p *testLogPlugin) StartLogging(streamPath string, info Context) error {
+	f, err := os.Open(streamPath)
+	if err != nil {
+		return err
+	}
+	go func() {
+		defer f.Close()
+		var entries []logdriver.LogEntry
+		dec := logdriver.NewLogEntryDecoder(f)
+		for {
+			var entry logdriver.LogEntry
+			if err := dec.Decode(&entry); err != nil {
+				p.done <- entries
+				return
+			}
+			entries = append(entries, entry)
+		}
+	}()
+	p.started <- info.ContainerID
+	return nil
+}
+
+func (p *testLogPlugin) StopLogging(streamPath string) error {
+	p.entries = <-p.done
+	return nil
+}
+
+func (p *testLogPlugin) Capabilities() (Capability, error) {
+	if !p.readLogs {
+		return Capability{}, errors.New("not implemented")
+	}
+	return Capability{ReadLogs: true}, nil
+}
+
+func (p *testLogPlugin) ReadLogs(info Context, config ReadConfig) (io.ReadCloser, error) {
+	buf := new(bytes.Buffer)
+	enc := logdriver.NewLogEntryEncoder(buf)
+	for i := range p.entries {
+		if err := enc.Encode(&p.entries[i]); err != nil {
+			return nil, err
+		}
+	}
+	return ioutil.NopCloser(buf), nil
+}
+
+func withPluginStreamsDir(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "docker-logger-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := pluginStreamsDir
+	pluginStreamsDir = dir
+	return func() {
+		pluginStreamsDir = previous
+		os.RemoveAll(dir)
+	}
+}
+
+func TestPluginAdapter(t *testing.T) {
+	defer withPluginStreamsDir(t)()
+
+	plugin := &testLogPlugin{readLogs: true, started: make(chan string, 1), done: make(chan []logdriver.LogEntry, 1)}
+	l, err := makePluginCreator("test", plugin)(Context{ContainerID: "container"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := <-plugin.started; id != "container" {
+		t.Fatalf("Expected the plugin to log the container, got %s", id)
+	}
+	if l.Name() != "test" {
+		t.Fatalf("Expected the name of the plugin, got %s", l.Name())
+	}
+	reader, ok := l.(LogReader)
+	if !ok {
+		t.Fatal("Expected the logger to read the logs of a plugin with the ReadLogs capability")
+	}
+
+	now := time.Now().UTC()
+	messages := []*Message{
+		{Source: "stdout", Line: []byte("foo"), Timestamp: now},
+		{Source: "stderr", Line: []byte("bar"), Timestamp: now.Add(time.Second)},
+	}
+	for _, msg := range messages {
+		if err := l.Log(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(plugin.entries) != len(messages) {
+		t.Fatalf("Expected the plugin to read %d messages, got %v", len(messages), plugin.entries)
+	}
+	files, err := ioutil.ReadDir(pluginStreamsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Fatalf("Expected the stream to be removed, got %v", files)
+	}
+
+	watcher := reader.ReadLogs(ReadConfig{Tail: -1})
+	for _, expected := range messages {
+		msg, ok := <-watcher.Msg
+		if !ok {
+			t.Fatalf("Expected the message %s, got the end of the logs", expected.Line)
+		}
+		if msg.ContainerID != "container" || msg.Source != expected.Source || string(msg.Line) != string(expected.Line)+"\n" || !msg.Timestamp.Equal(expected.Timestamp) {
+			t.Fatalf("Expected %v, got %v", expected, msg)
+		}
+	}
+	if msg, ok := <-watcher.Msg; ok {
+		t.Fatalf("Expected the end of the logs, got %v", msg)
+	}
+}
+
+func TestPluginAdapterWithoutReadLogs(t *testing.T) {
+	defer withPluginStreamsDir(t)()
+
+	plugin := &testLogPlugin{started: make(chan string, 1), done: make(chan []logdriver.LogEntry, 1)}
+	l, err := makePluginCreator("test", plugin)(Context{ContainerID: "container"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if _, ok := l.(LogReader); ok {
+		t.Fatal("Expected the logger of a plugin without the ReadLogs capability to not read the logs")
+	}
+}
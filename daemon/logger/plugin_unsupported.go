@@ -0,0 +1,12 @@
+// +build !linux
+
+package logger
+
+import (
+	"errors"
+	"io"
+)
+
+func openPluginStream(path string) (io.WriteCloser, error) {
+	return nil, errors.New("log driver plugins are not supported on this platform")
+}
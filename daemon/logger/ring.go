@@ -0,0 +1,196 @@
+package logger
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const defaultRingMaxSize = 1e6 // 1MB
+
+var errRingClosed = errors.New("closed")
+
+// RingLogger is a Logger buffering the messages in memory, and sending them
+// to another Logger from a goroutine, so that a container doesn't block when
+// its logging driver is slow or stalled. The messages logged while the buffer
+// is full are dropped.
+type RingLogger struct {
+	buffer  *messageRing
+	l       Logger
+	dropped int64
+	done    chan struct{}
+}
+
+type ringWithReader struct {
+	*RingLogger
+}
+
+func (r *ringWithReader) ReadLogs(cfg ReadConfig) *LogWatcher {
+	reader, ok := r.l.(LogReader)
+	if !ok {
+		// something is wrong if we get here
+		panic("expected log reader")
+	}
+	return reader.ReadLogs(cfg)
+}
+
+// NewRingLogger returns a RingLogger sending the messages to the Logger
+// driver, with a buffer of maxSize bytes of messages. The default size is
+// used if maxSize is negative. The returned Logger is a LogReader if the
+// driver is.
+func NewRingLogger(driver Logger, maxSize int64) Logger {
+	if maxSize < 0 {
+		maxSize = defaultRingMaxSize
+	}
+	r := &RingLogger{
+		buffer: newRing(maxSize),
+		l:      driver,
+		done:   make(chan struct{}),
+	}
+	go r.run()
+	if _, ok := driver.(LogReader); ok {
+		return &ringWithReader{r}
+	}
+	return r
+}
+
+// Log queues the message to be sent to the logging driver, or drops it if
+// the buffer is full.
+func (r *RingLogger) Log(msg *Message) error {
+	if err := r.buffer.Enqueue(msg); err != nil {
+		if err == errRingClosed {
+			return err
+		}
+		atomic.AddInt64(&r.dropped, 1)
+	}
+	return nil
+}
+
+// Name returns the name of the logging driver.
+func (r *RingLogger) Name() string {
+	return r.l.Name()
+}
+
+// Dropped returns the number of messages which were dropped because the
+// buffer was full.
+func (r *RingLogger) Dropped() int64 {
+	return atomic.LoadInt64(&r.dropped)
+}
+
+// Close sends the messages left in the buffer to the logging driver, and
+// closes it.
+func (r *RingLogger) Close() error {
+	r.buffer.Close()
+	<-r.done
+	for _, msg := range r.buffer.Drain() {
+		if err := r.l.Log(msg); err != nil {
+			logrus.Debugf("Error writing log message for logger %s: %v", r.l.Name(), err)
+			break
+		}
+	}
+	return r.l.Close()
+}
+
+// run sends the messages of the buffer to the logging driver until the buffer
+// is closed.
+func (r *RingLogger) run() {
+	defer close(r.done)
+	for {
+		msg, err := r.buffer.Dequeue()
+		if err != nil {
+			return
+		}
+		if err := r.l.Log(msg); err != nil {
+			logrus.Errorf("Failed to log msg %q for logger %s: %s", msg.Line, r.l.Name(), err)
+		}
+	}
+}
+
+// DroppedMessages returns the number of messages dropped by the Logger l,
+// which is only the case of a RingLogger.
+func DroppedMessages(l Logger) int64 {
+	switch r := l.(type) {
+	case *RingLogger:
+		return r.Dropped()
+	case *ringWithReader:
+		return r.Dropped()
+	}
+	return 0
+}
+
+// messageRing is a queue of messages holding up to maxBytes bytes of lines.
+type messageRing struct {
+	mu sync.Mutex
+	// signals the readers when a message is queued, or the ring is closed
+	wait *sync.Cond
+
+	sizeBytes int64
+	maxBytes  int64
+	queue     []*Message
+	closed    bool
+}
+
+var errRingFull = errors.New("full")
+
+func newRing(maxBytes int64) *messageRing {
+	r := &messageRing{maxBytes: maxBytes}
+	r.wait = sync.NewCond(&r.mu)
+	return r
+}
+
+// Enqueue adds a message to the queue, unless it is full. A message is
+// always queued when the queue is empty, whatever its size.
+func (r *messageRing) Enqueue(m *Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return errRingClosed
+	}
+	size := int64(len(m.Line))
+	if r.sizeBytes+size > r.maxBytes && len(r.queue) > 0 {
+		return errRingFull
+	}
+	r.queue = append(r.queue, m)
+	r.sizeBytes += size
+	r.wait.Signal()
+	return nil
+}
+
+// Dequeue removes the first message of the queue, waiting for one if the
+// queue is empty. It returns an error once the ring is closed.
+func (r *messageRing) Dequeue() (*Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for len(r.queue) == 0 && !r.closed {
+		r.wait.Wait()
+	}
+	if r.closed {
+		return nil, errRingClosed
+	}
+	msg := r.queue[0]
+	r.queue[0] = nil
+	r.queue = r.queue[1:]
+	r.sizeBytes -= int64(len(msg.Line))
+	return msg, nil
+}
+
+// Close closes the ring, so that no message is queued anymore, and the
+// waiting readers return.
+func (r *messageRing) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	r.wait.Broadcast()
+}
+
+// Drain removes all the messages of the queue and returns them.
+func (r *messageRing) Drain() []*Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	msgs := r.queue
+	r.queue = nil
+	r.sizeBytes = 0
+	return msgs
+}
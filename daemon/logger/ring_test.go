@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// blockingLogger records the messages it logs, once it is unblocked.
+type blockingLogger struct {
+	unblock chan struct{}
+
+	mu     sync.Mutex
+	lines  []string
+	closed bool
+}
+
+func (l *blockingLogger) Log(m *Message) error {
+	<-l.unblock
+	l.mu.Lock()
+	l.lines = append(l.lines, string(m.Line))
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *blockingLogger) Close() error {
+	l.closed = true
+	return nil
+}
+
+func (l *blockingLogger) Name() string { return "blocking" }
+
+func TestRingLoggerDropsMessagesWhenFull(t *testing.T) {
+	driver := &blockingLogger{unblock: make(chan struct{})}
+	l := NewRingLogger(driver, 10)
+
+	for i := 0; i < 100; i++ {
+		if err := l.Log(&Message{Line: []byte(strconv.Itoa(i % 10))}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dropped := DroppedMessages(l)
+	// the driver holds one message, and the buffer up to 10
+	if dropped < 89 || dropped > 90 {
+		t.Fatalf("Expected 89 or 90 dropped messages, got %d", dropped)
+	}
+
+	close(driver.unblock)
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !driver.closed {
+		t.Fatal("Expected the driver to be closed")
+	}
+	if int64(len(driver.lines))+dropped != 100 {
+		t.Fatalf("Expected the messages which were not dropped to be logged, got %d logged and %d dropped", len(driver.lines), dropped)
+	}
+	for i, line := range driver.lines {
+		if line != strconv.Itoa(i) {
+			t.Fatalf("Expected the messages to be logged in order, got %v", driver.lines)
+		}
+	}
+	if err := l.Log(&Message{Line: []byte("closed")}); err == nil {
+		t.Fatal("Expected an error logging to a closed logger")
+	}
+}
+
+func TestRingLoggerLargeMessage(t *testing.T) {
+	driver := &blockingLogger{unblock: make(chan struct{})}
+	close(driver.unblock)
+	l := NewRingLogger(driver, 1)
+
+	// a message larger than the buffer is queued when the buffer is empty
+	if err := l.Log(&Message{Line: []byte("larger than the buffer")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(driver.lines) != 1 || DroppedMessages(l) != 0 {
+		t.Fatalf("Expected the message to be logged, got %v", driver.lines)
+	}
+}
+
+func TestRingLoggerReader(t *testing.T) {
+	l := NewRingLogger(&TestLoggerText{}, -1)
+	if _, ok := l.(LogReader); ok {
+		t.Fatal("Expected the logger not to be a LogReader")
+	}
+	l.Close()
+	if DroppedMessages(&TestLoggerText{}) != 0 {
+		t.Fatal("Expected no dropped messages for a blocking logger")
+	}
+}
+
+func TestValidateLogOptsMode(t *testing.T) {
+	for _, cfg := range []map[string]string{
+		{},
+		{ModeOpt: ModeBlocking},
+		{ModeOpt: ModeNonBlocking},
+		{ModeOpt: ModeNonBlocking, MaxBufferSizeOpt: "4m"},
+	} {
+		if err := ValidateLogOpts("none", cfg); err != nil {
+			t.Fatalf("Unexpected error for %v: %v", cfg, err)
+		}
+	}
+	for _, cfg := range []map[string]string{
+		{ModeOpt: "unknown"},
+		{MaxBufferSizeOpt: "4m"},
+		{ModeOpt: ModeBlocking, MaxBufferSizeOpt: "4m"},
+		{ModeOpt: ModeNonBlocking, MaxBufferSizeOpt: "four"},
+	} {
+		if err := ValidateLogOpts("none", cfg); err == nil {
+			t.Fatalf("Expected an error for %v", cfg)
+		}
+	}
+}
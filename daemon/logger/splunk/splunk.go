@@ -42,6 +42,7 @@ type splunkLogger struct {
 	url         string
 	auth        string
 	nullMessage *splunkMessage
+	partial     logger.PartialJoiner
 }
 
 type splunkMessage struct {
@@ -154,7 +155,16 @@ func New(ctx logger.Context) (logger.Logger, error) {
 	return logger, nil
 }
 
+// Log sends the message to Splunk, once the long lines split in partial
+// messages are joined.
 func (l *splunkLogger) Log(msg *logger.Message) error {
+	if msg = l.partial.Join(msg); msg == nil {
+		return nil
+	}
+	return l.send(msg)
+}
+
+func (l *splunkLogger) send(msg *logger.Message) error {
 	// Construct message as a copy of nullMessage
 	message := *l.nullMessage
 	message.Time = fmt.Sprintf("%f", float64(msg.Timestamp.UnixNano())/1000000000)
@@ -190,6 +200,11 @@ func (l *splunkLogger) Log(msg *logger.Message) error {
 }
 
 func (l *splunkLogger) Close() error {
+	for _, msg := range l.partial.Flush() {
+		if err := l.send(msg); err != nil {
+			logrus.Errorf("Failed to log msg %q for logger %s: %s", msg.Line, driverName, err)
+		}
+	}
 	l.transport.CloseIdleConnections()
 	return nil
 }
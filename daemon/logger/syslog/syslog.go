@@ -46,7 +46,8 @@ var facilities = map[string]syslog.Priority{
 }
 
 type syslogger struct {
-	writer *syslog.Writer
+	writer  *syslog.Writer
+	partial logger.PartialJoiner
 }
 
 func init() {
@@ -92,7 +93,16 @@ func New(ctx logger.Context) (logger.Logger, error) {
 	}, nil
 }
 
+// Log sends the message to syslog, once the long lines split in partial
+// messages are joined.
 func (s *syslogger) Log(msg *logger.Message) error {
+	if msg = s.partial.Join(msg); msg == nil {
+		return nil
+	}
+	return s.send(msg)
+}
+
+func (s *syslogger) send(msg *logger.Message) error {
 	if msg.Source == "stderr" {
 		return s.writer.Err(string(msg.Line))
 	}
@@ -100,6 +110,11 @@ func (s *syslogger) Log(msg *logger.Message) error {
 }
 
 func (s *syslogger) Close() error {
+	for _, msg := range s.partial.Flush() {
+		if err := s.send(msg); err != nil {
+			logrus.Errorf("Failed to log msg %q for logger %s: %s", msg.Line, name, err)
+		}
+	}
 	return s.writer.Close()
 }
 
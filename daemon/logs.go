@@ -8,6 +8,7 @@ import (
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/daemon/logger"
 	"github.com/docker/docker/daemon/logger/jsonfilelog"
+	"github.com/docker/docker/daemon/logger/local"
 	derr "github.com/docker/docker/errors"
 	"github.com/docker/docker/pkg/stdcopy"
 )
@@ -49,10 +50,13 @@ func (daemon *Daemon) ContainerLogs(containerName string, config *ContainerLogsC
 	}
 	config.OutStream = outStream
 
-	cLog, err := daemon.getLogger(container)
+	cLog, created, err := daemon.getLogger(container)
 	if err != nil {
 		return err
 	}
+	if created {
+		defer cLog.Close()
+	}
 	logReader, ok := cLog.(logger.LogReader)
 	if !ok {
 		return logger.ErrReadLogsNotSupported
@@ -99,15 +103,25 @@ func (daemon *Daemon) ContainerLogs(containerName string, config *ContainerLogsC
 	}
 }
 
-func (daemon *Daemon) getLogger(container *Container) (logger.Logger, error) {
+// getLogger returns the logger of the container, to read its logs. If the
+// container is not running, a logger is created, which the caller has to
+// close. The local cache of the logs is opened directly if there is one, so
+// that the logging driver is not created.
+func (daemon *Daemon) getLogger(container *Container) (l logger.Logger, created bool, err error) {
 	if container.logDriver != nil && container.IsRunning() {
-		return container.logDriver, nil
+		return container.logDriver, false, nil
 	}
 	cfg := container.getLogConfig(daemon.defaultLogConfig)
 	if err := logger.ValidateLogOpts(cfg.Type, cfg.Config); err != nil {
-		return nil, err
+		return nil, false, err
+	}
+	if cfg.Type != jsonfilelog.Name && cfg.Type != local.Name {
+		if l, err = container.openLogCache(cfg); l != nil || err != nil {
+			return l, err == nil, err
+		}
 	}
-	return container.StartLogger(cfg)
+	l, err = container.StartLogger(cfg)
+	return l, err == nil, err
 }
 
 // StartLogging initializes and starts the container logging stream.
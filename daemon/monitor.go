@@ -3,6 +3,7 @@ package daemon
 import (
 	"io"
 	"os/exec"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/daemon/logger"
 	derr "github.com/docker/docker/errors"
 	"github.com/docker/docker/pkg/stringid"
 	"github.com/docker/docker/runconfig"
@@ -25,14 +27,23 @@ const (
 type containerSupervisor interface {
 	// LogContainerEvent generates events related to a given container
 	LogContainerEvent(*Container, string)
+	// LogContainerEventWithAttributes generates events related to a given
+	// container with specific attributes
+	LogContainerEventWithAttributes(*Container, string, map[string]string)
 	// Cleanup ensures that the container is properly unmounted
 	Cleanup(*Container)
 	// StartLogging starts the logging driver for the container
 	StartLogging(*Container) error
 	// Run starts a container
 	Run(c *Container, pipes *execdriver.Pipes, startCallback execdriver.DriverCallback) (execdriver.ExitStatus, error)
+	// Restore reattaches to a container which kept running while the daemon was down
+	Restore(c *Container, pipes *execdriver.Pipes, startCallback execdriver.DriverCallback) (execdriver.ExitStatus, error)
 	// IsShuttingDown tells whether the supervisor is shutting down or not
 	IsShuttingDown() bool
+	// initHealthMonitor starts the healthcheck monitor of the container
+	initHealthMonitor(*Container)
+	// stopHealthchecks stops the healthcheck monitor of the container
+	stopHealthchecks(*Container)
 }
 
 // containerMonitor monitors the execution of a container's main process.
@@ -74,6 +85,15 @@ type containerMonitor struct {
 
 	// lastStartTime is the time which the monitor last exec'd the container's process
 	lastStartTime time.Time
+
+	// restoring is set while the monitor reattaches to a process started
+	// by a previous instance of the daemon
+	restoring bool
+
+	// restarted is set once the monitor restarted the container. The lock
+	// of the container is held by the caller of Start during the initial
+	// start only, so the callback has to take it after a restart.
+	restarted bool
 }
 
 // newContainerMonitor returns an initialized containerMonitor for the provided container
@@ -124,6 +144,16 @@ func (m *containerMonitor) Close() error {
 
 // Start starts the containers process and monitors it according to the restart policy
 func (m *containerMonitor) Start() error {
+	return m.start(false)
+}
+
+// Restore reattaches to the process of a container which kept running while
+// the daemon was down, and monitors it according to the restart policy
+func (m *containerMonitor) Restore() error {
+	return m.start(true)
+}
+
+func (m *containerMonitor) start(restore bool) error {
 	var (
 		err        error
 		exitStatus execdriver.ExitStatus
@@ -146,11 +176,16 @@ func (m *containerMonitor) Start() error {
 		m.container.HasBeenManuallyStopped = false
 	}
 
-	// reset the restart count
-	m.container.RestartCount = -1
+	// reset the restart count, unless the container is only being reattached
+	if !restore {
+		m.container.RestartCount = -1
+	}
+	m.restoring = restore
 
 	for {
-		m.container.RestartCount++
+		if !m.restoring {
+			m.container.RestartCount++
+		}
 
 		if err := m.supervisor.StartLogging(m.container); err != nil {
 			m.resetContainer(false)
@@ -160,11 +195,25 @@ func (m *containerMonitor) Start() error {
 
 		pipes := execdriver.NewPipes(m.container.Stdin(), m.container.Stdout(), m.container.Stderr(), m.container.Config.OpenStdin)
 
-		m.logEvent("start")
+		run := m.supervisor.Run
+		if m.restoring {
+			run = m.supervisor.Restore
+		} else {
+			m.logEvent("start")
+		}
 
 		m.lastStartTime = time.Now()
 
-		if exitStatus, err = m.supervisor.Run(m.container, pipes, m.callback); err != nil {
+		exitStatus, err = run(m.container, pipes, m.callback)
+		if m.restoring {
+			m.restoring = false
+			// the process of the container exited while the daemon was down
+			if err != nil {
+				m.resetContainer(false)
+				return err
+			}
+		}
+		if err != nil {
 			// if we receive an internal error from the initial start of a container then lets
 			// return it instead of entering the restart loop
 			// set to 127 for container cmd not found/does not exist)
@@ -199,11 +248,15 @@ func (m *containerMonitor) Start() error {
 		// here container.Lock is already lost
 		afterRun = true
 
+		m.container.Lock()
+		m.supervisor.stopHealthchecks(m.container)
+		m.container.Unlock()
+
 		m.resetMonitor(err == nil && exitStatus.ExitCode == 0)
 
 		if m.shouldRestart(exitStatus.ExitCode) {
 			m.container.setRestarting(&exitStatus)
-			m.logEvent("die")
+			m.logDieEvent(exitStatus.ExitCode)
 			m.resetContainer(true)
 
 			// sleep with a small time increment between each restart to help avoid issues cased by quickly
@@ -215,10 +268,11 @@ func (m *containerMonitor) Start() error {
 			if m.shouldStop {
 				return err
 			}
+			m.restarted = true
 			continue
 		}
 
-		m.logEvent("die")
+		m.logDieEvent(exitStatus.ExitCode)
 		m.resetContainer(true)
 		return err
 	}
@@ -303,7 +357,17 @@ func (m *containerMonitor) callback(processConfig *execdriver.ProcessConfig, pid
 		}
 	}
 
-	m.container.setRunning(pid)
+	if m.restarted {
+		m.container.Lock()
+	}
+	// a restored container keeps the state it was started with
+	if !m.restoring {
+		m.container.setRunning(pid)
+	}
+	m.supervisor.initHealthMonitor(m.container)
+	if m.restarted {
+		m.container.Unlock()
+	}
 
 	// signal that the process has started
 	// close channel only if not closed
@@ -358,6 +422,7 @@ func (m *containerMonitor) resetContainer(lock bool) {
 			}
 		}
 		container.logDriver.Close()
+		container.LogDroppedMessages += logger.DroppedMessages(container.logDriver)
 		container.logCopier = nil
 		container.logDriver = nil
 	}
@@ -380,3 +445,10 @@ func (m *containerMonitor) resetContainer(lock bool) {
 func (m *containerMonitor) logEvent(action string) {
 	m.supervisor.LogContainerEvent(m.container, action)
 }
+
+func (m *containerMonitor) logDieEvent(exitCode int) {
+	attributes := map[string]string{
+		"exitCode": strconv.Itoa(exitCode),
+	}
+	m.supervisor.LogContainerEventWithAttributes(m.container, "die", attributes)
+}
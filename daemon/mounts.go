@@ -34,9 +34,13 @@ func (daemon *Daemon) removeMountPoints(container *Container, rm bool) error {
 			// not an error, but an implementation detail.
 			// This prevents docker from logging "ERROR: Volume in use"
 			// where there is another container using the volume.
-			if err != nil && !volumestore.IsInUse(err) {
-				rmErrors = append(rmErrors, err.Error())
+			if err != nil {
+				if !volumestore.IsInUse(err) {
+					rmErrors = append(rmErrors, err.Error())
+				}
+				continue
 			}
+			daemon.LogVolumeEvent(m.Volume.Name(), "destroy", map[string]string{"driver": m.Volume.DriverName()})
 		}
 	}
 	if len(rmErrors) > 0 {
@@ -101,7 +101,13 @@ func (daemon *Daemon) CreateNetwork(name, driver string, ipam network.IPAM, opti
 
 	nwOptions = append(nwOptions, libnetwork.NetworkOptionIpam(ipam.Driver, "", v4Conf, v6Conf))
 	nwOptions = append(nwOptions, libnetwork.NetworkOptionDriverOpts(options))
-	return c.NewNetwork(driver, name, nwOptions...)
+	n, err := c.NewNetwork(driver, name, nwOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	daemon.LogNetworkEvent(n, "create")
+	return n, nil
 }
 
 func getIpamConfig(data []network.IPAMConfig) ([]*libnetwork.IpamConf, []*libnetwork.IpamConf, error) {
@@ -144,7 +150,26 @@ func (daemon *Daemon) DisconnectContainerFromNetwork(containerName string, netwo
 	if err != nil {
 		return err
 	}
-	return container.DisconnectFromNetwork(network)
+	if err := container.DisconnectFromNetwork(network); err != nil {
+		return err
+	}
+
+	daemon.LogNetworkEventWithAttributes(network, "disconnect", map[string]string{"container": container.ID})
+	return nil
+}
+
+// DeleteNetwork destroys the network with the given name or id.
+func (daemon *Daemon) DeleteNetwork(networkID string) error {
+	nw, err := daemon.FindNetwork(networkID)
+	if err != nil {
+		return err
+	}
+
+	if err := nw.Delete(); err != nil {
+		return err
+	}
+	daemon.LogNetworkEvent(nw, "destroy")
+	return nil
 }
 
 // GetNetworkDriverList returns the list of plugins drivers
@@ -0,0 +1,129 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/discovery"
+)
+
+// Reload applies the options of a configuration reloaded from the
+// configuration file, which can be changed while the daemon is running:
+// - Daemon labels.
+// - Debug mode and log level.
+// - Cluster discovery (store, advertise address and options).
+// - Live restore of the containers.
+// Only the options set in the configuration file are changed.
+func (daemon *Daemon) Reload(config *Config) error {
+	daemon.configStore.reloadLock.Lock()
+	defer daemon.configStore.reloadLock.Unlock()
+
+	debug := daemon.configStore.Debug
+	if config.IsValueSet("debug") {
+		debug = config.Debug
+	}
+	logLevel := daemon.configStore.LogLevel
+	if config.IsValueSet("log-level") {
+		logLevel = config.LogLevel
+	}
+	level, err := parseLogLevel(debug, logLevel)
+	if err != nil {
+		return err
+	}
+
+	if err := daemon.reloadClusterDiscovery(config); err != nil {
+		return err
+	}
+
+	if config.IsValueSet("label") {
+		daemon.configStore.Labels = config.Labels
+	}
+	if config.IsValueSet("live-restore") {
+		daemon.configStore.LiveRestore = config.LiveRestore
+	}
+
+	daemon.configStore.Debug = debug
+	daemon.configStore.LogLevel = logLevel
+	if debug {
+		os.Setenv("DEBUG", "1")
+	} else {
+		os.Unsetenv("DEBUG")
+	}
+	logrus.SetLevel(level)
+
+	daemon.LogDaemonEvent("reload")
+	return nil
+}
+
+// parseLogLevel returns the logging level of the daemon. Debug mode takes
+// precedence over the log level, which defaults to info.
+func parseLogLevel(debug bool, logLevel string) (logrus.Level, error) {
+	if debug {
+		return logrus.DebugLevel, nil
+	}
+	if logLevel == "" {
+		return logrus.InfoLevel, nil
+	}
+	level, err := logrus.ParseLevel(logLevel)
+	if err != nil {
+		return level, fmt.Errorf("Unable to parse logging level: %s", logLevel)
+	}
+	return level, nil
+}
+
+// reloadClusterDiscovery changes the discovery backend and the address the
+// daemon advertises when they are set in the configuration. The cluster store
+// used by multi-host networking is only configured when the daemon starts.
+func (daemon *Daemon) reloadClusterDiscovery(config *Config) error {
+	if !config.IsValueSet("cluster-store") && !config.IsValueSet("cluster-advertise") && !config.IsValueSet("cluster-store-opt") {
+		return nil
+	}
+
+	store := daemon.configStore.ClusterStore
+	if config.IsValueSet("cluster-store") {
+		store = config.ClusterStore
+	}
+	advertise := daemon.configStore.ClusterAdvertise
+	if config.IsValueSet("cluster-advertise") {
+		advertise = config.ClusterAdvertise
+	}
+	clusterOpts := daemon.configStore.ClusterOpts
+	if config.IsValueSet("cluster-store-opt") {
+		clusterOpts = config.ClusterOpts
+	}
+
+	if store == "" || advertise == "" {
+		if advertise != "" {
+			return fmt.Errorf("invalid cluster configuration. --cluster-advertise must be accompanied by --cluster-store configuration")
+		}
+		// Discovery is disabled
+		if daemon.discovery != nil {
+			daemon.discovery.stop()
+		}
+	} else {
+		var err error
+		advertise, err = discovery.ParseAdvertise(store, advertise)
+		if err != nil {
+			return fmt.Errorf("discovery advertise parsing failed (%v)", err)
+		}
+
+		if store != daemon.configStore.ClusterStore || advertise != daemon.configStore.ClusterAdvertise ||
+			!reflect.DeepEqual(clusterOpts, daemon.configStore.ClusterOpts) || daemon.discovery == nil {
+			if daemon.discovery == nil {
+				daemon.discovery, err = initDiscovery(store, advertise, clusterOpts)
+			} else {
+				err = daemon.discovery.reload(store, advertise, clusterOpts)
+			}
+			if err != nil {
+				return fmt.Errorf("discovery initialization failed (%v)", err)
+			}
+		}
+	}
+
+	daemon.configStore.ClusterStore = store
+	daemon.configStore.ClusterAdvertise = advertise
+	daemon.configStore.ClusterOpts = clusterOpts
+	return nil
+}
@@ -0,0 +1,7 @@
+// +build !linux !seccomp
+
+package daemon
+
+// supportsSeccomp is false, since the daemon was built without seccomp
+// support.
+const supportsSeccomp = false
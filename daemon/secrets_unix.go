@@ -0,0 +1,100 @@
+// +build linux freebsd
+
+package daemon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/opencontainers/runc/libcontainer/label"
+)
+
+func (container *Container) secretsPath() (string, error) {
+	return container.getRootResourcePath("secrets")
+}
+
+// setupSecrets writes the secrets of the container to a tmpfs, which is
+// bind-mounted in the container. The secrets never reach the disk.
+func (daemon *Daemon) setupSecrets(container *Container) error {
+	if len(container.secrets) == 0 {
+		return nil
+	}
+	rootUID, rootGID := daemon.GetRemappedUIDGID()
+	secretsPath, err := container.secretsPath()
+	if err != nil {
+		return err
+	}
+	if err := idtools.MkdirAllAs(secretsPath, 0700, rootUID, rootGID); err != nil {
+		return err
+	}
+	if err := syscall.Mount("tmpfs", secretsPath, "tmpfs", uintptr(syscall.MS_NOEXEC|syscall.MS_NOSUID|syscall.MS_NODEV), label.FormatMountLabel("mode=0755", container.getMountLabel())); err != nil {
+		return fmt.Errorf("mounting secrets tmpfs: %s", err)
+	}
+	if err := daemon.writeSecrets(secretsPath, container.secrets, rootUID, rootGID); err != nil {
+		container.unmountSecrets(detachMounted)
+		return err
+	}
+	return nil
+}
+
+func (daemon *Daemon) writeSecrets(dir string, secrets map[string][]byte, rootUID, rootGID int) error {
+	if err := os.Chown(dir, rootUID, rootGID); err != nil {
+		return err
+	}
+	for id, data := range secrets {
+		if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+			return fmt.Errorf("invalid secret ID %q", id)
+		}
+		path := filepath.Join(dir, id)
+		if err := ioutil.WriteFile(path, data, 0400); err != nil {
+			return err
+		}
+		if err := os.Chown(path, rootUID, rootGID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unmountSecrets unmounts and removes the tmpfs of the secrets of the
+// container, if it was mounted.
+func (container *Container) unmountSecrets(unmount func(pth string) error) {
+	secretsPath, err := container.secretsPath()
+	if err != nil {
+		logrus.Error(err)
+		return
+	}
+	if _, err := os.Stat(secretsPath); err != nil {
+		return
+	}
+	if err := unmount(secretsPath); err != nil {
+		logrus.Warnf("failed to umount %s: %v", secretsPath, err)
+	}
+	if err := os.Remove(secretsPath); err != nil && !os.IsNotExist(err) {
+		logrus.Warnf("failed to remove %s: %v", secretsPath, err)
+	}
+}
+
+func (container *Container) secretsMounts() []execdriver.Mount {
+	if len(container.secrets) == 0 {
+		return nil
+	}
+	secretsPath, err := container.secretsPath()
+	if err != nil {
+		logrus.Error(err)
+		return nil
+	}
+	return []execdriver.Mount{{
+		Source:      secretsPath,
+		Destination: secretsMountPath,
+		Writable:    false,
+		Private:     true,
+	}}
+}
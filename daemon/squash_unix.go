@@ -0,0 +1,166 @@
+// +build !windows
+
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/docker/pkg/stringid"
+)
+
+// SquashImage creates a new image from the image `id`, in which all the
+// layers added on top of the image `parent` are squashed into a single layer.
+// The configuration and the history of the image are preserved. If `parent`
+// is empty, all the layers of the image are squashed.
+func (daemon *Daemon) SquashImage(id, parent string) (string, error) {
+	img, err := daemon.imageStore.Get(image.ID(id))
+	if err != nil {
+		return "", err
+	}
+
+	rootFS := image.NewRootFS()
+	var parentHistory int
+	if parent != "" {
+		parentImg, err := daemon.imageStore.Get(image.ID(parent))
+		if err != nil {
+			return "", err
+		}
+		if !isLayerPrefix(parentImg.RootFS.DiffIDs, img.RootFS.DiffIDs) {
+			return "", fmt.Errorf("image %s is not based on image %s", id, parent)
+		}
+		rootFS.DiffIDs = append(rootFS.DiffIDs, parentImg.RootFS.DiffIDs...)
+		parentHistory = len(parentImg.History)
+	}
+
+	diff, err := daemon.diffLayers(img.RootFS.ChainID(), rootFS.ChainID())
+	if err != nil {
+		return "", err
+	}
+	defer diff.Close()
+
+	l, err := daemon.layerStore.Register(diff, rootFS.ChainID())
+	if err != nil {
+		return "", err
+	}
+	defer layer.ReleaseAndLog(daemon.layerStore, l)
+
+	// The history of the squashed layers is kept, but all of them are now
+	// empty and the new layer is added with an entry of its own.
+	history := make([]image.History, len(img.History))
+	copy(history, img.History)
+	for i := parentHistory; i < len(history); i++ {
+		history[i].EmptyLayer = true
+	}
+	h := image.History{
+		Created:    time.Now().UTC(),
+		Comment:    fmt.Sprintf("merge %s to %s", id, parent),
+		EmptyLayer: true,
+	}
+	if parent == "" {
+		h.Comment = fmt.Sprintf("merge %s", id)
+	}
+	if diffID := l.DiffID(); layer.DigestSHA256EmptyTar != diffID {
+		h.EmptyLayer = false
+		rootFS.Append(diffID)
+	}
+	history = append(history, h)
+
+	config, err := json.Marshal(&image.Image{
+		V1Image: img.V1Image,
+		RootFS:  rootFS,
+		History: history,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	newID, err := daemon.imageStore.Create(config)
+	if err != nil {
+		return "", err
+	}
+	if parent != "" {
+		if err := daemon.imageStore.SetParent(newID, image.ID(parent)); err != nil {
+			return "", err
+		}
+	}
+	return newID.String(), nil
+}
+
+// diffLayers returns a tar stream of the changes between the filesystems of
+// the layer chains `top` and `base`, where `base` can be empty.
+func (daemon *Daemon) diffLayers(top, base layer.ChainID) (archive.Archive, error) {
+	topName := stringid.GenerateNonCryptoID()
+	topLayer, err := daemon.layerStore.Mount(topName, top, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	baseName := stringid.GenerateNonCryptoID()
+	baseLayer, err := daemon.layerStore.Mount(baseName, base, "", nil)
+	if err != nil {
+		daemon.releaseSquashMount(topName)
+		return nil, err
+	}
+	release := func() error {
+		daemon.releaseSquashMount(topName)
+		daemon.releaseSquashMount(baseName)
+		return nil
+	}
+
+	topPath, err := topLayer.Path()
+	if err != nil {
+		release()
+		return nil, err
+	}
+	basePath, err := baseLayer.Path()
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	changes, err := archive.ChangesDirs(topPath, basePath)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	diff, err := archive.ExportChanges(topPath, changes, daemon.uidMaps, daemon.gidMaps)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return ioutils.NewReadCloserWrapper(diff, func() error {
+		diff.Close()
+		return release()
+	}), nil
+}
+
+func (daemon *Daemon) releaseSquashMount(name string) {
+	if err := daemon.layerStore.Unmount(name); err != nil {
+		logrus.Errorf("Error unmounting layer %s: %v", name, err)
+	}
+	metadata, err := daemon.layerStore.DeleteMount(name)
+	layer.LogReleaseMetadata(metadata)
+	if err != nil {
+		logrus.Errorf("Error removing layer %s: %v", name, err)
+	}
+}
+
+// isLayerPrefix returns whether the layers `base` are the first layers of
+// `layers`.
+func isLayerPrefix(base, layers []layer.DiffID) bool {
+	if len(base) > len(layers) {
+		return false
+	}
+	for i, diffID := range base {
+		if layers[i] != diffID {
+			return false
+		}
+	}
+	return true
+}
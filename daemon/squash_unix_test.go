@@ -0,0 +1,84 @@
+// +build !windows
+
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/image"
+)
+
+func TestSquashImage(t *testing.T) {
+	daemon, cleanup := newImageStoreTestDaemon(t)
+	defer cleanup()
+
+	base := createTestImage(t, daemon, nil, writeTestFile("base", "base"))
+	img := createTestImage(t, daemon, base,
+		writeTestFile("foo", "foo"),
+		func(root string) error {
+			if err := os.Remove(filepath.Join(root, "foo")); err != nil {
+				return err
+			}
+			return ioutil.WriteFile(filepath.Join(root, "bar"), []byte("bar"), 0644)
+		},
+	)
+
+	id, err := daemon.SquashImage(img.ID().String(), base.ID().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	squashed, err := daemon.imageStore.Get(image.ID(id))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(squashed.RootFS.DiffIDs) != 2 || squashed.RootFS.DiffIDs[0] != base.RootFS.DiffIDs[0] {
+		t.Fatalf("Expected the base layer and a squashed layer, got %v", squashed.RootFS.DiffIDs)
+	}
+	if len(squashed.History) != 4 {
+		t.Fatalf("Expected 4 history entries, got %d", len(squashed.History))
+	}
+	for i, h := range squashed.History {
+		if empty := i == 1 || i == 2; h.EmptyLayer != empty {
+			t.Fatalf("Expected history entry %d to have EmptyLayer=%v", i, empty)
+		}
+	}
+	parent, err := daemon.imageStore.GetParent(squashed.ID())
+	if err != nil || parent != base.ID() {
+		t.Fatalf("Expected the parent of the squashed image to be %s, got %s (%v)", base.ID(), parent, err)
+	}
+
+	rw, err := daemon.layerStore.Mount("squash-check", squashed.RootFS.ChainID(), "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer daemon.releaseSquashMount("squash-check")
+	root, err := rw.Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, expected := range map[string]string{"base": "base", "bar": "bar"} {
+		content, err := ioutil.ReadFile(filepath.Join(root, name))
+		if err != nil || string(content) != expected {
+			t.Fatalf("Expected %s to contain %q, got %q (%v)", name, expected, content, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "foo")); !os.IsNotExist(err) {
+		t.Fatalf("Expected foo to be removed by the squashed layer, got %v", err)
+	}
+}
+
+func TestSquashImageNotBasedOnParent(t *testing.T) {
+	daemon, cleanup := newImageStoreTestDaemon(t)
+	defer cleanup()
+
+	img := createTestImage(t, daemon, nil, writeTestFile("foo", "foo"))
+	other := createTestImage(t, daemon, nil, writeTestFile("bar", "bar"))
+
+	if _, err := daemon.SquashImage(img.ID().String(), other.ID().String()); err == nil {
+		t.Fatal("Expected an error when squashing on top of an image which is not a parent")
+	}
+}
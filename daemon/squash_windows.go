@@ -0,0 +1,8 @@
+package daemon
+
+import "fmt"
+
+// SquashImage is not supported on Windows.
+func (daemon *Daemon) SquashImage(id, parent string) (string, error) {
+	return "", fmt.Errorf("Windows does not support squashing images")
+}
@@ -2,6 +2,7 @@ package daemon
 
 import (
 	"runtime"
+	"strconv"
 
 	"github.com/Sirupsen/logrus"
 	derr "github.com/docker/docker/errors"
@@ -90,7 +91,10 @@ func (daemon *Daemon) containerStart(container *Container) (err error) {
 			}
 			container.toDisk()
 			daemon.Cleanup(container)
-			daemon.LogContainerEvent(container, "die")
+			attributes := map[string]string{
+				"exitCode": strconv.Itoa(container.ExitCode),
+			}
+			daemon.LogContainerEventWithAttributes(container, "die", attributes)
 		}
 	}()
 
@@ -123,11 +127,17 @@ func (daemon *Daemon) containerStart(container *Container) (err error) {
 		}
 	}
 
+	if err := daemon.setupSecrets(container); err != nil {
+		return err
+	}
+
 	mounts, err := daemon.setupMounts(container)
 	if err != nil {
 		return err
 	}
 	mounts = append(mounts, container.ipcMounts()...)
+	mounts = append(mounts, container.tmpfsMounts()...)
+	mounts = append(mounts, container.secretsMounts()...)
 
 	container.command.Mounts = mounts
 	if err := daemon.waitForStart(container); err != nil {
@@ -151,12 +161,61 @@ func (daemon *Daemon) waitForStart(container *Container) error {
 	return nil
 }
 
+// restoreRunningContainer reattaches the daemon to a container which kept
+// running while the daemon was down. The networking of the container is
+// left untouched, its sandbox was restored with the network controller.
+func (daemon *Daemon) restoreRunningContainer(container *Container) (err error) {
+	container.Lock()
+	defer container.Unlock()
+
+	// the monitor cleans up once it started
+	monitorStarted := false
+	defer func() {
+		if err != nil && !monitorStarted {
+			daemon.Cleanup(container)
+		}
+	}()
+
+	if err := daemon.conditionalMountOnStart(container); err != nil {
+		return err
+	}
+
+	container.hostConfig = runconfig.SetDefaultNetModeIfBlank(container.hostConfig)
+
+	if err := daemon.restoreNetworking(container); err != nil {
+		return err
+	}
+	linkedEnv, err := daemon.setupLinkedContainers(container)
+	if err != nil {
+		return err
+	}
+	env := container.createDaemonEnvironment(linkedEnv)
+	if err := daemon.populateCommand(container, env); err != nil {
+		return err
+	}
+
+	container.monitor = daemon.newContainerMonitor(container, container.hostConfig.RestartPolicy)
+	monitorStarted = true
+
+	// block until the monitor is attached to the process of the container
+	// or failed to attach
+	select {
+	case <-container.monitor.startSignal:
+	case err := <-promise.Go(container.monitor.Restore):
+		return err
+	}
+	return nil
+}
+
 // Cleanup releases any network resources allocated to the container along with any rules
 // around how containers are linked together.  It also unmounts the container's root filesystem.
 func (daemon *Daemon) Cleanup(container *Container) {
 	daemon.releaseNetwork(container)
 
 	container.unmountIpcMounts(detachMounted)
+	container.unmountSecrets(detachMounted)
+	// the secrets are only mounted for the first run of the container
+	container.secrets = nil
 
 	daemon.conditionalUnmountOnCleanup(container)
 
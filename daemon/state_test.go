@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/daemon/execdriver"
 )
 
@@ -109,3 +110,23 @@ func TestStateTimeoutWait(t *testing.T) {
 	}
 
 }
+
+func TestStateHealthString(t *testing.T) {
+	s := NewState()
+	if h := s.healthString(); h != types.NoHealthcheck {
+		t.Fatalf("Expected %s without a healthcheck, got %s", types.NoHealthcheck, h)
+	}
+
+	s.setRunning(42)
+	s.Health = &Health{}
+	s.Health.Status = types.Unhealthy
+	if h := s.healthString(); h != types.Unhealthy {
+		t.Fatalf("Expected %s for a running container, got %s", types.Unhealthy, h)
+	}
+
+	// the last health status of a stopped container is ignored
+	s.setStopped(&execdriver.ExitStatus{ExitCode: 1})
+	if h := s.healthString(); h != types.NoHealthcheck {
+		t.Fatalf("Expected %s for a stopped container, got %s", types.NoHealthcheck, h)
+	}
+}
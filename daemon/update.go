@@ -0,0 +1,106 @@
+package daemon
+
+import (
+	derr "github.com/docker/docker/errors"
+	"github.com/docker/docker/runconfig"
+)
+
+// ContainerUpdate updates resources of the container
+func (daemon *Daemon) ContainerUpdate(name string, hostConfig *runconfig.HostConfig) ([]string, error) {
+	var warnings []string
+
+	warnings, err := daemon.verifyContainerSettings(hostConfig, nil)
+	if err != nil {
+		return warnings, err
+	}
+
+	if err := daemon.update(name, hostConfig); err != nil {
+		return warnings, err
+	}
+
+	return warnings, nil
+}
+
+func (daemon *Daemon) update(name string, hostConfig *runconfig.HostConfig) error {
+	if hostConfig == nil {
+		return nil
+	}
+
+	container, err := daemon.Get(name)
+	if err != nil {
+		return err
+	}
+
+	container.Lock()
+	defer container.Unlock()
+
+	if container.removalInProgress || container.Dead {
+		return derr.ErrorCodeCantUpdate.WithArgs(container.ID, "container is marked for removal")
+	}
+
+	// Kernel memory limits can only be set before any task is attached
+	// to the cgroup, so they can't be changed on a running container.
+	if container.Running && hostConfig.KernelMemory != 0 {
+		return derr.ErrorCodeCantUpdate.WithArgs(container.ID, "kernel memory can not be updated on a running container, please stop it first")
+	}
+
+	oldResources := container.hostConfig.Resources
+	container.updateResources(hostConfig.Resources)
+
+	// If the container is not running, updating the hostconfig is enough:
+	// the new resources are applied the next time the container starts.
+	// Otherwise (running or paused), the execdriver command is updated so
+	// the changes are applied to the live cgroups.
+	if container.Running {
+		updateCommandResources(container.command, container.hostConfig)
+		if err := daemon.execDriver.Update(container.command); err != nil {
+			container.hostConfig.Resources = oldResources
+			updateCommandResources(container.command, container.hostConfig)
+			return derr.ErrorCodeCantUpdate.WithArgs(container.ID, err.Error())
+		}
+	}
+
+	if err := container.toDisk(); err != nil {
+		return derr.ErrorCodeCantUpdate.WithArgs(container.ID, err.Error())
+	}
+
+	daemon.LogContainerEvent(container, "update")
+
+	return nil
+}
+
+// updateResources merges the non-zero fields of resources into the
+// hostconfig of the container. Callers must hold the container lock.
+func (container *Container) updateResources(resources runconfig.Resources) {
+	cResources := &container.hostConfig.Resources
+	if resources.BlkioWeight != 0 {
+		cResources.BlkioWeight = resources.BlkioWeight
+	}
+	if resources.CPUShares != 0 {
+		cResources.CPUShares = resources.CPUShares
+	}
+	if resources.CPUPeriod != 0 {
+		cResources.CPUPeriod = resources.CPUPeriod
+	}
+	if resources.CPUQuota != 0 {
+		cResources.CPUQuota = resources.CPUQuota
+	}
+	if resources.CpusetCpus != "" {
+		cResources.CpusetCpus = resources.CpusetCpus
+	}
+	if resources.CpusetMems != "" {
+		cResources.CpusetMems = resources.CpusetMems
+	}
+	if resources.Memory != 0 {
+		cResources.Memory = resources.Memory
+	}
+	if resources.MemorySwap != 0 {
+		cResources.MemorySwap = resources.MemorySwap
+	}
+	if resources.MemoryReservation != 0 {
+		cResources.MemoryReservation = resources.MemoryReservation
+	}
+	if resources.KernelMemory != 0 {
+		cResources.KernelMemory = resources.KernelMemory
+	}
+}
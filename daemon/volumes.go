@@ -33,11 +33,17 @@ func volumeToAPIType(v volume.Volume) *types.Volume {
 
 // createVolume creates a volume.
 func (daemon *Daemon) createVolume(name, driverName string, opts map[string]string) (volume.Volume, error) {
+	_, err := daemon.volumes.Get(name)
+	exists := err == nil
+
 	v, err := daemon.volumes.Create(name, driverName, opts)
 	if err != nil {
 		return nil, err
 	}
 	daemon.volumes.Increment(v)
+	if !exists {
+		daemon.LogVolumeEvent(v.Name(), "create", map[string]string{"driver": v.DriverName()})
+	}
 	return v, nil
 }
 
@@ -123,6 +129,9 @@ func (daemon *Daemon) registerMountPoints(container *Container, hostConfig *runc
 		if binds[bind.Destination] {
 			return derr.ErrorCodeVolumeDup.WithArgs(bind.Destination)
 		}
+		if _, exists := hostConfig.Tmpfs[bind.Destination]; exists {
+			return derr.ErrorCodeVolumeDup.WithArgs(bind.Destination)
+		}
 
 		if len(bind.Name) > 0 && len(bind.Driver) > 0 {
 			// create the volume
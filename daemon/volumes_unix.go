@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"sort"
+	"strconv"
 
 	"github.com/docker/docker/daemon/execdriver"
 	"github.com/docker/docker/pkg/chrootarchive"
@@ -68,6 +69,15 @@ func (daemon *Daemon) setupMounts(container *Container) ([]execdriver.Mount, err
 				Destination: m.Destination,
 				Writable:    m.RW,
 			})
+			if m.Volume != nil {
+				attributes := map[string]string{
+					"driver":      m.Volume.DriverName(),
+					"container":   container.ID,
+					"destination": m.Destination,
+					"read/write":  strconv.FormatBool(m.RW),
+				}
+				daemon.LogVolumeEvent(m.Volume.Name(), "mount", attributes)
+			}
 		}
 	}
 
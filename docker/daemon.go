@@ -30,7 +30,10 @@ import (
 	"github.com/docker/docker/utils"
 )
 
-const daemonUsage = "       docker daemon [ --help | ... ]\n"
+const (
+	daemonUsage          = "       docker daemon [ --help | ... ]\n"
+	daemonConfigFileName = "daemon.json"
+)
 
 var (
 	daemonCli cli.Handler = NewDaemonCli()
@@ -52,10 +55,12 @@ func NewDaemonCli() *DaemonCli {
 	registryOptions := new(registry.Options)
 	registryOptions.InstallFlags(daemonFlags, presentInHelp)
 	registryOptions.InstallFlags(flag.CommandLine, absentFromHelp)
+	configFile := daemonFlags.String([]string{"-config-file"}, filepath.Join(getDaemonConfDir(), daemonConfigFileName), "Daemon configuration file")
 	daemonFlags.Require(flag.Exact, 0)
 
 	return &DaemonCli{
 		Config:          daemonConfig,
+		configFile:      configFile,
 		registryOptions: registryOptions,
 	}
 }
@@ -104,6 +109,7 @@ func migrateKey() (err error) {
 // DaemonCli represents the daemon CLI.
 type DaemonCli struct {
 	*daemon.Config
+	configFile      *string
 	registryOptions *registry.Options
 }
 
@@ -140,7 +146,18 @@ func (cli *DaemonCli) CmdDaemon(args ...string) error {
 	}
 
 	daemonFlags.ParseFlags(args, true)
+
+	// The default configuration file is optional
+	if _, err := os.Stat(*cli.configFile); err == nil || daemonFlags.IsSet("-config-file") {
+		if err := daemon.MergeDaemonConfigurations(daemonFlags, *cli.configFile); err != nil {
+			fmt.Fprintf(os.Stderr, "unable to configure the Docker daemon with file %s: %v\n", *cli.configFile, err)
+			os.Exit(1)
+		}
+	}
+
 	commonFlags.PostParse()
+	cli.Config.Debug = commonFlags.Debug
+	cli.Config.LogLevel = commonFlags.LogLevel
 
 	if commonFlags.TrustKey == "" {
 		commonFlags.TrustKey = filepath.Join(getDaemonConfDir(), defaultTrustKeyFile)
@@ -177,8 +194,9 @@ func (cli *DaemonCli) CmdDaemon(args ...string) error {
 	}
 
 	serverConfig := &apiserver.Config{
-		Logging: true,
-		Version: dockerversion.Version,
+		AuthorizationPluginNames: cli.Config.AuthorizationPlugins,
+		Logging:                  true,
+		Version:                  dockerversion.Version,
 	}
 	serverConfig = setPlatformServerConfig(serverConfig, cli.Config)
 
@@ -259,6 +277,15 @@ func (cli *DaemonCli) CmdDaemon(args ...string) error {
 
 	api.InitRouters(d)
 
+	reload := func(config *daemon.Config) {
+		if err := d.Reload(config); err != nil {
+			logrus.Errorf("Error reconfiguring the daemon: %v", err)
+			return
+		}
+		logrus.Infof("Reloaded the configuration file %s", *cli.configFile)
+	}
+	setupConfigReloadTrap(*cli.configFile, daemonFlags, reload)
+
 	signal.Trap(func() {
 		api.Close()
 		<-serveAPIWait
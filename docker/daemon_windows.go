@@ -7,6 +7,7 @@ import (
 
 	apiserver "github.com/docker/docker/api/server"
 	"github.com/docker/docker/daemon"
+	flag "github.com/docker/docker/pkg/mflag"
 )
 
 func setPlatformServerConfig(serverConfig *apiserver.Config, daemonCfg *daemon.Config) *apiserver.Config {
@@ -31,3 +32,8 @@ func getDaemonConfDir() string {
 // notifySystem sends a message to the host when the server is ready to be used
 func notifySystem() {
 }
+
+// setupConfigReloadTrap doesn't do anything on windows, where the
+// configuration file is only read when the daemon starts
+func setupConfigReloadTrap(configFile string, flags *flag.FlagSet, reload func(*daemon.Config)) {
+}
@@ -82,6 +82,51 @@ var (
 		HTTPStatusCode: http.StatusInternalServerError,
 	})
 
+	// ErrorCodeFromArgs is generated when the parser comes across a FROM
+	// command with the wrong number of args.
+	ErrorCodeFromArgs = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "FROMARGS",
+		Message:        "FROM requires either one argument, or three: FROM <image> AS <name>",
+		Description:    "The FROM command takes an image, optionally followed by the name of the build stage",
+		HTTPStatusCode: http.StatusInternalServerError,
+	})
+
+	// ErrorCodeInvalidStageName is generated when the name of a build
+	// stage is not valid.
+	ErrorCodeInvalidStageName = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "INVALIDSTAGENAME",
+		Message:        "invalid name for build stage: %q, name can't start with a number or contain symbols",
+		Description:    "The name of a build stage must start with a letter and only contain letters, digits, '_', '.' and '-'",
+		HTTPStatusCode: http.StatusInternalServerError,
+	})
+
+	// ErrorCodeDuplicateStageName is generated when two build stages
+	// have the same name.
+	ErrorCodeDuplicateStageName = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "DUPLICATESTAGENAME",
+		Message:        "duplicate name for build stage: %s",
+		Description:    "The name of a build stage must be unique in a Dockerfile",
+		HTTPStatusCode: http.StatusInternalServerError,
+	})
+
+	// ErrorCodeTargetStageNotFound is generated when the build stage given
+	// as the target of the build is not in the Dockerfile.
+	ErrorCodeTargetStageNotFound = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "TARGETSTAGENOTFOUND",
+		Message:        "failed to reach build target %s in Dockerfile",
+		Description:    "The target of the build must be the name of a build stage of the Dockerfile",
+		HTTPStatusCode: http.StatusInternalServerError,
+	})
+
+	// ErrorCodeShellNotJSON is generated when the arguments of the SHELL
+	// command are not in JSON form.
+	ErrorCodeShellNotJSON = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "SHELLNOTJSON",
+		Message:        "SHELL requires the arguments to be in JSON form",
+		Description:    "The SHELL command takes a JSON array of the executable and its parameters",
+		HTTPStatusCode: http.StatusInternalServerError,
+	})
+
 	// ErrorCodeVolumeEmpty is generated when the specified Volume string
 	// is empty.
 	ErrorCodeVolumeEmpty = errcode.Register(errGroup, errcode.ErrorDescriptor{
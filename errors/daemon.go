@@ -635,6 +635,15 @@ var (
 		HTTPStatusCode: http.StatusInternalServerError,
 	})
 
+	// ErrorCodeCantUpdate is generated when we try to update the resources
+	// of a container but failed.
+	ErrorCodeCantUpdate = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "CANTUPDATE",
+		Message:        "Cannot update container %s: %s",
+		Description:    "An error occurred while trying to update the resources of a container",
+		HTTPStatusCode: http.StatusInternalServerError,
+	})
+
 	// ErrorCodePauseError is generated when we try to pause a container
 	// but failed.
 	ErrorCodePauseError = errcode.Register(errGroup, errcode.ErrorDescriptor{
@@ -25,6 +25,15 @@ var (
 		HTTPStatusCode: http.StatusBadRequest,
 	})
 
+	// ErrorCodeAuthorizationDenied is generated when an authorization plugin
+	// denies a request or a response.
+	ErrorCodeAuthorizationDenied = errcode.Register(errGroup, errcode.ErrorDescriptor{
+		Value:          "AUTHORIZATIONDENIED",
+		Message:        "%s",
+		Description:    "An authorization plugin denied the request",
+		HTTPStatusCode: http.StatusForbidden,
+	})
+
 	// ErrorNetworkControllerNotEnabled is generated when the networking stack in not enabled
 	// for certain platforms, like windows.
 	ErrorNetworkControllerNotEnabled = errcode.Register(errGroup, errcode.ErrorDescriptor{
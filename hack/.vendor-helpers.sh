@@ -43,6 +43,15 @@ clone() {
 	echo -n 'rm vendor, '
 	( cd "$target" && rm -rf vendor Godeps/_workspace )
 
+	# local changes which are not in the pinned revision yet, see
+	# hack/vendor-patches/README.md
+	local patch
+	for patch in "hack/vendor-patches/$pkg"/*.patch; do
+		[ -f "$patch" ] || continue
+		echo -n "patch $(basename "$patch"), "
+		git apply -p1 --directory="$target" "$patch"
+	done
+
 	echo done
 }
 
@@ -22,6 +22,7 @@ clone git github.com/vdemeester/shakers 3c10293ce22b900c27acad7b28656196fcc2f73b
 clone git golang.org/x/net 47990a1ba55743e6ef1affd3a14e5bac8553615d https://github.com/golang/net.git
 
 #get libnetwork packages
+# patched with hack/vendor-patches/github.com/docker/libnetwork/live-restore.patch
 clone git github.com/docker/libnetwork b4ddf18317b19d6e4bcc821145589749206a7d00
 clone git github.com/armon/go-metrics eb0af217e5e9747e41dd5303755356b62d28e3ec
 clone git github.com/hashicorp/go-msgpack 71c2886f5a673a35f909803f38ece5810165097b
@@ -51,6 +52,7 @@ clone git github.com/agl/ed25519 d2b94fd789ea21d12fac1a4443dd3a3f79cda72c
 
 # this runc commit from branch relabel_fix_docker_1.9.1, pls remove it when you
 # update next time
+# patched with hack/vendor-patches/github.com/opencontainers/runc/pids-cgroup.patch
 clone git github.com/opencontainers/runc 1349b37bd56f4f5ce2690b5b2c0f53f88a261c67 # libcontainer
 # libcontainer deps (see src/github.com/opencontainers/runc/Godeps/Godeps.json)
 clone git github.com/coreos/go-systemd v4
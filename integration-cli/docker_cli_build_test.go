@@ -19,9 +19,11 @@ import (
 	"text/template"
 	"time"
 
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/builder/dockerfile/command"
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/pkg/stringutils"
 	"github.com/go-check/check"
 )
@@ -6552,3 +6554,445 @@ func (s *DockerSuite) TestBuildCacheRootSource(c *check.C) {
 
 	c.Assert(out, checker.Not(checker.Contains), "Using cache")
 }
+
+func (s *DockerSuite) TestBuildMultiStageCopyFrom(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "testbuildmultistagecopyfrom"
+	ctx, err := fakeContext(`
+	FROM busybox AS build
+	COPY foo /foo
+	RUN echo -n built > /bar
+
+	FROM busybox
+	COPY --from=build /foo /bar /out/
+	COPY --from=0 /bar /bar0
+	CMD cat /out/foo /out/bar /bar0`,
+		map[string]string{
+			"foo": "foo",
+		})
+	c.Assert(err, checker.IsNil)
+	defer ctx.Close()
+
+	id, err := buildImageFromContext(name, ctx, true)
+	c.Assert(err, checker.IsNil)
+
+	out, _ := dockerCmd(c, "run", "--rm", name)
+	c.Assert(out, checker.Equals, "foobuiltbuilt")
+
+	// the files of the first stage are not in the tagged image
+	_, _, err = dockerCmdWithError("run", "--rm", name, "ls", "/foo")
+	c.Assert(err, checker.NotNil)
+
+	// the cache is used for the files copied from a stage
+	id2, err := buildImageFromContext(name, ctx, true)
+	c.Assert(err, checker.IsNil)
+	c.Assert(id2, checker.Equals, id)
+}
+
+func (s *DockerSuite) TestBuildMultiStageFromStage(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "testbuildmultistagefromstage"
+	_, err := buildImage(name, `
+	FROM busybox AS base
+	RUN echo -n base > /base
+	LABEL stage=base
+
+	FROM base
+	RUN echo -n next > /next`, true)
+	c.Assert(err, checker.IsNil)
+
+	out, _ := dockerCmd(c, "run", "--rm", name, "cat", "/base", "/next")
+	c.Assert(out, checker.Equals, "basenext")
+	label, err := inspectField(name, "Config.Labels.stage")
+	c.Assert(err, checker.IsNil)
+	c.Assert(label, checker.Equals, "base")
+}
+
+func (s *DockerSuite) TestBuildCopyFromImage(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "testbuildcopyfromimage"
+	_, err := buildImage(name, `
+	FROM scratch
+	COPY --from=busybox /bin/busybox /busybox
+	CMD ["/busybox", "echo", "hello"]`, true)
+	c.Assert(err, checker.IsNil)
+
+	out, _ := dockerCmd(c, "run", "--rm", name)
+	c.Assert(strings.TrimSpace(out), checker.Equals, "hello")
+}
+
+func (s *DockerSuite) TestBuildMultiStageInvalid(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "testbuildmultistageinvalid"
+	for dockerfile, expected := range map[string]string{
+		"FROM busybox AS":                              "FROM requires either one argument, or three",
+		"FROM busybox FROM build":                      "FROM requires either one argument, or three",
+		"FROM busybox AS 1build":                       "invalid name for build stage",
+		"FROM busybox AS build\nFROM busybox AS BUILD": "duplicate name for build stage",
+		"FROM busybox AS build\nCOPY --from=build / /": "can't copy files from itself",
+		"FROM busybox\nCOPY --from=1 /bin/sh /bin/sh":  "Invalid build stage index 1",
+	} {
+		_, out, err := buildImageWithOut(name, dockerfile, true)
+		c.Assert(err, checker.NotNil, check.Commentf("%s", dockerfile))
+		c.Assert(out, checker.Contains, expected, check.Commentf("%s", dockerfile))
+	}
+}
+
+func (s *DockerSuite) TestBuildMultiStageTarget(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "testbuildmultistagetarget"
+	dockerfile := `
+	FROM busybox AS build
+	RUN echo -n build > /build
+
+	FROM busybox AS test
+	COPY --from=build /build /build
+	RUN echo -n test > /test
+
+	FROM busybox
+	RUN false`
+
+	_, out, err := buildImageWithOut(name, dockerfile, true, "--target", "TEST")
+	c.Assert(err, checker.IsNil, check.Commentf("%s", out))
+	c.Assert(out, checker.Not(checker.Contains), "RUN false")
+
+	out, _ = dockerCmd(c, "run", "--rm", name, "cat", "/build", "/test")
+	c.Assert(out, checker.Equals, "buildtest")
+
+	_, out, err = buildImageWithOut(name, dockerfile, true, "--target", "nosuchstage")
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "failed to reach build target nosuchstage in Dockerfile")
+}
+
+func (s *DockerSuite) TestBuildIidfile(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "testbuildiidfile"
+	iidfile := filepath.Join(c.MkDir(), "iid")
+
+	_, err := buildImage(name, `
+	FROM busybox
+	LABEL foo=bar`, true, "--iidfile", iidfile)
+	c.Assert(err, checker.IsNil)
+
+	id, err := inspectField(name, "Id")
+	c.Assert(err, checker.IsNil)
+	content, err := ioutil.ReadFile(iidfile)
+	c.Assert(err, checker.IsNil)
+	c.Assert(string(content), checker.Equals, id)
+
+	// the file of a previous build is removed when a build fails
+	_, err = buildImage(name, `
+	FROM busybox
+	RUN false`, true, "--iidfile", iidfile)
+	c.Assert(err, checker.NotNil)
+	_, err = os.Stat(iidfile)
+	c.Assert(os.IsNotExist(err), checker.True)
+}
+
+func (s *DockerSuite) TestBuildSquash(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "testbuildsquash"
+	dockerfile := `
+	FROM busybox
+	RUN echo -n foo > /foo
+	RUN rm /foo && echo -n bar > /bar
+	CMD cat /bar`
+
+	id, err := buildImage(name, dockerfile, true)
+	c.Assert(err, checker.IsNil)
+
+	squashedID, out, err := buildImageWithOut(name, dockerfile, true, "--squash")
+	c.Assert(err, checker.IsNil)
+	c.Assert(squashedID, checker.Not(checker.Equals), id)
+	// the intermediate images are still used as cache
+	c.Assert(out, checker.Contains, "Using cache")
+
+	out, _ = dockerCmd(c, "run", "--rm", name)
+	c.Assert(out, checker.Equals, "bar")
+	_, _, err = dockerCmdWithError("run", "--rm", name, "ls", "/foo")
+	c.Assert(err, checker.NotNil)
+
+	// the history is kept, with an entry for the squashed layer
+	out, _ = dockerCmd(c, "history", "--no-trunc", name)
+	c.Assert(out, checker.Contains, "merge "+id)
+	c.Assert(out, checker.Contains, "rm /foo")
+}
+
+func (s *DockerSuite) TestBuildCacheFrom(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "testbuildcachefrom"
+	dockerfile := `
+	FROM busybox
+	ENV FOO=bar
+	RUN echo -n foo > /foo
+	RUN echo -n bar > /bar
+	CMD cat /foo /bar`
+
+	id, err := buildImage(name, dockerfile, true)
+	c.Assert(err, checker.IsNil)
+
+	// saving and loading the image drops its intermediate images, like a pull
+	tmpDir, err := ioutil.TempDir("", "cache-from")
+	c.Assert(err, checker.IsNil)
+	defer os.RemoveAll(tmpDir)
+	tarPath := filepath.Join(tmpDir, "image.tar")
+	dockerCmd(c, "save", "-o", tarPath, name)
+	deleteImages(name)
+	dockerCmd(c, "load", "-i", tarPath)
+
+	cachedID, out, err := buildImageWithOut(name+"-cached", dockerfile, true, "--cache-from", "nosuchimage,"+name)
+	c.Assert(err, checker.IsNil)
+	c.Assert(strings.Count(out, "Using cache"), checker.Equals, 4, check.Commentf(out))
+	c.Assert(out, checker.Contains, "Ignoring cache source nosuchimage")
+	c.Assert(cachedID, checker.Equals, id)
+
+	// the images restored from the cache source are now part of the local cache
+	_, out, err = buildImageWithOut(name+"-nocache", dockerfile+"\nLABEL foo=bar", true)
+	c.Assert(err, checker.IsNil)
+	c.Assert(strings.Count(out, "Using cache"), checker.Equals, 4, check.Commentf(out))
+}
+
+func (s *DockerSuite) TestBuildShell(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "testbuildshell"
+	_, out, err := buildImageWithOut(name, `
+	FROM busybox
+	SHELL ["/bin/sh", "-c", "echo shell: $0"]
+	RUN foo
+	ONBUILD RUN qux
+	CMD bar`, false)
+	c.Assert(err, checker.IsNil)
+	c.Assert(out, checker.Contains, "shell: foo")
+
+	res, err := inspectFieldJSON(name, "Config.Shell")
+	c.Assert(err, checker.IsNil)
+	c.Assert(res, checker.Equals, `["/bin/sh","-c","echo shell: $0"]`)
+	res, err = inspectFieldJSON(name, "Config.Cmd")
+	c.Assert(err, checker.IsNil)
+	c.Assert(res, checker.Equals, `["/bin/sh","-c","echo shell: $0","bar"]`)
+
+	// the shell is inherited by the ONBUILD triggers and the images based on it
+	_, out, err = buildImageWithOut(name+"-child", "FROM "+name+"\nRUN baz", false)
+	c.Assert(err, checker.IsNil)
+	c.Assert(out, checker.Contains, "shell: qux")
+	c.Assert(out, checker.Contains, "shell: baz")
+}
+
+func (s *DockerSuite) TestBuildShellNotJSON(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	_, out, err := buildImageWithOut("testbuildshellnotjson", `
+	FROM busybox
+	SHELL /bin/sh -c`, true)
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "SHELL requires the arguments to be in JSON form")
+}
+
+func (s *DockerSuite) TestBuildNetworkNone(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	_, out, err := buildImageWithOut("testbuildnetworknone", `
+	FROM busybox
+	RUN ls /sys/class/net`, false, "--network", "none")
+	c.Assert(err, checker.IsNil)
+	c.Assert(out, checker.Contains, "lo")
+	c.Assert(out, checker.Not(checker.Contains), "eth0")
+}
+
+func (s *DockerSuite) TestBuildNetworkUserDefined(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dockerCmd(c, "network", "create", "--subnet", "172.28.0.0/16", "testbuildnet")
+	defer dockerCmd(c, "network", "rm", "testbuildnet")
+
+	_, out, err := buildImageWithOut("testbuildnetworkuserdefined", `
+	FROM busybox
+	RUN ip -4 addr show eth0`, false, "--network", "testbuildnet")
+	c.Assert(err, checker.IsNil)
+	c.Assert(out, checker.Contains, "172.28.")
+}
+
+func (s *DockerSuite) TestBuildAddHost(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	_, out, err := buildImageWithOut("testbuildaddhost", `
+	FROM busybox
+	RUN grep mirror.example.com /etc/hosts`, false, "--add-host", "mirror.example.com:10.180.0.1")
+	c.Assert(err, checker.IsNil)
+	c.Assert(out, checker.Contains, "10.180.0.1")
+
+	// the host is not part of the image
+	out, _ = dockerCmd(c, "run", "--rm", "testbuildaddhost", "cat", "/etc/hosts")
+	c.Assert(out, checker.Not(checker.Contains), "mirror.example.com")
+}
+
+func (s *DockerSuite) TestBuildLabel(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "testbuildlabel"
+	dockerfile := `
+	FROM busybox
+	LABEL foo=bar
+	RUN touch /foo`
+
+	_, err := buildImage(name, dockerfile, true, "--label", "com.example.ref=abc", "--label", "foo=baz")
+	c.Assert(err, checker.IsNil)
+	res, err := inspectFieldJSON(name, "Config.Labels")
+	c.Assert(err, checker.IsNil)
+	c.Assert(res, checker.Equals, `{"com.example.ref":"abc","foo":"baz"}`)
+
+	// the labels are added after the last step, which is still cached
+	_, out, err := buildImageWithOut(name, dockerfile, true, "--label", "com.example.ref=def")
+	c.Assert(err, checker.IsNil)
+	c.Assert(strings.Count(out, "Using cache"), checker.Equals, 2, check.Commentf(out))
+	res, err = inspectFieldJSON(name, "Config.Labels")
+	c.Assert(err, checker.IsNil)
+	c.Assert(res, checker.Equals, `{"com.example.ref":"def","foo":"bar"}`)
+
+	// the values are set as is
+	_, err = buildImage(name, dockerfile, true, "--label", `com.example.ref=$HOME\t"x"`)
+	c.Assert(err, checker.IsNil)
+	res, err = inspectFieldJSON(name, "Config.Labels")
+	c.Assert(err, checker.IsNil)
+	c.Assert(res, checker.Equals, `{"com.example.ref":"$HOME\\t\"x\"","foo":"bar"}`)
+}
+
+func (s *DockerSuite) TestBuildSecret(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "testbuildsecret"
+	tmpDir, err := ioutil.TempDir("", "build-secret")
+	c.Assert(err, checker.IsNil)
+	defer os.RemoveAll(tmpDir)
+	secretPath := filepath.Join(tmpDir, "mysecret")
+	c.Assert(ioutil.WriteFile(secretPath, []byte("s3cr3t-value"), 0600), checker.IsNil)
+
+	_, out, err := buildImageWithOut(name, `
+	FROM busybox
+	RUN cat /run/secrets/mysecret /run/secrets/other
+	RUN touch /foo`, false, "--secret", "id=mysecret,src="+secretPath, "--secret", "src="+secretPath+",id=other")
+	c.Assert(err, checker.IsNil)
+	c.Assert(strings.Count(out, "s3cr3t-value"), checker.Equals, 2, check.Commentf(out))
+
+	// the secrets and their mount point are not in the image
+	out, _, err = dockerCmdWithError("run", "--rm", name, "ls", "/run")
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+	out, _ = dockerCmd(c, "history", "--no-trunc", name)
+	c.Assert(out, checker.Not(checker.Contains), "s3cr3t-value")
+	out, _ = dockerCmd(c, "inspect", name)
+	c.Assert(out, checker.Not(checker.Contains), "s3cr3t-value")
+}
+
+func (s *DockerSuite) TestBuildSecretInvalid(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dockerfile := "FROM busybox\nRUN true"
+	_, out, err := buildImageWithOut("testbuildsecretinvalid", dockerfile, false, "--secret", "id=foo")
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "src is required")
+
+	_, out, err = buildImageWithOut("testbuildsecretinvalid", dockerfile, false, "--secret", "id=../foo,src=/etc/hostname")
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "invalid secret ID")
+}
+
+func (s *DockerSuite) TestBuildProgressJSON(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "testbuildprogressjson"
+	dockerfile := `
+	FROM busybox
+	RUN echo hello && echo world
+	RUN false`
+
+	readSteps := func(stdout string) []jsonmessage.JSONBuildStep {
+		var steps []jsonmessage.JSONBuildStep
+		dec := json.NewDecoder(strings.NewReader(stdout))
+		for dec.More() {
+			var step jsonmessage.JSONBuildStep
+			c.Assert(dec.Decode(&step), checker.IsNil, check.Commentf(stdout))
+			steps = append(steps, step)
+		}
+		return steps
+	}
+
+	_, stdout, _, err := buildImageWithStdoutStderr(name, dockerfile, true, "--progress=json")
+	c.Assert(err, checker.NotNil)
+	steps := readSteps(stdout)
+	c.Assert(steps, checker.HasLen, 3, check.Commentf(stdout))
+	c.Assert(steps[0].Index, checker.Equals, 1)
+	c.Assert(steps[0].Total, checker.Equals, 3)
+	c.Assert(steps[0].Instruction, checker.Equals, "FROM busybox")
+	c.Assert(steps[1].Instruction, checker.Equals, "RUN echo hello && echo world")
+	c.Assert(steps[1].Cached, checker.False)
+	c.Assert(steps[1].ImageID, checker.Not(checker.Equals), "")
+	c.Assert(steps[1].Output, checker.DeepEquals, []string{"hello", "world"})
+	c.Assert(steps[2].Error, checker.Contains, "returned a non-zero code")
+	c.Assert(steps[2].ImageID, checker.Equals, "")
+
+	// the steps before the failure are now cached
+	_, stdout, _, _ = buildImageWithStdoutStderr(name, dockerfile, true, "--progress=json")
+	steps = readSteps(stdout)
+	c.Assert(steps, checker.HasLen, 3, check.Commentf(stdout))
+	c.Assert(steps[1].Cached, checker.True)
+	c.Assert(steps[1].Output, checker.IsNil)
+}
+
+func (s *DockerSuite) TestBuildSession(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	name := "testbuildsession"
+	ctx, err := fakeContext(`
+	FROM busybox
+	COPY . /ctx/`, map[string]string{
+		"foo":     "foo",
+		"dir/bar": "bar",
+		"big":     strings.Repeat("big", 1<<20),
+	})
+	c.Assert(err, checker.IsNil)
+	defer ctx.Close()
+	// the files which are older than the newest file of the previous build are
+	// compared with their modification time
+	old := time.Now().Add(-time.Hour)
+	for _, file := range []string{"foo", "dir/bar", "big"} {
+		c.Assert(os.Chtimes(filepath.Join(ctx.Dir, file), old, old), checker.IsNil)
+	}
+
+	_, out, err := buildImageFromContextWithOut(name, ctx, true, "--session", name)
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Matches, `(?s).*Sending build context to Docker daemon 3\.\d+ MB.*`)
+
+	status, body, err := sockRequest("GET", "/build/sessions/"+name, nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusOK)
+	var session types.BuildSession
+	c.Assert(json.Unmarshal(body, &session), checker.IsNil)
+	var files []string
+	for _, f := range session.Files {
+		files = append(files, f.Name)
+	}
+	c.Assert(files, checker.DeepEquals, []string{"Dockerfile", "big", "dir", "dir/bar", "foo"})
+
+	// only the changes of the context are sent
+	c.Assert(ctx.Add("foo", "changed"), checker.IsNil)
+	c.Assert(ctx.Add("baz", "baz"), checker.IsNil)
+	c.Assert(ctx.Delete("dir"), checker.IsNil)
+	_, out, err = buildImageFromContextWithOut(name, ctx, true, "--session", name)
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Not(checker.Matches), `(?s).*Sending build context to Docker daemon [0-9.]+ MB.*`)
+	out, _ = dockerCmd(c, "run", "--rm", name, "sh", "-c", "ls /ctx && cat /ctx/foo && stat -c %s /ctx/big")
+	c.Assert(out, checker.Equals, "Dockerfile\nbaz\nbig\nfoo\nchanged3145728\n")
+
+	// the cache of the steps doesn't depend on the session
+	_, out, err = buildImageFromContextWithOut(name, ctx, true)
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, "Using cache")
+
+	// a deleted session has no files
+	status, _, err = sockRequest("DELETE", "/build/sessions/"+name, nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusNoContent)
+	status, body, err = sockRequest("GET", "/build/sessions/"+name, nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusOK)
+	c.Assert(json.Unmarshal(body, &session), checker.IsNil)
+	c.Assert(session.Files, checker.HasLen, 0)
+}
+
+func (s *DockerSuite) TestBuildSessionInvalid(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	_, out, err := buildImageWithOut("testbuildsessioninvalid", "FROM busybox", true, "--session", "_foo")
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "Invalid build session ID")
+}
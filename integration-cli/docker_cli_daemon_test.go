@@ -14,6 +14,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/docker/docker/pkg/integration/checker"
@@ -1263,7 +1264,7 @@ func (s *DockerDaemonSuite) TestDaemonLoggingDriverNoneLogsError(c *check.C) {
 	if err == nil {
 		c.Fatalf("Logs should fail with 'none' driver")
 	}
-	if !strings.Contains(out, `"logs" command is supported only for "json-file" and "journald" logging drivers (got: none)`) {
+	if !strings.Contains(out, `"logs" command is not supported for the "none" logging driver`) {
 		c.Fatalf("There should be an error about none not being a recognized log driver, got: %s", out)
 	}
 }
@@ -1878,3 +1879,133 @@ func (s *DockerDaemonSuite) TestDaemonNoSpaceleftOnDeviceError(c *check.C) {
 	out, err := s.d.Cmd("pull", "registry:2")
 	c.Assert(out, check.Not(check.Equals), 1, check.Commentf("no space left on device"))
 }
+
+func (s *DockerDaemonSuite) TestDaemonConfigurationFile(c *check.C) {
+	configFile := filepath.Join(s.d.folder, "daemon.json")
+	err := ioutil.WriteFile(configFile, []byte(`{"label": ["foo=bar"]}`), 0644)
+	c.Assert(err, checker.IsNil)
+
+	err = s.d.Start("--config-file", configFile)
+	c.Assert(err, checker.IsNil)
+
+	out, err := s.d.Cmd("info")
+	c.Assert(err, checker.IsNil)
+	c.Assert(out, checker.Contains, "foo=bar")
+}
+
+func (s *DockerDaemonSuite) TestDaemonConfigurationFileConflict(c *check.C) {
+	configFile := filepath.Join(s.d.folder, "daemon.json")
+	err := ioutil.WriteFile(configFile, []byte(`{"label": ["foo=bar"]}`), 0644)
+	c.Assert(err, checker.IsNil)
+
+	// The label is set both with a flag and in the configuration file
+	err = s.d.Start("--config-file", configFile, "--label", "baz=qux")
+	c.Assert(err, checker.NotNil)
+
+	content, _ := ioutil.ReadFile(s.d.logFile.Name())
+	c.Assert(string(content), checker.Contains, "label: (from flag: [baz=qux], from file: [foo=bar])")
+}
+
+func (s *DockerDaemonSuite) TestDaemonConfigurationReload(c *check.C) {
+	configFile := filepath.Join(s.d.folder, "daemon.json")
+	err := ioutil.WriteFile(configFile, []byte(`{"label": ["foo=bar"]}`), 0644)
+	c.Assert(err, checker.IsNil)
+
+	// Debug mode is set in the configuration file, not with a flag
+	err = s.d.Start("--config-file", configFile, "--log-level=info")
+	c.Assert(err, checker.IsNil)
+
+	out, err := s.d.Cmd("info")
+	c.Assert(err, checker.IsNil)
+	c.Assert(out, checker.Contains, "Debug mode (server): false")
+
+	err = ioutil.WriteFile(configFile, []byte(`{"label": ["foo=baz"], "debug": true}`), 0644)
+	c.Assert(err, checker.IsNil)
+
+	err = s.d.cmd.Process.Signal(syscall.SIGHUP)
+	c.Assert(err, checker.IsNil)
+
+	for i := 0; i < 50; i++ {
+		out, err = s.d.Cmd("info")
+		c.Assert(err, checker.IsNil)
+		if strings.Contains(out, "foo=baz") {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	c.Assert(out, checker.Contains, "foo=baz")
+	c.Assert(out, checker.Not(checker.Contains), "foo=bar")
+	c.Assert(out, checker.Contains, "Debug mode (server): true")
+}
+
+func (s *DockerDaemonSuite) TestDaemonEventsJournalAfterRestart(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	c.Assert(s.d.StartWithBusybox(), check.IsNil)
+
+	out, err := s.d.Cmd("run", "-d", "busybox", "true")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	id := strings.TrimSpace(out)
+	out, err = s.d.Cmd("wait", id)
+	c.Assert(err, check.IsNil, check.Commentf(out))
+
+	c.Assert(s.d.Restart(), check.IsNil)
+
+	// the events recorded before the restart are replayed from the journal
+	until := strconv.FormatInt(time.Now().Unix(), 10)
+	out, err = s.d.Cmd("events", "--since=0", "--until="+until, "--filter", "container="+id)
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, id+": (from busybox) start\n")
+	c.Assert(out, checker.Contains, id+": (from busybox) die\n")
+}
+
+func (s *DockerDaemonSuite) TestDaemonLiveRestore(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	c.Assert(s.d.StartWithBusybox("--live-restore"), check.IsNil)
+
+	out, err := s.d.Cmd("run", "-d", "--name", "live", "busybox", "sh", "-c", "while true; do echo tick; sleep 1; done")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	out, err = s.d.Cmd("run", "-d", "-t", "--name", "tty", "busybox", "top")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+
+	pid, err := s.d.Cmd("inspect", "-f", "{{.State.Pid}}", "live")
+	c.Assert(err, check.IsNil, check.Commentf(pid))
+	ip, err := s.d.Cmd("inspect", "-f", "{{.NetworkSettings.IPAddress}}", "live")
+	c.Assert(err, check.IsNil, check.Commentf(ip))
+
+	c.Assert(s.d.Restart("--live-restore"), check.IsNil)
+
+	// the container kept running with the same process
+	out, err = s.d.Cmd("inspect", "-f", "{{.State.Running}} {{.State.Pid}}", "live")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(strings.TrimSpace(out), checker.Equals, "true "+strings.TrimSpace(pid))
+
+	// the networking of the container was left untouched
+	out, err = s.d.Cmd("inspect", "-f", "{{.NetworkSettings.IPAddress}}", "live")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Equals, ip)
+	out, err = s.d.Cmd("exec", "live", "ip", "-o", "-4", "addr", "show", "eth0")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Contains, strings.TrimSpace(ip)+"/")
+
+	// containers with a tty are stopped with the daemon
+	out, err = s.d.Cmd("inspect", "-f", "{{.State.Running}}", "tty")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(strings.TrimSpace(out), checker.Equals, "false")
+
+	// the output of the container is logged again
+	out, err = s.d.Cmd("logs", "live")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	before := strings.Count(out, "tick")
+	time.Sleep(2 * time.Second)
+	out, err = s.d.Cmd("logs", "live")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(strings.Count(out, "tick"), checker.GreaterThan, before)
+
+	// the exit code of a restored container is unknown, and reported as a
+	// failure
+	out, err = s.d.Cmd("stop", "live")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	out, err = s.d.Cmd("inspect", "-f", "{{.State.Running}} {{.State.ExitCode}}", "live")
+	c.Assert(err, check.IsNil, check.Commentf(out))
+	c.Assert(strings.TrimSpace(out), checker.Equals, "false 255")
+}
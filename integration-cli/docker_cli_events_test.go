@@ -73,7 +73,7 @@ func (s *DockerSuite) TestEventsContainerFailStartDie(c *check.C) {
 	_, _, err := dockerCmdWithError("run", "--name", "testeventdie", image, "blerg")
 	c.Assert(err, checker.NotNil, check.Commentf("Container run with command blerg should have failed, but it did not, out=%s", out))
 
-	out, _ = dockerCmd(c, "events", "--since=0", fmt.Sprintf("--until=%d", daemonTime(c).Unix()))
+	out, _ = dockerCmd(c, "events", "--since=0", fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "type=container")
 	events := strings.Split(out, "\n")
 	c.Assert(len(events), checker.GreaterThan, 1) //Missing expected event
 
@@ -106,16 +106,18 @@ func (s *DockerSuite) TestEventsLimit(c *check.C) {
 		c.Assert(err, checker.IsNil, check.Commentf("%q failed with error", strings.Join(args, " ")))
 	}
 
-	out, _ := dockerCmd(c, "events", "--since=0", fmt.Sprintf("--until=%d", daemonTime(c).Unix()))
+	// the events are read from the journal, they are not limited to the
+	// last 64 events kept in memory
+	out, _ := dockerCmd(c, "events", "--since=0", fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "type=container")
 	events := strings.Split(out, "\n")
 	nEvents := len(events) - 1
-	c.Assert(nEvents, checker.Equals, 64, check.Commentf("events should be limited to 64, but received %d", nEvents))
+	c.Assert(nEvents, checker.GreaterOrEqualThan, 17*5, check.Commentf("events should not be limited to 64, but received %d", nEvents))
 }
 
 func (s *DockerSuite) TestEventsContainerEvents(c *check.C) {
 	testRequires(c, DaemonIsLinux)
 	dockerCmd(c, "run", "--rm", "busybox", "true")
-	out, _ := dockerCmd(c, "events", "--since=0", fmt.Sprintf("--until=%d", daemonTime(c).Unix()))
+	out, _ := dockerCmd(c, "events", "--since=0", fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "type=container")
 	events := strings.Split(out, "\n")
 	events = events[:len(events)-1]
 	c.Assert(len(events), checker.GreaterOrEqualThan, 5) //Missing expected event
@@ -138,7 +140,7 @@ func (s *DockerSuite) TestEventsContainerEventsSinceUnixEpoch(c *check.C) {
 	timeBeginning := time.Unix(0, 0).Format(time.RFC3339Nano)
 	timeBeginning = strings.Replace(timeBeginning, "Z", ".000000000Z", -1)
 	out, _ := dockerCmd(c, "events", fmt.Sprintf("--since='%s'", timeBeginning),
-		fmt.Sprintf("--until=%d", daemonTime(c).Unix()))
+		fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "type=container")
 	events := strings.Split(out, "\n")
 	events = events[:len(events)-1]
 	c.Assert(len(events), checker.GreaterOrEqualThan, 5) //Missing expected event
@@ -615,11 +617,16 @@ func (s *DockerSuite) TestEventsTop(c *check.C) {
 }
 
 // #13753
-func (s *DockerSuite) TestEventsDefaultEmpty(c *check.C) {
+func (s *DockerSuite) TestEventsUntilWithoutSince(c *check.C) {
 	testRequires(c, DaemonIsLinux)
-	dockerCmd(c, "run", "busybox")
-	out, _ := dockerCmd(c, "events", fmt.Sprintf("--until=%d", daemonTime(c).Unix()))
-	c.Assert(strings.TrimSpace(out), checker.Equals, "")
+	out, _ := dockerCmd(c, "run", "-d", "busybox", "true")
+	cID := strings.TrimSpace(out)
+	dockerCmd(c, "wait", cID)
+
+	// the recorded events are returned, and the stream is closed as until
+	// is in the past
+	out, _ = dockerCmd(c, "events", fmt.Sprintf("--until=%d", daemonTime(c).Unix()))
+	c.Assert(out, checker.Contains, cID+": (from busybox) die\n")
 }
 
 // #14316
@@ -114,3 +114,83 @@ func (s *DockerSuite) TestEventsOOMDisableTrue(c *check.C) {
 		c.Assert(strings.TrimSpace(out), checker.Equals, "running", check.Commentf("container should be still running"))
 	}
 }
+
+// parseEventAction returns the action of an event printed as
+// `time type action actor-id (attributes)`.
+func parseEventAction(c *check.C, event string) string {
+	fields := strings.Fields(event)
+	c.Assert(len(fields), checker.GreaterOrEqualThan, 4, check.Commentf("unexpected event format %q", event))
+	return fields[2]
+}
+
+func (s *DockerSuite) TestEventsVolumeEvents(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+
+	since := daemonTime(c).Unix()
+	dockerCmd(c, "volume", "create", "--name", "test-event-volume-local")
+	dockerCmd(c, "run", "--name", "test-volume-container", "--volume", "test-event-volume-local:/foo", "busybox", "true")
+	dockerCmd(c, "rm", "test-volume-container")
+	dockerCmd(c, "volume", "rm", "test-event-volume-local")
+
+	out, _ := dockerCmd(c, "events", fmt.Sprintf("--since=%d", since), fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "volume=test-event-volume-local")
+	events := strings.Split(strings.TrimSpace(out), "\n")
+	c.Assert(events, checker.HasLen, 3, check.Commentf("Events == %s", events))
+
+	expected := []string{"create", "mount", "destroy"}
+	for i, e := range events {
+		c.Assert(e, checker.Contains, "volume ")
+		c.Assert(e, checker.Contains, "test-event-volume-local")
+		c.Assert(parseEventAction(c, e), checker.Equals, expected[i])
+	}
+}
+
+func (s *DockerSuite) TestEventsNetworkEvents(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+
+	since := daemonTime(c).Unix()
+	dockerCmd(c, "network", "create", "test-event-network-local")
+	dockerCmd(c, "run", "--name", "test-network-container", "--net", "test-event-network-local", "busybox", "true")
+	dockerCmd(c, "rm", "-f", "test-network-container")
+	dockerCmd(c, "network", "rm", "test-event-network-local")
+
+	out, _ := dockerCmd(c, "events", fmt.Sprintf("--since=%d", since), fmt.Sprintf("--until=%d", daemonTime(c).Unix()), "--filter", "network=test-event-network-local")
+	events := strings.Split(strings.TrimSpace(out), "\n")
+	c.Assert(events, checker.HasLen, 4, check.Commentf("Events == %s", events))
+
+	expected := []string{"create", "connect", "disconnect", "destroy"}
+	for i, e := range events {
+		c.Assert(e, checker.Contains, "network ")
+		c.Assert(e, checker.Contains, "name=test-event-network-local")
+		c.Assert(parseEventAction(c, e), checker.Equals, expected[i])
+	}
+}
+
+func (s *DockerSuite) TestEventsFilterType(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+
+	since := daemonTime(c).Unix()
+	dockerCmd(c, "run", "--rm", "busybox", "true")
+	dockerCmd(c, "volume", "create", "--name", "test-event-type")
+	dockerCmd(c, "volume", "rm", "test-event-type")
+	until := daemonTime(c).Unix()
+
+	out, _ := dockerCmd(c, "events", fmt.Sprintf("--since=%d", since), fmt.Sprintf("--until=%d", until), "--filter", "type=network")
+	events := strings.Split(strings.TrimSpace(out), "\n")
+	c.Assert(events, checker.HasLen, 2, check.Commentf("Events == %s", events))
+	c.Assert(parseEventAction(c, events[0]), checker.Equals, "connect")
+	c.Assert(parseEventAction(c, events[1]), checker.Equals, "disconnect")
+	for _, e := range events {
+		c.Assert(e, checker.Contains, "name=bridge")
+	}
+
+	out, _ = dockerCmd(c, "events", fmt.Sprintf("--since=%d", since), fmt.Sprintf("--until=%d", until), "--filter", "type=volume")
+	events = strings.Split(strings.TrimSpace(out), "\n")
+	c.Assert(events, checker.HasLen, 2, check.Commentf("Events == %s", events))
+	c.Assert(events[0], checker.Contains, "volume create test-event-type")
+	c.Assert(events[1], checker.Contains, "volume destroy test-event-type")
+
+	out, _ = dockerCmd(c, "events", fmt.Sprintf("--since=%d", since), fmt.Sprintf("--until=%d", until), "--filter", "type=container", "--filter", "event=die")
+	events = strings.Split(strings.TrimSpace(out), "\n")
+	c.Assert(events, checker.HasLen, 1, check.Commentf("Events == %s", events))
+	c.Assert(events[0], checker.HasSuffix, " die")
+}
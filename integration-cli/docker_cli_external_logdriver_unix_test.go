@@ -0,0 +1,189 @@
+// +build !windows
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/docker/docker/pkg/plugins/logdriver"
+	"github.com/go-check/check"
+)
+
+const testExternalLogDriver = "test-external-log-driver"
+
+func init() {
+	check.Suite(&DockerExternalLogDriverSuite{
+		ds: &DockerSuite{},
+	})
+}
+
+type DockerExternalLogDriverSuite struct {
+	server *httptest.Server
+	ds     *DockerSuite
+	d      *Daemon
+
+	mu      sync.Mutex
+	streams map[string]chan struct{}        // closed once a stream is read, by file
+	entries map[string][]logdriver.LogEntry // entries read, by container ID
+}
+
+func (s *DockerExternalLogDriverSuite) SetUpTest(c *check.C) {
+	s.d = NewDaemon(c)
+	s.streams = make(map[string]chan struct{})
+	s.entries = make(map[string][]logdriver.LogEntry)
+}
+
+func (s *DockerExternalLogDriverSuite) TearDownTest(c *check.C) {
+	s.d.Stop()
+	s.ds.TearDownTest(c)
+}
+
+func (s *DockerExternalLogDriverSuite) SetUpSuite(c *check.C) {
+	mux := http.NewServeMux()
+	s.server = httptest.NewServer(mux)
+
+	type pluginRequest struct {
+		File string
+		Info struct {
+			ContainerID string
+		}
+	}
+
+	send := func(w http.ResponseWriter, data string) {
+		w.Header().Set("Content-Type", "application/vnd.docker.plugins.v1+json")
+		fmt.Fprintln(w, data)
+	}
+
+	mux.HandleFunc("/Plugin.Activate", func(w http.ResponseWriter, r *http.Request) {
+		send(w, `{"Implements": ["LogDriver"]}`)
+	})
+
+	mux.HandleFunc("/LogDriver.Capabilities", func(w http.ResponseWriter, r *http.Request) {
+		send(w, `{"Cap": {"ReadLogs": true}}`)
+	})
+
+	mux.HandleFunc("/LogDriver.StartLogging", func(w http.ResponseWriter, r *http.Request) {
+		var req pluginRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		f, err := os.Open(req.File)
+		if err != nil {
+			send(w, fmt.Sprintf(`{"Err": %q}`, err))
+			return
+		}
+		done := make(chan struct{})
+		s.mu.Lock()
+		s.streams[req.File] = done
+		s.mu.Unlock()
+
+		go func() {
+			defer close(done)
+			defer f.Close()
+			dec := logdriver.NewLogEntryDecoder(f)
+			for {
+				var entry logdriver.LogEntry
+				if err := dec.Decode(&entry); err != nil {
+					return
+				}
+				s.mu.Lock()
+				s.entries[req.Info.ContainerID] = append(s.entries[req.Info.ContainerID], entry)
+				s.mu.Unlock()
+			}
+		}()
+		send(w, `{}`)
+	})
+
+	mux.HandleFunc("/LogDriver.StopLogging", func(w http.ResponseWriter, r *http.Request) {
+		var req pluginRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		s.mu.Lock()
+		done := s.streams[req.File]
+		s.mu.Unlock()
+		if done != nil {
+			<-done
+		}
+		send(w, `{}`)
+	})
+
+	mux.HandleFunc("/LogDriver.ReadLogs", func(w http.ResponseWriter, r *http.Request) {
+		var req pluginRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		s.mu.Lock()
+		entries := s.entries[req.Info.ContainerID]
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/vnd.docker.plugins.v1+json")
+		enc := logdriver.NewLogEntryEncoder(w)
+		for i := range entries {
+			enc.Encode(&entries[i])
+		}
+	})
+
+	err := os.MkdirAll("/etc/docker/plugins", 0755)
+	c.Assert(err, checker.IsNil)
+
+	err = ioutil.WriteFile("/etc/docker/plugins/"+testExternalLogDriver+".spec", []byte(s.server.URL), 0644)
+	c.Assert(err, checker.IsNil)
+}
+
+func (s *DockerExternalLogDriverSuite) TearDownSuite(c *check.C) {
+	s.server.Close()
+
+	err := os.Remove("/etc/docker/plugins/" + testExternalLogDriver + ".spec")
+	c.Assert(err, checker.IsNil)
+}
+
+func (s *DockerExternalLogDriverSuite) TestExternalLogDriver(c *check.C) {
+	err := s.d.StartWithBusybox()
+	c.Assert(err, checker.IsNil)
+
+	out, err := s.d.Cmd("run", "--name", "test-logs", "--log-driver", testExternalLogDriver, "busybox", "sh", "-c", "echo foo; echo bar >&2")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	out, err = s.d.Cmd("inspect", "-f", "{{.Id}}", "test-logs")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	id := strings.TrimSpace(out)
+
+	s.mu.Lock()
+	entries := s.entries[id]
+	s.mu.Unlock()
+	c.Assert(entries, checker.HasLen, 2)
+	sources := map[string]string{}
+	for _, entry := range entries {
+		sources[entry.Source] = string(entry.Line)
+	}
+	c.Assert(sources, checker.DeepEquals, map[string]string{"stdout": "foo", "stderr": "bar"})
+
+	// the logs are read from the plugin
+	out, err = s.d.Cmd("logs", "test-logs")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(strings.Contains(out, "foo\n"), checker.True, check.Commentf(out))
+	c.Assert(strings.Contains(out, "bar\n"), checker.True, check.Commentf(out))
+}
+
+func (s *DockerExternalLogDriverSuite) TestExternalLogDriverDaemonDefault(c *check.C) {
+	err := s.d.StartWithBusybox("--log-driver", testExternalLogDriver)
+	c.Assert(err, checker.IsNil)
+
+	out, err := s.d.Cmd("run", "--name", "test-logs", "busybox", "echo", "foo")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+
+	out, err = s.d.Cmd("logs", "test-logs")
+	c.Assert(err, checker.IsNil, check.Commentf(out))
+	c.Assert(out, checker.Equals, "foo\n")
+}
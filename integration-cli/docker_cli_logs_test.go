@@ -351,3 +351,104 @@ func (s *DockerSuite) TestLogsCLIContainerNotFound(c *check.C) {
 	message := fmt.Sprintf(".*no such id: %s.*\n", name)
 	c.Assert(out, checker.Matches, message)
 }
+
+func (s *DockerSuite) TestLogsNonBlockingMode(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _ := dockerCmd(c, "run", "-d", "--log-opt", "mode=non-blocking", "--log-opt", "max-buffer-size=4m", "busybox", "sh", "-c", "for i in $(seq 1 100); do echo $i; done")
+
+	id := strings.TrimSpace(out)
+	dockerCmd(c, "wait", id)
+
+	out, _ = dockerCmd(c, "logs", id)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	c.Assert(lines, checker.HasLen, 100)
+
+	dropped, err := inspectField(id, "LogDroppedMessages")
+	c.Assert(err, checker.IsNil)
+	c.Assert(dropped, checker.Equals, "0")
+}
+
+func (s *DockerSuite) TestLogsNonBlockingModeInvalidOptions(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _, err := dockerCmdWithError("run", "--log-opt", "mode=unknown", "busybox", "true")
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "logging mode not supported")
+
+	out, _, err = dockerCmdWithError("run", "--log-opt", "max-buffer-size=4m", "busybox", "true")
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "max-buffer-size option is only supported with 'mode=non-blocking'")
+}
+
+func (s *DockerSuite) TestLogsLongLines(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _ := dockerCmd(c, "run", "-d", "busybox", "sh", "-c", "head -c 40000 /dev/zero | tr '\\0' a; echo; echo end")
+
+	id := strings.TrimSpace(out)
+	dockerCmd(c, "wait", id)
+
+	// the line split in partial messages is output whole
+	out, _ = dockerCmd(c, "logs", id)
+	c.Assert(out, checker.Equals, strings.Repeat("a", 40000)+"\nend\n")
+}
+
+func (s *DockerSuite) TestLogsMultilinePattern(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _ := dockerCmd(c, "run", "-d", "--log-opt", `multiline-pattern=^[^\s]`, "busybox", "sh", "-c", `printf 'Exception\n\tat a\n\tat b\nnext\n'`)
+
+	id := strings.TrimSpace(out)
+	dockerCmd(c, "wait", id)
+
+	// the lines of the stack trace are a single message, with a single
+	// timestamp
+	out, _ = dockerCmd(c, "logs", "-t", id)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	c.Assert(lines, checker.HasLen, 4)
+	c.Assert(lines[0], checker.HasSuffix, " Exception")
+	c.Assert(lines[1], checker.Equals, "\tat a")
+	c.Assert(lines[2], checker.Equals, "\tat b")
+	c.Assert(lines[3], checker.HasSuffix, " next")
+
+	out, _, err := dockerCmdWithError("run", "--log-opt", "multiline-pattern=^(", "busybox", "true")
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "error parsing option multiline-pattern")
+}
+
+func (s *DockerSuite) TestLogsLocalCache(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _ := dockerCmd(c, "run", "-d", "--log-driver=syslog", "--log-opt", "syslog-address=udp://127.0.0.1:514", "busybox", "echo", "hello from the cache")
+
+	id := strings.TrimSpace(out)
+	dockerCmd(c, "wait", id)
+
+	out, _ = dockerCmd(c, "logs", id)
+	c.Assert(out, checker.Equals, "hello from the cache\n")
+}
+
+func (s *DockerSuite) TestLogsLocalCacheDisabled(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _ := dockerCmd(c, "run", "-d", "--log-driver=syslog", "--log-opt", "syslog-address=udp://127.0.0.1:514", "--log-opt", "cache-disabled=true", "busybox", "echo", "hello")
+
+	id := strings.TrimSpace(out)
+	dockerCmd(c, "wait", id)
+
+	out, _, err := dockerCmdWithError("logs", id)
+	c.Assert(err, checker.NotNil)
+	c.Assert(out, checker.Contains, "configured logging reader does not support reading")
+}
+
+func (s *DockerSuite) TestLogsLocalDriver(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _ := dockerCmd(c, "run", "-d", "--log-driver=local", "--log-opt", "max-size=10k", "--log-opt", "max-file=3", "busybox", "sh", "-c", "for i in $(seq 1 3000); do echo $i; done")
+
+	id := strings.TrimSpace(out)
+	dockerCmd(c, "wait", id)
+
+	out, _ = dockerCmd(c, "logs", "--tail", "2", id)
+	c.Assert(out, checker.Equals, "2999\n3000\n")
+
+	// the oldest log files were removed
+	out, _ = dockerCmd(c, "logs", id)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	c.Assert(len(lines) < 3000, checker.True, check.Commentf("expected the oldest lines to be removed, got %d lines", len(lines)))
+	c.Assert(lines[len(lines)-1], checker.Equals, "3000")
+}
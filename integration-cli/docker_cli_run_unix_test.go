@@ -4,6 +4,7 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -13,6 +14,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/pkg/integration/checker"
 	"github.com/docker/docker/pkg/mount"
 	"github.com/docker/docker/pkg/parsers"
@@ -275,6 +277,53 @@ func (s *DockerSuite) TestRunWithSwappinessInvalid(c *check.C) {
 	c.Assert(out, checker.Contains, expected, check.Commentf("Expected output to contain %q, not %q", out, expected))
 }
 
+func (s *DockerSuite) TestRunPidsLimit(c *check.C) {
+	testRequires(c, pidsLimit)
+
+	// sh and the first sleep use up the limit, so the second fork fails
+	out, _, err := dockerCmdWithError("run", "--pids-limit", "2", "--name", "test", "busybox", "sh", "-c", "sleep 1 & sleep 1 & wait")
+	c.Assert(err, check.NotNil)
+	c.Assert(out, checker.Contains, "can't fork")
+
+	out, err = inspectField("test", "HostConfig.PidsLimit")
+	c.Assert(err, check.IsNil)
+	c.Assert(out, check.Equals, "2")
+}
+
+func (s *DockerSuite) TestRunPidsLimitExec(c *check.C) {
+	testRequires(c, pidsLimit)
+
+	// the processes of docker exec count towards the limit of the container
+	dockerCmd(c, "run", "-d", "--pids-limit", "2", "--name", "test", "busybox", "top")
+	c.Assert(waitRun("test"), checker.IsNil)
+	out, _, err := dockerCmdWithError("exec", "test", "sh", "-c", "sleep 1 & wait")
+	c.Assert(err, check.NotNil)
+	c.Assert(out, checker.Contains, "can't fork")
+}
+
+func (s *DockerSuite) TestRunPidsLimitInvalid(c *check.C) {
+	out, _, err := dockerCmdWithError("run", "--pids-limit", "-2", "busybox", "true")
+	c.Assert(err, check.NotNil)
+	c.Assert(out, checker.Contains, "The pids limit must be -1 (unlimited) or greater")
+}
+
+func (s *DockerSuite) TestRunPidsLimitStats(c *check.C) {
+	testRequires(c, pidsLimit)
+
+	out, _ := dockerCmd(c, "run", "-d", "--pids-limit", "10", "busybox", "sh", "-c", "sleep 100 & top")
+	id := strings.TrimSpace(out)
+	c.Assert(waitRun(id), checker.IsNil)
+
+	_, body, err := sockRequestRaw("GET", fmt.Sprintf("/containers/%s/stats?stream=false", id), nil, "")
+	c.Assert(err, checker.IsNil)
+	defer body.Close()
+
+	var v types.StatsJSON
+	c.Assert(json.NewDecoder(body).Decode(&v), checker.IsNil)
+	// at least the sleep and top processes
+	c.Assert(v.PidsStats.Current, checker.GreaterThan, uint64(1))
+}
+
 func (s *DockerSuite) TestRunWithMemoryReservation(c *check.C) {
 	testRequires(c, memoryReservationSupport)
 	dockerCmd(c, "run", "--memory-reservation", "200M", "busybox", "true")
@@ -366,3 +415,41 @@ func (s *DockerSuite) TestRunInvalidCPUShares(c *check.C) {
 	expected = "The maximum allowed cpu-shares is"
 	c.Assert(out, checker.Contains, expected)
 }
+
+func (s *DockerSuite) TestRunTmpfsMounts(c *check.C) {
+	// TODO Windows (Post TP4): This test cannot run on a Windows daemon as
+	// Windows does not support tmpfs mounts.
+	testRequires(c, DaemonIsLinux)
+	if out, _, err := dockerCmdWithError("run", "--tmpfs", "/run", "busybox", "touch", "/run/somefile"); err != nil {
+		c.Fatalf("/run directory not mounted on tmpfs %q %s", err, out)
+	}
+	if out, _, err := dockerCmdWithError("run", "--tmpfs", "/run:noexec", "busybox", "touch", "/run/somefile"); err != nil {
+		c.Fatalf("/run directory not mounted on tmpfs %q %s", err, out)
+	}
+	if out, _, err := dockerCmdWithError("run", "--tmpfs", "/run:noexec,nosuid,rw,size=5k,mode=700", "busybox", "touch", "/run/somefile"); err != nil {
+		c.Fatalf("/run failed to mount on tmpfs with valid options %q %s", err, out)
+	}
+	if _, _, err := dockerCmdWithError("run", "--tmpfs", "/run:foobar", "busybox", "touch", "/run/somefile"); err == nil {
+		c.Fatalf("/run mounted on tmpfs when it should have failed with an invalid mount option")
+	}
+	if _, _, err := dockerCmdWithError("run", "--tmpfs", "/run", "-v", "/run:/run", "busybox", "touch", "/run/somefile"); err == nil {
+		c.Fatalf("Should have generated an error saying Duplicate mount points")
+	}
+}
+
+func (s *DockerSuite) TestRunTmpfsMountsReadOnlyRootfs(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _ := dockerCmd(c, "run", "--read-only", "--tmpfs", "/run", "--tmpfs", "/tmp", "busybox", "sh", "-c", "touch /run/file && touch /tmp/file && echo ok")
+	c.Assert(strings.TrimSpace(out), checker.Equals, "ok")
+}
+
+func (s *DockerSuite) TestRunTmpfsMountsExcludedFromDiff(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dockerCmd(c, "run", "--name", "tmpfs_diff", "--tmpfs", "/scratch", "busybox", "touch", "/scratch/file")
+	out, _ := dockerCmd(c, "diff", "tmpfs_diff")
+	c.Assert(out, checker.Not(checker.Contains), "/scratch")
+
+	dockerCmd(c, "commit", "tmpfs_diff", "tmpfs_diff_image")
+	out, _, err := dockerCmdWithError("run", "--rm", "tmpfs_diff_image", "ls", "/scratch")
+	c.Assert(err, checker.NotNil, check.Commentf(out))
+}
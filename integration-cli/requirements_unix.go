@@ -57,6 +57,12 @@ var (
 		},
 		"Test requires an environment that supports cgroup swap memory limit.",
 	}
+	pidsLimit = testRequirement{
+		func() bool {
+			return SysInfo.PidsLimit
+		},
+		"Test requires an environment that supports cgroup pids limit.",
+	}
 	memorySwappinessSupport = testRequirement{
 		func() bool {
 			return SysInfo.MemorySwappiness
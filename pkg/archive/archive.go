@@ -489,102 +489,132 @@ func TarWithOptions(srcPath string, options *TarOptions) (io.ReadCloser, error)
 		// during e.g. a diff operation the container can continue
 		// mutating the filesystem and we can see transient errors
 		// from this
+		walkWithOptions(srcPath, options, patterns, patDirs, exceptions, func(filePath, relFilePath string, f os.FileInfo) error {
+			if err := ta.addTarFile(filePath, relFilePath); err != nil {
+				logrus.Debugf("Can't add file %s to tar: %s", filePath, err)
+			}
+			return nil
+		})
+	}()
 
-		stat, err := os.Lstat(srcPath)
-		if err != nil {
-			return
-		}
+	return pipeReader, nil
+}
 
-		if !stat.IsDir() {
-			// We can't later join a non-dir with any includes because the
-			// 'walk' will error if "file/." is stat-ed and "file" is not a
-			// directory. So, we must split the source path and use the
-			// basename as the include.
-			if len(options.IncludeFiles) > 0 {
-				logrus.Warn("Tar: Can't archive a file with includes")
-			}
+// WalkFunc is the type of the function called by WalkWithOptions for each
+// file, with its path and its name in the archive.
+type WalkFunc func(filePath, relFilePath string, f os.FileInfo) error
 
-			dir, base := SplitPathDirEntry(srcPath)
-			srcPath = dir
-			options.IncludeFiles = []string{base}
-		}
+// WalkWithOptions walks the files which TarWithOptions archives from srcPath
+// with the same options, calling walkFn for each of them. If walkFn returns
+// an error, the walk stops and the error is returned.
+func WalkWithOptions(srcPath string, options *TarOptions, walkFn WalkFunc) error {
+	srcPath = fixVolumePathPrefix(srcPath)
+
+	patterns, patDirs, exceptions, err := fileutils.CleanPatterns(options.ExcludePatterns)
+	if err != nil {
+		return err
+	}
+	return walkWithOptions(srcPath, options, patterns, patDirs, exceptions, walkFn)
+}
 
-		if len(options.IncludeFiles) == 0 {
-			options.IncludeFiles = []string{"."}
+func walkWithOptions(srcPath string, options *TarOptions, patterns []string, patDirs [][]string, exceptions bool, walkFn WalkFunc) error {
+	stat, err := os.Lstat(srcPath)
+	if err != nil {
+		return err
+	}
+
+	if !stat.IsDir() {
+		// We can't later join a non-dir with any includes because the
+		// 'walk' will error if "file/." is stat-ed and "file" is not a
+		// directory. So, we must split the source path and use the
+		// basename as the include.
+		if len(options.IncludeFiles) > 0 {
+			logrus.Warn("Tar: Can't archive a file with includes")
 		}
 
-		seen := make(map[string]bool)
+		dir, base := SplitPathDirEntry(srcPath)
+		srcPath = dir
+		options.IncludeFiles = []string{base}
+	}
 
-		for _, include := range options.IncludeFiles {
-			rebaseName := options.RebaseNames[include]
+	if len(options.IncludeFiles) == 0 {
+		options.IncludeFiles = []string{"."}
+	}
 
-			walkRoot := getWalkRoot(srcPath, include)
-			filepath.Walk(walkRoot, func(filePath string, f os.FileInfo, err error) error {
-				if err != nil {
-					logrus.Debugf("Tar: Can't stat file %s to tar: %s", srcPath, err)
-					return nil
-				}
+	seen := make(map[string]bool)
 
-				relFilePath, err := filepath.Rel(srcPath, filePath)
-				if err != nil || (!options.IncludeSourceDir && relFilePath == "." && f.IsDir()) {
-					// Error getting relative path OR we are looking
-					// at the source directory path. Skip in both situations.
-					return nil
-				}
+	var walkErr error
+	for _, include := range options.IncludeFiles {
+		rebaseName := options.RebaseNames[include]
 
-				if options.IncludeSourceDir && include == "." && relFilePath != "." {
-					relFilePath = strings.Join([]string{".", relFilePath}, string(filepath.Separator))
-				}
+		walkRoot := getWalkRoot(srcPath, include)
+		filepath.Walk(walkRoot, func(filePath string, f os.FileInfo, err error) error {
+			if err != nil {
+				logrus.Debugf("Tar: Can't stat file %s to tar: %s", srcPath, err)
+				return nil
+			}
 
-				skip := false
-
-				// If "include" is an exact match for the current file
-				// then even if there's an "excludePatterns" pattern that
-				// matches it, don't skip it. IOW, assume an explicit 'include'
-				// is asking for that file no matter what - which is true
-				// for some files, like .dockerignore and Dockerfile (sometimes)
-				if include != relFilePath {
-					skip, err = fileutils.OptimizedMatches(relFilePath, patterns, patDirs)
-					if err != nil {
-						logrus.Debugf("Error matching %s: %v", relFilePath, err)
-						return err
-					}
-				}
+			relFilePath, err := filepath.Rel(srcPath, filePath)
+			if err != nil || (!options.IncludeSourceDir && relFilePath == "." && f.IsDir()) {
+				// Error getting relative path OR we are looking
+				// at the source directory path. Skip in both situations.
+				return nil
+			}
 
-				if skip {
-					if !exceptions && f.IsDir() {
-						return filepath.SkipDir
-					}
-					return nil
-				}
+			if options.IncludeSourceDir && include == "." && relFilePath != "." {
+				relFilePath = strings.Join([]string{".", relFilePath}, string(filepath.Separator))
+			}
 
-				if seen[relFilePath] {
-					return nil
-				}
-				seen[relFilePath] = true
-
-				// Rename the base resource.
-				if rebaseName != "" {
-					var replacement string
-					if rebaseName != string(filepath.Separator) {
-						// Special case the root directory to replace with an
-						// empty string instead so that we don't end up with
-						// double slashes in the paths.
-						replacement = rebaseName
-					}
-
-					relFilePath = strings.Replace(relFilePath, include, replacement, 1)
+			skip := false
+
+			// If "include" is an exact match for the current file
+			// then even if there's an "excludePatterns" pattern that
+			// matches it, don't skip it. IOW, assume an explicit 'include'
+			// is asking for that file no matter what - which is true
+			// for some files, like .dockerignore and Dockerfile (sometimes)
+			if include != relFilePath {
+				skip, err = fileutils.OptimizedMatches(relFilePath, patterns, patDirs)
+				if err != nil {
+					logrus.Debugf("Error matching %s: %v", relFilePath, err)
+					return err
 				}
+			}
 
-				if err := ta.addTarFile(filePath, relFilePath); err != nil {
-					logrus.Debugf("Can't add file %s to tar: %s", filePath, err)
+			if skip {
+				if !exceptions && f.IsDir() {
+					return filepath.SkipDir
 				}
 				return nil
-			})
-		}
-	}()
+			}
 
-	return pipeReader, nil
+			if seen[relFilePath] {
+				return nil
+			}
+			seen[relFilePath] = true
+
+			// Rename the base resource.
+			if rebaseName != "" {
+				var replacement string
+				if rebaseName != string(filepath.Separator) {
+					// Special case the root directory to replace with an
+					// empty string instead so that we don't end up with
+					// double slashes in the paths.
+					replacement = rebaseName
+				}
+
+				relFilePath = strings.Replace(relFilePath, include, replacement, 1)
+			}
+
+			if walkErr = walkFn(filePath, relFilePath, f); walkErr != nil {
+				return walkErr
+			}
+			return nil
+		})
+		if walkErr != nil {
+			return walkErr
+		}
+	}
+	return nil
 }
 
 // Unpack unpacks the decompressedArchive to dest with options.
@@ -3,6 +3,7 @@ package archive
 import (
 	"archive/tar"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -10,6 +11,7 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"syscall"
 	"testing"
@@ -709,6 +711,58 @@ func TestTarWithOptions(t *testing.T) {
 	}
 }
 
+func TestWalkWithOptions(t *testing.T) {
+	origin, err := ioutil.TempDir("", "docker-test-walk-origin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(origin)
+	if err := os.MkdirAll(path.Join(origin, "folder", "sub"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"1", "2", "folder/3", "folder/sub/4"} {
+		if err := ioutil.WriteFile(path.Join(origin, name), []byte(name), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		opts     *TarOptions
+		expected []string
+	}{
+		{&TarOptions{}, []string{"1", "2", "folder", "folder/3", "folder/sub", "folder/sub/4"}},
+		{&TarOptions{ExcludePatterns: []string{"2", "folder/sub"}}, []string{"1", "folder", "folder/3"}},
+		{&TarOptions{ExcludePatterns: []string{"folder", "!folder/sub"}}, []string{"1", "2", "folder/sub", "folder/sub/4"}},
+		{&TarOptions{IncludeFiles: []string{"2", "folder/sub"}, ExcludePatterns: []string{"2", "folder/sub/4"}}, []string{"2", "folder/sub"}},
+	}
+	for _, testCase := range cases {
+		var walked []string
+		err := WalkWithOptions(origin, testCase.opts, func(filePath, relFilePath string, f os.FileInfo) error {
+			if filePath != path.Join(origin, relFilePath) {
+				t.Fatalf("Expected the path of %s to be in %s, got %s", relFilePath, origin, filePath)
+			}
+			walked = append(walked, filepath.ToSlash(relFilePath))
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(walked, testCase.expected) {
+			t.Errorf("Expected %v to be walked, got %v for %+v", testCase.expected, walked, testCase.opts)
+		}
+	}
+
+	stop := errors.New("stop")
+	var walked int
+	err = WalkWithOptions(origin, &TarOptions{}, func(filePath, relFilePath string, f os.FileInfo) error {
+		walked++
+		return stop
+	})
+	if err != stop || walked != 1 {
+		t.Fatalf("Expected the walk to stop with the error of the function, got %v after %d files", err, walked)
+	}
+}
+
 // Some tar archives such as http://haproxy.1wt.eu/download/1.5/src/devel/haproxy-1.5-dev21.tar.gz
 // use PAX Global Extended Headers.
 // Failing prevents the archives from being uncompressed during ADD
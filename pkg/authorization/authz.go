@@ -0,0 +1,218 @@
+package authorization
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/ioutils"
+)
+
+const maxBodySize = 1048576 // 1MB
+
+// errBodyTooLarge is returned by drainBody when the body is larger than
+// maxBodySize.
+var errBodyTooLarge = errors.New("body too large")
+
+// NewCtx creates new authZ context, it is used to store authorization information related to a specific docker
+// REST http session
+// A context provides two method:
+// Authenticate Request:
+// Call authZ plugins with current REST request and AuthN response
+// Request contains full HTTP packet sent to the docker daemon
+// https://docs.docker.com/reference/api/docker_remote_api/
+//
+// Authenticate Response:
+// Call authZ plugins with full info about current REST request, REST response and AuthN response
+// The response from this method may contains content that overrides the daemon response
+// This allows authZ plugins to filter privileged content
+//
+// If multiple authZ plugins are specified, the block/allow decision is based on ANDing all plugin results
+// For response manipulation, the response from each plugin is piped between plugins. Plugin execution order
+// is determined according to daemon parameters
+func NewCtx(authZPlugins []Plugin, user, userAuthNMethod, requestMethod, requestURI string) *Ctx {
+	return &Ctx{
+		plugins:         authZPlugins,
+		user:            user,
+		userAuthNMethod: userAuthNMethod,
+		requestMethod:   requestMethod,
+		requestURI:      requestURI,
+	}
+}
+
+// Ctx stores a single request-response interaction context
+type Ctx struct {
+	user            string
+	userAuthNMethod string
+	requestMethod   string
+	requestURI      string
+	plugins         []Plugin
+	// authReq stores the cached request object for the current transaction
+	authReq *Request
+}
+
+// DeniedError is returned when an authorization plugin denies a request or
+// a response.
+type DeniedError struct {
+	// Plugin is the name of the plugin which denied the request
+	Plugin string
+	// Msg is the message returned by the plugin
+	Msg string
+}
+
+func (e *DeniedError) Error() string {
+	if e.Plugin == "" {
+		return fmt.Sprintf("authorization denied: %s", e.Msg)
+	}
+	return fmt.Sprintf("authorization denied by plugin %s: %s", e.Plugin, e.Msg)
+}
+
+// AuthZRequest authorized the request to the docker daemon using authZ plugins
+func (ctx *Ctx) AuthZRequest(r *http.Request) error {
+	// The body is read even when its length is unknown, such as for a
+	// chunked request, and a body which is too large to be sent to the
+	// plugins is denied, so that the plugins cannot be bypassed.
+	var body []byte
+	if sendBody(ctx.requestURI, r.Header) && r.ContentLength != 0 {
+		var err error
+		if r.ContentLength > maxBodySize {
+			err = errBodyTooLarge
+		} else {
+			body, r.Body, err = drainBody(r.Body)
+		}
+		if err == errBodyTooLarge {
+			return &DeniedError{Msg: fmt.Sprintf("the request body is larger than %d bytes", maxBodySize)}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx.authReq = &Request{
+		User:            ctx.user,
+		UserAuthNMethod: ctx.userAuthNMethod,
+		RequestMethod:   ctx.requestMethod,
+		RequestURI:      ctx.requestURI,
+		RequestBody:     body,
+		RequestHeaders:  headers(r.Header),
+	}
+
+	for _, plugin := range ctx.plugins {
+		logrus.Debugf("AuthZ request using plugin %s", plugin.Name())
+
+		authRes, err := plugin.AuthZRequest(ctx.authReq)
+		if err != nil {
+			return fmt.Errorf("plugin %s failed with error: %s", plugin.Name(), err)
+		}
+
+		if authRes.Err != "" {
+			return fmt.Errorf("plugin %s failed with error: %s", plugin.Name(), authRes.Err)
+		}
+
+		if !authRes.Allow {
+			return &DeniedError{Plugin: plugin.Name(), Msg: authRes.Msg}
+		}
+	}
+
+	return nil
+}
+
+// AuthZResponse authorized and manipulates the response from docker daemon using authZ plugins
+func (ctx *Ctx) AuthZResponse(rm ResponseModifier) error {
+	ctx.authReq.ResponseStatusCode = rm.StatusCode()
+	ctx.authReq.ResponseHeaders = rm.RawHeaders()
+
+	if sendBody(ctx.requestURI, rm.Header()) {
+		ctx.authReq.ResponseBody = rm.RawBody()
+	}
+
+	for _, plugin := range ctx.plugins {
+		logrus.Debugf("AuthZ response using plugin %s", plugin.Name())
+
+		authRes, err := plugin.AuthZResponse(ctx.authReq)
+		if err != nil {
+			return fmt.Errorf("plugin %s failed with error: %s", plugin.Name(), err)
+		}
+
+		if authRes.Err != "" {
+			return fmt.Errorf("plugin %s failed with error: %s", plugin.Name(), authRes.Err)
+		}
+
+		if !authRes.Allow {
+			return &DeniedError{Plugin: plugin.Name(), Msg: authRes.Msg}
+		}
+
+		// A response which was hijacked or already sent to the client
+		// cannot be modified anymore.
+		if rm.Hijacked() || rm.Streamed() {
+			continue
+		}
+
+		// The modified response is piped to the next plugins
+		if authRes.ModifiedStatusCode > 0 {
+			rm.OverrideStatusCode(authRes.ModifiedStatusCode)
+			ctx.authReq.ResponseStatusCode = authRes.ModifiedStatusCode
+		}
+		if authRes.ModifiedHeaders != nil {
+			rm.OverrideHeader(authRes.ModifiedHeaders)
+			ctx.authReq.ResponseHeaders = authRes.ModifiedHeaders
+		}
+		if authRes.ModifiedBody != nil {
+			rm.OverrideBody(authRes.ModifiedBody)
+			ctx.authReq.ResponseBody = authRes.ModifiedBody
+		}
+	}
+
+	return nil
+}
+
+// drainBody dump the body, it reads the body data into memory and
+// see go sources /go/src/net/http/httputil/dump.go
+// It returns errBodyTooLarge if the body is larger than maxBodySize.
+func drainBody(body io.ReadCloser) ([]byte, io.ReadCloser, error) {
+	bufReader := bufio.NewReaderSize(body, maxBodySize+1)
+	newBody := ioutils.NewReadCloserWrapper(bufReader, func() error { return body.Close() })
+
+	data, err := bufReader.Peek(maxBodySize + 1)
+	if err == nil {
+		// This means the request is larger than our max
+		return nil, nil, errBodyTooLarge
+	}
+	if err != io.EOF {
+		// This means we had an error reading
+		return nil, nil, err
+	}
+
+	return data, newBody, nil
+}
+
+// sendBody returns true when request/response body should be sent to AuthZPlugin
+func sendBody(url string, header http.Header) bool {
+	// Skip body for auth endpoint
+	if strings.HasSuffix(url, "/auth") {
+		return false
+	}
+
+	// body is sent only for text or json messages
+	return strings.HasPrefix(header.Get("Content-Type"), "application/json")
+}
+
+// headers returns flatten version of the http headers excluding authorization
+// and build secrets
+func headers(header http.Header) map[string]string {
+	v := make(map[string]string, 0)
+	for k, values := range header {
+		// Skip authorization and build secrets headers
+		if strings.EqualFold(k, "Authorization") || strings.EqualFold(k, "X-Registry-Config") || strings.EqualFold(k, "X-Registry-Auth") || strings.EqualFold(k, "X-Build-Secrets") {
+			continue
+		}
+		for _, val := range values {
+			v[k] = val
+		}
+	}
+	return v
+}
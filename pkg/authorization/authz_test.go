@@ -0,0 +1,312 @@
+package authorization
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakePlugin is a Plugin which records the requests it receives and returns
+// canned responses.
+type fakePlugin struct {
+	name     string
+	res      Response
+	reqRes   Response
+	requests []*Request
+}
+
+func (p *fakePlugin) Name() string {
+	return p.name
+}
+
+func (p *fakePlugin) AuthZRequest(r *Request) (*Response, error) {
+	p.requests = append(p.requests, r)
+	res := p.reqRes
+	return &res, nil
+}
+
+func (p *fakePlugin) AuthZResponse(r *Request) (*Response, error) {
+	p.requests = append(p.requests, r)
+	res := p.res
+	return &res, nil
+}
+
+func TestAuthZRequestAllow(t *testing.T) {
+	body := `{"Image":"busybox"}`
+	plugin := &fakePlugin{name: "allow", reqRes: Response{Allow: true}}
+	r, err := http.NewRequest("POST", "/containers/create", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-Registry-Auth", "secret")
+	r.Header.Set("X-Build-Secrets", "secret")
+
+	ctx := NewCtx([]Plugin{plugin}, "user", "TLS", "POST", "/containers/create")
+	if err := ctx.AuthZRequest(r); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plugin.requests) != 1 {
+		t.Fatalf("Expected 1 call to the plugin, got %d", len(plugin.requests))
+	}
+	req := plugin.requests[0]
+	if req.User != "user" || req.UserAuthNMethod != "TLS" || req.RequestMethod != "POST" || req.RequestURI != "/containers/create" {
+		t.Fatalf("Unexpected request sent to the plugin: %+v", req)
+	}
+	if string(req.RequestBody) != body {
+		t.Fatalf("Expected body %q, got %q", body, req.RequestBody)
+	}
+	if _, ok := req.RequestHeaders["X-Registry-Auth"]; ok {
+		t.Fatal("Expected the registry auth header not to be sent to the plugin")
+	}
+	if _, ok := req.RequestHeaders["X-Build-Secrets"]; ok {
+		t.Fatal("Expected the build secrets header not to be sent to the plugin")
+	}
+
+	// The body must still be readable by the handler
+	buf := new(bytes.Buffer)
+	if _, err := buf.ReadFrom(r.Body); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != body {
+		t.Fatalf("Expected request body %q after authorization, got %q", body, buf.String())
+	}
+}
+
+func TestAuthZRequestDeny(t *testing.T) {
+	first := &fakePlugin{name: "first", reqRes: Response{Allow: false, Msg: "not allowed"}}
+	second := &fakePlugin{name: "second", reqRes: Response{Allow: true}}
+	r, err := http.NewRequest("GET", "/info", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := NewCtx([]Plugin{first, second}, "user", "TLS", "GET", "/info")
+	err = ctx.AuthZRequest(r)
+	denied, ok := err.(*DeniedError)
+	if !ok {
+		t.Fatalf("Expected a DeniedError, got %v", err)
+	}
+	if denied.Plugin != "first" || denied.Msg != "not allowed" {
+		t.Fatalf("Unexpected denial: %+v", denied)
+	}
+	if len(second.requests) != 0 {
+		t.Fatal("Expected the second plugin not to be consulted")
+	}
+}
+
+func TestAuthZRequestBodyUnknownLength(t *testing.T) {
+	body := `{"Image":"busybox"}`
+	plugin := &fakePlugin{name: "allow", reqRes: Response{Allow: true}}
+	r, err := http.NewRequest("POST", "/containers/create", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/json")
+	// a chunked request
+	r.ContentLength = -1
+
+	ctx := NewCtx([]Plugin{plugin}, "user", "TLS", "POST", "/containers/create")
+	if err := ctx.AuthZRequest(r); err != nil {
+		t.Fatal(err)
+	}
+	if string(plugin.requests[0].RequestBody) != body {
+		t.Fatalf("Expected body %q, got %q", body, plugin.requests[0].RequestBody)
+	}
+}
+
+func TestAuthZRequestBodyTooLarge(t *testing.T) {
+	body := `{"Image":"` + strings.Repeat("a", maxBodySize) + `"}`
+	for _, length := range []int64{int64(len(body)), -1} {
+		plugin := &fakePlugin{name: "allow", reqRes: Response{Allow: true}}
+		r, err := http.NewRequest("POST", "/containers/create", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Header.Set("Content-Type", "application/json")
+		r.ContentLength = length
+
+		ctx := NewCtx([]Plugin{plugin}, "user", "TLS", "POST", "/containers/create")
+		if _, ok := ctx.AuthZRequest(r).(*DeniedError); !ok {
+			t.Fatalf("Expected the request with the content length %d to be denied", length)
+		}
+		if len(plugin.requests) != 0 {
+			t.Fatal("Expected the plugin not to be consulted")
+		}
+	}
+}
+
+func TestAuthZRequestPluginError(t *testing.T) {
+	plugin := &fakePlugin{name: "broken", reqRes: Response{Err: "internal failure"}}
+	r, err := http.NewRequest("GET", "/info", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := NewCtx([]Plugin{plugin}, "user", "TLS", "GET", "/info")
+	err = ctx.AuthZRequest(r)
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if _, ok := err.(*DeniedError); ok {
+		t.Fatalf("Expected a plugin error, not a denial: %v", err)
+	}
+	if !strings.Contains(err.Error(), "internal failure") {
+		t.Fatalf("Expected the plugin error in %q", err)
+	}
+}
+
+func TestAuthZResponseOverride(t *testing.T) {
+	first := &fakePlugin{
+		name:   "first",
+		reqRes: Response{Allow: true},
+		res: Response{
+			Allow:              true,
+			ModifiedStatusCode: http.StatusAccepted,
+			ModifiedBody:       []byte(`{"filtered":true}`),
+			ModifiedHeaders:    map[string]string{"Content-Type": "application/json", "X-Filtered": "1"},
+		},
+	}
+	second := &fakePlugin{name: "second", reqRes: Response{Allow: true}, res: Response{Allow: true}}
+	r, err := http.NewRequest("GET", "/containers/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := NewCtx([]Plugin{first, second}, "user", "TLS", "GET", "/containers/json")
+	if err := ctx.AuthZRequest(r); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	rm := NewResponseModifier(recorder)
+	rm.Header().Set("Content-Type", "application/json")
+	rm.WriteHeader(http.StatusOK)
+	fmt.Fprint(rm, `[{"Id":"abc"}]`)
+
+	if err := ctx.AuthZResponse(rm); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second plugin sees the response modified by the first one
+	req := second.requests[len(second.requests)-1]
+	if req.ResponseStatusCode != http.StatusAccepted {
+		t.Fatalf("Expected status code %d piped to the second plugin, got %d", http.StatusAccepted, req.ResponseStatusCode)
+	}
+	if string(req.ResponseBody) != `{"filtered":true}` {
+		t.Fatalf("Expected the modified body piped to the second plugin, got %q", req.ResponseBody)
+	}
+
+	if err := rm.FlushAll(); err != nil {
+		t.Fatal(err)
+	}
+	if recorder.Code != http.StatusAccepted {
+		t.Fatalf("Expected status code %d, got %d", http.StatusAccepted, recorder.Code)
+	}
+	if recorder.Body.String() != `{"filtered":true}` {
+		t.Fatalf("Expected the modified body, got %q", recorder.Body.String())
+	}
+	if recorder.Header().Get("X-Filtered") != "1" {
+		t.Fatalf("Expected the modified headers, got %v", recorder.Header())
+	}
+}
+
+func TestAuthZResponseDeny(t *testing.T) {
+	plugin := &fakePlugin{name: "deny", reqRes: Response{Allow: true}, res: Response{Allow: false, Msg: "secret"}}
+	r, err := http.NewRequest("GET", "/info", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := NewCtx([]Plugin{plugin}, "user", "TLS", "GET", "/info")
+	if err := ctx.AuthZRequest(r); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	rm := NewResponseModifier(recorder)
+	fmt.Fprint(rm, "private data")
+
+	if _, ok := ctx.AuthZResponse(rm).(*DeniedError); !ok {
+		t.Fatal("Expected the response to be denied")
+	}
+	if recorder.Body.Len() != 0 {
+		t.Fatalf("Expected nothing to be sent to the client, got %q", recorder.Body.String())
+	}
+}
+
+func TestResponseModifierStreamed(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	rm := NewResponseModifier(recorder)
+	rm.Header().Set("X-Test", "1")
+	fmt.Fprint(rm, "first")
+	rm.Flush()
+
+	if !rm.Streamed() {
+		t.Fatal("Expected the response to be streamed after a flush")
+	}
+	fmt.Fprint(rm, "second")
+
+	// A streamed response can't be overridden
+	rm.OverrideBody([]byte("overridden"))
+	if err := rm.FlushAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	if recorder.Body.String() != "firstsecond" {
+		t.Fatalf("Expected the streamed body, got %q", recorder.Body.String())
+	}
+	if recorder.Header().Get("X-Test") != "1" {
+		t.Fatalf("Expected the buffered headers to be sent, got %v", recorder.Header())
+	}
+}
+
+func TestResponseModifierLargeBody(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	rm := NewResponseModifier(recorder)
+	chunk := bytes.Repeat([]byte("a"), 1024)
+	for i := 0; i < 2048; i++ {
+		if _, err := rm.Write(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !rm.Streamed() {
+		t.Fatal("Expected a large response to be streamed")
+	}
+	if len(rm.RawBody()) != 0 {
+		t.Fatalf("Expected nothing to be buffered, got %d bytes", len(rm.RawBody()))
+	}
+	if err := rm.FlushAll(); err != nil {
+		t.Fatal(err)
+	}
+	if recorder.Body.Len() != 2048*1024 {
+		t.Fatalf("Expected the whole body to be sent, got %d bytes", recorder.Body.Len())
+	}
+}
+
+func TestRawHeaders(t *testing.T) {
+	rm := NewResponseModifier(httptest.NewRecorder())
+	rm.Header().Set("Content-Type", "application/json")
+	rm.Header().Set("X-Test", "value")
+
+	expected := map[string]string{"Content-Type": "application/json", "X-Test": "value"}
+	if headers := rm.RawHeaders(); !reflect.DeepEqual(headers, expected) {
+		t.Fatalf("Expected headers %v, got %v", expected, headers)
+	}
+}
+
+func TestNewPluginsDeduplicates(t *testing.T) {
+	plugins := NewPlugins([]string{"a", "b", "a"})
+	if len(plugins) != 2 {
+		t.Fatalf("Expected 2 plugins, got %d", len(plugins))
+	}
+	if plugins[0].Name() != "a" || plugins[1].Name() != "b" {
+		t.Fatalf("Expected plugins in order a, b, got %s, %s", plugins[0].Name(), plugins[1].Name())
+	}
+}
@@ -0,0 +1,226 @@
+package authorization
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// ResponseModifier allows authorization plugins to read and modify the content of the http.response
+type ResponseModifier interface {
+	http.ResponseWriter
+	http.Flusher
+	http.CloseNotifier
+
+	// RawBody returns the current http content
+	RawBody() []byte
+
+	// RawHeaders returns the current content of the http headers
+	RawHeaders() map[string]string
+
+	// StatusCode returns the current status code
+	StatusCode() int
+
+	// OverrideBody replaces the body of the HTTP response
+	OverrideBody(b []byte)
+
+	// OverrideHeader replaces the headers of the HTTP response
+	OverrideHeader(headers map[string]string)
+
+	// OverrideStatusCode replaces the status code of the HTTP response
+	OverrideStatusCode(statusCode int)
+
+	// FlushAll flushes all data to the HTTP response
+	FlushAll() error
+
+	// Hijacked indicates the response has been hijacked by the Docker daemon
+	Hijacked() bool
+
+	// Streamed indicates that the response has already been sent, at least in
+	// part, to the client, so it can no longer be modified
+	Streamed() bool
+}
+
+// NewResponseModifier creates a wrapper to an http.ResponseWriter to allow inspecting and modifying the content
+func NewResponseModifier(rw http.ResponseWriter) ResponseModifier {
+	return &responseModifier{rw: rw, header: make(http.Header)}
+}
+
+// responseModifier is used as an adapter to http.ResponseWriter in order to manipulate and explore
+// the http request/response from docker daemon
+type responseModifier struct {
+	// The original response writer
+	rw http.ResponseWriter
+	// body holds the response body until it is flushed
+	body bytes.Buffer
+	// header holds the response header until it is flushed
+	header http.Header
+	// statusCode holds the response status code
+	statusCode int
+	// hijacked indicates the request has been hijacked
+	hijacked bool
+	// streamed indicates the response has been flushed to the client
+	streamed bool
+}
+
+func (rm *responseModifier) Hijacked() bool {
+	return rm.hijacked
+}
+
+func (rm *responseModifier) Streamed() bool {
+	return rm.streamed
+}
+
+// WriteHeader stores the http status code
+func (rm *responseModifier) WriteHeader(s int) {
+	// Use original request if hijacked or streamed
+	if rm.hijacked || rm.streamed {
+		rm.rw.WriteHeader(s)
+		return
+	}
+
+	rm.statusCode = s
+}
+
+// Header returns the internal http header
+func (rm *responseModifier) Header() http.Header {
+	// Use original header if hijacked or streamed
+	if rm.hijacked || rm.streamed {
+		return rm.rw.Header()
+	}
+
+	return rm.header
+}
+
+// StatusCode returns the http status code
+func (rm *responseModifier) StatusCode() int {
+	if rm.statusCode == 0 {
+		return http.StatusOK
+	}
+	return rm.statusCode
+}
+
+// OverrideBody replaces the body of the HTTP response
+func (rm *responseModifier) OverrideBody(b []byte) {
+	rm.body.Reset()
+	rm.body.Write(b)
+}
+
+// OverrideStatusCode replaces the status code of the HTTP response
+func (rm *responseModifier) OverrideStatusCode(statusCode int) {
+	rm.statusCode = statusCode
+}
+
+// OverrideHeader replaces the headers of the HTTP response
+func (rm *responseModifier) OverrideHeader(headers map[string]string) {
+	rm.header = make(http.Header)
+	for k, v := range headers {
+		rm.header.Set(k, v)
+	}
+}
+
+// Write stores the byte array inside content. A response larger than
+// maxBodySize, such as the archive of an image being saved, is not kept in
+// memory: it is streamed to the client from then on, like a flushed one.
+func (rm *responseModifier) Write(b []byte) (int, error) {
+	if rm.hijacked || rm.streamed {
+		return rm.rw.Write(b)
+	}
+
+	if rm.body.Len()+len(b) > maxBodySize {
+		rm.streamed = true
+		if err := rm.flushBuffered(); err != nil {
+			return 0, err
+		}
+		return rm.rw.Write(b)
+	}
+	return rm.body.Write(b)
+}
+
+// RawBody returns the response body
+func (rm *responseModifier) RawBody() []byte {
+	return rm.body.Bytes()
+}
+
+// RawHeaders returns the response headers
+func (rm *responseModifier) RawHeaders() map[string]string {
+	headers := make(map[string]string)
+	for k := range rm.header {
+		headers[k] = rm.header.Get(k)
+	}
+	return headers
+}
+
+// Hijack returns the internal connection of the wrapped http.ResponseWriter
+func (rm *responseModifier) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rm.rw.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("Internal response writer doesn't support the Hijacker interface")
+	}
+
+	rm.hijacked = true
+	return hijacker.Hijack()
+}
+
+// CloseNotify uses the internal close notify API of the wrapped http.ResponseWriter
+func (rm *responseModifier) CloseNotify() <-chan bool {
+	closeNotifier, ok := rm.rw.(http.CloseNotifier)
+	if !ok {
+		logrus.Errorf("Internal response writer doesn't support the CloseNotifier interface")
+		return nil
+	}
+	return closeNotifier.CloseNotify()
+}
+
+// Flush sends the buffered response to the client, and uses the internal
+// flush API of the wrapped http.ResponseWriter. Once flushed, the response
+// is streamed directly to the client.
+func (rm *responseModifier) Flush() {
+	flusher, ok := rm.rw.(http.Flusher)
+	if !ok {
+		logrus.Errorf("Internal response writer doesn't support the Flusher interface")
+		return
+	}
+
+	if !rm.streamed {
+		rm.streamed = true
+		if err := rm.flushBuffered(); err != nil {
+			logrus.Errorf("Failed to flush the response: %v", err)
+			return
+		}
+	}
+	flusher.Flush()
+}
+
+// FlushAll flushes all data to the HTTP response
+func (rm *responseModifier) FlushAll() error {
+	if rm.hijacked || rm.streamed {
+		return nil
+	}
+
+	// Mark the response as sent, so that the buffered data is only written once
+	rm.streamed = true
+	return rm.flushBuffered()
+}
+
+// flushBuffered writes the buffered headers, status code and body to the
+// wrapped http.ResponseWriter.
+func (rm *responseModifier) flushBuffered() error {
+	for k, v := range rm.header {
+		rm.rw.Header()[k] = v
+	}
+	if rm.statusCode > 0 {
+		rm.rw.WriteHeader(rm.statusCode)
+	}
+
+	if rm.body.Len() == 0 {
+		return nil
+	}
+	_, err := rm.rw.Write(rm.body.Bytes())
+	rm.body.Reset()
+	return err
+}
@@ -88,20 +88,42 @@ func (p *JSONProgress) String() string {
 	return pbBox + numbersBox + timeLeftBox
 }
 
+// JSONBuildStep describes a step of a build once it is done. Duration is
+// expressed in nanoseconds, and Output holds the last lines written by the
+// container of the step, up to 64KB.
+type JSONBuildStep struct {
+	Index       int           `json:"index"`
+	Total       int           `json:"total"`
+	Instruction string        `json:"instruction"`
+	Cached      bool          `json:"cached"`
+	ImageID     string        `json:"imageID,omitempty"`
+	Duration    time.Duration `json:"duration"`
+	Output      []string      `json:"output,omitempty"`
+	Error       string        `json:"error,omitempty"`
+}
+
+// JSONBuildResult describes the result of a successful build, which is the
+// last message of the stream of the build.
+type JSONBuildResult struct {
+	ImageID string `json:"imageID"`
+}
+
 // JSONMessage defines a message struct. It describes
 // the created time, where it from, status, ID of the
 // message. It's used for docker events.
 type JSONMessage struct {
-	Stream          string        `json:"stream,omitempty"`
-	Status          string        `json:"status,omitempty"`
-	Progress        *JSONProgress `json:"progressDetail,omitempty"`
-	ProgressMessage string        `json:"progress,omitempty"` //deprecated
-	ID              string        `json:"id,omitempty"`
-	From            string        `json:"from,omitempty"`
-	Time            int64         `json:"time,omitempty"`
-	TimeNano        int64         `json:"timeNano,omitempty"`
-	Error           *JSONError    `json:"errorDetail,omitempty"`
-	ErrorMessage    string        `json:"error,omitempty"` //deprecated
+	Stream          string           `json:"stream,omitempty"`
+	Status          string           `json:"status,omitempty"`
+	Progress        *JSONProgress    `json:"progressDetail,omitempty"`
+	ProgressMessage string           `json:"progress,omitempty"` //deprecated
+	ID              string           `json:"id,omitempty"`
+	From            string           `json:"from,omitempty"`
+	Time            int64            `json:"time,omitempty"`
+	TimeNano        int64            `json:"timeNano,omitempty"`
+	Error           *JSONError       `json:"errorDetail,omitempty"`
+	ErrorMessage    string           `json:"error,omitempty"` //deprecated
+	BuildStep       *JSONBuildStep   `json:"buildStep,omitempty"`
+	BuildResult     *JSONBuildResult `json:"buildResult,omitempty"`
 }
 
 // Display displays the JSONMessage to `out`. `isTerminal` describes if `out`
@@ -114,6 +136,11 @@ func (jm *JSONMessage) Display(out io.Writer, isTerminal bool) error {
 		}
 		return jm.Error
 	}
+	if jm.BuildStep != nil || jm.BuildResult != nil {
+		// the steps and the result of a build are only reported to
+		// clients which handle them
+		return nil
+	}
 	var endl string
 	if isTerminal && jm.Stream == "" && jm.Progress != nil {
 		// <ESC>[2K = erase entire current line
@@ -149,6 +176,13 @@ func (jm *JSONMessage) Display(out io.Writer, isTerminal bool) error {
 // describes if `out` is a terminal. If this is the case, it will print `\n` at the end of
 // each line and move the cursor while displaying.
 func DisplayJSONMessagesStream(in io.Reader, out io.Writer, terminalFd uintptr, isTerminal bool) error {
+	return DisplayJSONMessagesStreamWithResult(in, out, terminalFd, isTerminal, nil)
+}
+
+// DisplayJSONMessagesStreamWithResult displays a json message stream like
+// DisplayJSONMessagesStream, and calls `onResult`, if not nil, with the result
+// of the build reported in the stream.
+func DisplayJSONMessagesStreamWithResult(in io.Reader, out io.Writer, terminalFd uintptr, isTerminal bool, onResult func(*JSONBuildResult)) error {
 	var (
 		dec  = json.NewDecoder(in)
 		ids  = make(map[string]int)
@@ -163,6 +197,9 @@ func DisplayJSONMessagesStream(in io.Reader, out io.Writer, terminalFd uintptr,
 			return err
 		}
 
+		if jm.BuildResult != nil && onResult != nil {
+			onResult(jm.BuildResult)
+		}
 		if jm.Progress != nil {
 			jm.Progress.terminalFd = terminalFd
 		}
@@ -160,6 +160,40 @@ func TestJSONMessageDisplayWithJSONError(t *testing.T) {
 	}
 }
 
+// Test JSONMessage with a build step, which is not displayed.
+func TestJSONMessageDisplayWithBuildStep(t *testing.T) {
+	data := bytes.NewBuffer([]byte{})
+	jsonMessage := JSONMessage{BuildStep: &JSONBuildStep{Index: 1, Total: 1, Instruction: "FROM busybox"}}
+
+	if err := jsonMessage.Display(data, false); err != nil {
+		t.Fatal(err)
+	}
+	if data.Len() != 0 {
+		t.Fatalf("Expected no output, got [%v]", data.String())
+	}
+}
+
+func TestDisplayJSONMessagesStreamWithResult(t *testing.T) {
+	var (
+		inFd   uintptr
+		result *JSONBuildResult
+	)
+	data := bytes.NewBuffer([]byte{})
+	reader := strings.NewReader(`{"stream":"Successfully built abc\n"}{"buildResult":{"imageID":"sha256:abc"}}`)
+	onResult := func(r *JSONBuildResult) {
+		result = r
+	}
+	if err := DisplayJSONMessagesStreamWithResult(reader, data, inFd, false, onResult); err != nil {
+		t.Fatal(err)
+	}
+	if result == nil || result.ImageID != "sha256:abc" {
+		t.Fatalf("Expected the result of the build, got %v", result)
+	}
+	if data.String() != "Successfully built abc\n" {
+		t.Fatalf("Expected only the stream to be displayed, got [%v]", data.String())
+	}
+}
+
 func TestDisplayJSONMessagesStreamInvalidJSON(t *testing.T) {
 	var (
 		inFd uintptr
@@ -22,6 +22,23 @@ func TestMountOptionsParsing(t *testing.T) {
 	}
 }
 
+func TestParseTmpfsOptions(t *testing.T) {
+	flag, data, err := ParseTmpfsOptions("noexec,nosuid,size=64m,mode=1777")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data != "size=64m,mode=1777" {
+		t.Fatalf("Expected size=64m,mode=1777 got %s", data)
+	}
+	if expectedFlag := NOEXEC | NOSUID; flag != expectedFlag {
+		t.Fatalf("Expected %d got %d", expectedFlag, flag)
+	}
+
+	if _, _, err := ParseTmpfsOptions("size=64m,foo=bar"); err == nil {
+		t.Fatal("Expected an error for an invalid tmpfs option")
+	}
+}
+
 func TestMounted(t *testing.T) {
 	tmp := path.Join(os.TempDir(), "mount-tests")
 	if err := os.MkdirAll(tmp, 0777); err != nil {
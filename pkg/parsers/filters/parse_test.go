@@ -216,3 +216,38 @@ func TestArgsMatch(t *testing.T) {
 		}
 	}
 }
+
+func TestArgsExactMatch(t *testing.T) {
+	args := Args{"type": []string{"container", "image"}}
+	if !args.ExactMatch("type", "image") {
+		t.Fatal("Expected image to match exactly")
+	}
+	if args.ExactMatch("type", "imag") {
+		t.Fatal("Expected imag not to match exactly")
+	}
+	if !args.ExactMatch("network", "anything") {
+		t.Fatal("Expected a missing filter to match everything")
+	}
+}
+
+func TestArgsFuzzyMatch(t *testing.T) {
+	args := Args{"container": []string{"abc"}}
+	for _, source := range []string{"abc", "abcdef"} {
+		if !args.FuzzyMatch("container", source) {
+			t.Fatalf("Expected %s to match", source)
+		}
+	}
+	if args.FuzzyMatch("container", "xabc") {
+		t.Fatal("Expected xabc not to match")
+	}
+}
+
+func TestArgsInclude(t *testing.T) {
+	args := Args{"type": []string{"container"}}
+	if !args.Include("type") {
+		t.Fatal("Expected type to be included")
+	}
+	if args.Include("volume") {
+		t.Fatal("Expected volume not to be included")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func setup(t *testing.T) (string, func()) {
@@ -73,6 +74,19 @@ func TestLocalSocket(t *testing.T) {
 	}
 }
 
+func TestGetNoRetry(t *testing.T) {
+	_, unregister := setup(t)
+	defer unregister()
+
+	start := time.Now()
+	if _, err := GetNoRetry("unknown", "LogDriver"); err != ErrNotFound {
+		t.Fatalf("Expected error %v, got %v", ErrNotFound, err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("Expected the lookup to fail immediately, took %v", d)
+	}
+}
+
 func TestFileSpecPlugin(t *testing.T) {
 	tmpdir, unregister := setup(t)
 	defer unregister()
@@ -0,0 +1,57 @@
+// Package logdriver defines the log entries exchanged between the daemon and
+// the log driver plugins.
+//
+// The daemon writes the messages of a container to the stream given to the
+// plugin by the /LogDriver.StartLogging call, and the plugin writes the
+// messages it reads back to the response of the /LogDriver.ReadLogs call. Both
+// streams are a sequence of JSON encoded LogEntry objects.
+package logdriver
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// LogEntry is a message written by a container.
+type LogEntry struct {
+	// Source is the stream the message was written to, "stdout" or "stderr".
+	Source string `json:"source"`
+	// TimeNano is the time of the message, in nanoseconds since the epoch.
+	TimeNano int64 `json:"timeNano"`
+	// Line is the message, without the trailing newline.
+	Line []byte `json:"line"`
+	// Partial is set if the message is a fragment of a long line, which
+	// continues in the next message of the same source.
+	Partial bool `json:"partial,omitempty"`
+}
+
+// LogEntryEncoder writes log entries to a stream.
+type LogEntryEncoder struct {
+	enc *json.Encoder
+}
+
+// NewLogEntryEncoder returns a LogEntryEncoder writing to w.
+func NewLogEntryEncoder(w io.Writer) *LogEntryEncoder {
+	return &LogEntryEncoder{enc: json.NewEncoder(w)}
+}
+
+// Encode writes the entry to the stream.
+func (e *LogEntryEncoder) Encode(entry *LogEntry) error {
+	return e.enc.Encode(entry)
+}
+
+// LogEntryDecoder reads log entries from a stream.
+type LogEntryDecoder struct {
+	dec *json.Decoder
+}
+
+// NewLogEntryDecoder returns a LogEntryDecoder reading from r.
+func NewLogEntryDecoder(r io.Reader) *LogEntryDecoder {
+	return &LogEntryDecoder{dec: json.NewDecoder(r)}
+}
+
+// Decode reads the next entry of the stream into entry. It returns io.EOF at
+// the end of the stream.
+func (d *LogEntryDecoder) Decode(entry *LogEntry) error {
+	return d.dec.Decode(entry)
+}
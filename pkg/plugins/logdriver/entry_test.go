@@ -0,0 +1,39 @@
+package logdriver
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestLogEntryEncodeDecode(t *testing.T) {
+	entries := []LogEntry{
+		{Source: "stdout", TimeNano: 1, Line: []byte("hello")},
+		{Source: "stderr", TimeNano: 2, Line: []byte("not\x00utf8 \xff")},
+		{Source: "stdout", TimeNano: 3, Line: []byte{}},
+	}
+
+	buf := new(bytes.Buffer)
+	enc := NewLogEntryEncoder(buf)
+	for i := range entries {
+		if err := enc.Encode(&entries[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dec := NewLogEntryDecoder(buf)
+	for i := range entries {
+		var entry LogEntry
+		if err := dec.Decode(&entry); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(entry, entries[i]) {
+			t.Fatalf("Expected %v, got %v", entries[i], entry)
+		}
+	}
+	var entry LogEntry
+	if err := dec.Decode(&entry); err != io.EOF {
+		t.Fatalf("Expected EOF at the end of the stream, got %v", err)
+	}
+}
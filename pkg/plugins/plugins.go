@@ -152,18 +152,33 @@ func loadWithRetry(name string, retry bool) (*Plugin, error) {
 }
 
 func get(name string) (*Plugin, error) {
+	return getWithRetry(name, true)
+}
+
+func getWithRetry(name string, retry bool) (*Plugin, error) {
 	storage.Lock()
 	pl, ok := storage.plugins[name]
 	storage.Unlock()
 	if ok {
 		return pl, pl.activate()
 	}
-	return load(name)
+	return loadWithRetry(name, retry)
 }
 
 // Get returns the plugin given the specified name and requested implementation.
 func Get(name, imp string) (*Plugin, error) {
-	pl, err := get(name)
+	return getImplementing(name, imp, true)
+}
+
+// GetNoRetry returns the plugin given the specified name and requested
+// implementation, like Get, but fails immediately if the plugin cannot be
+// located rather than retrying until the plugin is available.
+func GetNoRetry(name, imp string) (*Plugin, error) {
+	return getImplementing(name, imp, false)
+}
+
+func getImplementing(name, imp string, retry bool) (*Plugin, error) {
+	pl, err := getWithRetry(name, retry)
 	if err != nil {
 		return nil, err
 	}
@@ -92,6 +92,31 @@ func (sf *StreamFormatter) FormatProgress(id, action string, progress *jsonmessa
 	return []byte(action + " " + progress.String() + endl)
 }
 
+// FormatBuildStep formats the description of a step of a build.
+func (sf *StreamFormatter) FormatBuildStep(step *jsonmessage.JSONBuildStep) []byte {
+	if sf.json {
+		b, err := json.Marshal(&jsonmessage.JSONMessage{BuildStep: step})
+		if err != nil {
+			return sf.FormatError(err)
+		}
+		return append(b, streamNewlineBytes...)
+	}
+	return []byte(fmt.Sprintf("Step %d/%d : %s (%s)%s", step.Index, step.Total, step.Instruction, step.Duration, streamNewline))
+}
+
+// FormatBuildResult formats the result of a successful build, which built
+// the image `imageID`.
+func (sf *StreamFormatter) FormatBuildResult(imageID string) []byte {
+	if sf.json {
+		b, err := json.Marshal(&jsonmessage.JSONMessage{BuildResult: &jsonmessage.JSONBuildResult{ImageID: imageID}})
+		if err != nil {
+			return sf.FormatError(err)
+		}
+		return append(b, streamNewlineBytes...)
+	}
+	return []byte(imageID + streamNewline)
+}
+
 // StdoutFormatter is a streamFormatter that writes to the standard output.
 type StdoutFormatter struct {
 	io.Writer
@@ -91,3 +91,29 @@ func TestJSONFormatProgress(t *testing.T) {
 		t.Fatal("Original progress not equals progress from FormatProgress")
 	}
 }
+
+func TestJSONFormatBuildStep(t *testing.T) {
+	sf := NewJSONStreamFormatter()
+	step := &jsonmessage.JSONBuildStep{
+		Index:       2,
+		Total:       3,
+		Instruction: "RUN echo foo",
+		ImageID:     "sha256:abc",
+		Duration:    1500,
+		Output:      []string{"foo"},
+	}
+	res := sf.FormatBuildStep(step)
+	expected := `{"buildStep":{"index":2,"total":3,"instruction":"RUN echo foo","cached":false,"imageID":"sha256:abc","duration":1500,"output":["foo"]}}` + "\r\n"
+	if string(res) != expected {
+		t.Fatalf("%q", res)
+	}
+}
+
+func TestJSONFormatBuildResult(t *testing.T) {
+	sf := NewJSONStreamFormatter()
+	res := sf.FormatBuildResult("sha256:abc")
+	expected := `{"buildResult":{"imageID":"sha256:abc"}}` + "\r\n"
+	if string(res) != expected {
+		t.Fatalf("%q", res)
+	}
+}
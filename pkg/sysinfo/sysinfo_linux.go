@@ -19,6 +19,7 @@ func New(quiet bool) *SysInfo {
 	sysInfo.cgroupCPUInfo = checkCgroupCPU(quiet)
 	sysInfo.cgroupBlkioInfo = checkCgroupBlkioInfo(quiet)
 	sysInfo.cgroupCpusetInfo = checkCgroupCpusetInfo(quiet)
+	sysInfo.cgroupPids = checkCgroupPids(quiet)
 
 	_, err := cgroups.FindCgroupMountpoint("devices")
 	sysInfo.CgroupDevicesEnabled = err == nil
@@ -159,6 +160,23 @@ func checkCgroupCpusetInfo(quiet bool) cgroupCpusetInfo {
 	}
 }
 
+// checkCgroupPids reads the pids information from the pids cgroup mount point.
+// The limit files only exist in child cgroups, so the controller being
+// mounted is enough to know that it is supported.
+func checkCgroupPids(quiet bool) cgroupPids {
+	_, err := cgroups.FindCgroupMountpoint("pids")
+	if err != nil {
+		if !quiet {
+			logrus.Warnf("Your kernel does not support cgroup pids limit: %v", err)
+		}
+		return cgroupPids{}
+	}
+
+	return cgroupPids{
+		PidsLimit: true,
+	}
+}
+
 func cgroupEnabled(mountPoint, name string) bool {
 	_, err := os.Stat(path.Join(mountPoint, name))
 	return err == nil
@@ -0,0 +1,82 @@
+package tarsum
+
+import (
+	"archive/tar"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// SumFiles calculates the sums of the entries of a tar archive from their
+// headers, and from the contents of the regular files returned by open,
+// typically once the archive was extracted. The contents of the files are
+// hashed concurrently by `workers` goroutines.
+//
+// The sums are the same as the ones returned by GetSums for a TarSum of the
+// archive, and are in the same order.
+func SumFiles(headers []*tar.Header, open func(hdr *tar.Header) (io.ReadCloser, error), v Version, tHash THash, workers int) (FileInfoSums, error) {
+	headerSelector, err := getTarHeaderSelector(v)
+	if err != nil {
+		return nil, err
+	}
+	if tHash == nil {
+		tHash = DefaultTHash
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	sums := make(FileInfoSums, len(headers))
+	errs := make([]error, len(headers))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				sums[i], errs[i] = sumFile(headers[i], int64(i), headerSelector, tHash, open)
+			}
+		}()
+	}
+	for i := range headers {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sums, nil
+}
+
+// sumFile calculates the sum of the entry `hdr` at the position `pos` in the
+// tar archive, the same way a TarSum does.
+func sumFile(hdr *tar.Header, pos int64, headerSelector tarHeaderSelector, tHash THash, open func(hdr *tar.Header) (io.ReadCloser, error)) (FileInfoSumInterface, error) {
+	h := tHash.Hash()
+	for _, elem := range headerSelector.selectHeaders(hdr) {
+		if _, err := h.Write([]byte(elem[0] + elem[1])); err != nil {
+			return nil, err
+		}
+	}
+	if (hdr.Typeflag == tar.TypeReg || hdr.Typeflag == tar.TypeRegA) && hdr.Size > 0 {
+		f, err := open(hdr)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if n, err := io.CopyN(h, f, hdr.Size); err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("%s: expected %d bytes, got %d", hdr.Name, hdr.Size, n)
+			}
+			return nil, err
+		}
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(hdr.Name, "./"), "/")
+	return fileInfoSum{name: name, sum: hex.EncodeToString(h.Sum(nil)), pos: pos}, nil
+}
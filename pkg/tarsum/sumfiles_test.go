@@ -0,0 +1,82 @@
+package tarsum
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestSumFiles(t *testing.T) {
+	for _, layer := range testLayers {
+		if layer.filename == "" {
+			continue
+		}
+		fh, err := os.Open(layer.filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var headers []*tar.Header
+		contents := make(map[*tar.Header][]byte)
+		tr := tar.NewReader(fh)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := ioutil.ReadAll(tr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			headers = append(headers, hdr)
+			contents[hdr] = data
+		}
+		fh.Close()
+
+		open := func(hdr *tar.Header) (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(contents[hdr])), nil
+		}
+		sums, err := SumFiles(headers, open, layer.version, layer.hash, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sums) != len(headers) {
+			t.Fatalf("%s: expected %d sums, got %d", layer.filename, len(headers), len(sums))
+		}
+		for i, fis := range sums {
+			if fis.Pos() != int64(i) {
+				t.Fatalf("%s: expected the sum of position %d, got %d", layer.filename, i, fis.Pos())
+			}
+		}
+
+		var extra []byte
+		if layer.jsonfile != "" {
+			if extra, err = ioutil.ReadFile(layer.jsonfile); err != nil {
+				t.Fatal(err)
+			}
+		}
+		tHash := layer.hash
+		if tHash == nil {
+			tHash = DefaultTHash
+		}
+		ts := &tarSum{tarSumVersion: layer.version, tHash: tHash, sums: sums}
+		if gotSum := ts.Sum(extra); gotSum != layer.tarsum {
+			t.Errorf("%s: expected [%s], but got [%s]", layer.filename, layer.tarsum, gotSum)
+		}
+	}
+}
+
+func TestSumFilesTruncatedFile(t *testing.T) {
+	headers := []*tar.Header{{Name: "file", Typeflag: tar.TypeReg, Size: 10}}
+	open := func(hdr *tar.Header) (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader([]byte("short"))), nil
+	}
+	if _, err := SumFiles(headers, open, Version1, nil, 1); err == nil {
+		t.Fatal("expected an error for a file shorter than its header")
+	}
+}
@@ -20,6 +20,7 @@ func Compare(a, b *Config) bool {
 		len(a.Labels) != len(b.Labels) ||
 		len(a.ExposedPorts) != len(b.ExposedPorts) ||
 		a.Entrypoint.Len() != b.Entrypoint.Len() ||
+		a.Shell.Len() != b.Shell.Len() ||
 		len(a.Volumes) != len(b.Volumes) {
 		return false
 	}
@@ -54,6 +55,13 @@ func Compare(a, b *Config) bool {
 			return false
 		}
 	}
+	aShell := a.Shell.Slice()
+	bShell := b.Shell.Slice()
+	for i := 0; i < len(aShell); i++ {
+		if aShell[i] != bShell[i] {
+			return false
+		}
+	}
 	for key := range a.Volumes {
 		if _, exists := b.Volumes[key]; !exists {
 			return false
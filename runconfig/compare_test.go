@@ -39,6 +39,9 @@ func TestCompare(t *testing.T) {
 	cmd1 := stringutils.NewStrSlice("/bin/sh", "-c")
 	cmd2 := stringutils.NewStrSlice("/bin/sh", "-d")
 	cmd3 := stringutils.NewStrSlice("/bin/sh", "-c", "echo")
+	shell1 := stringutils.NewStrSlice("/bin/bash", "-c")
+	shell2 := stringutils.NewStrSlice("/bin/ash", "-c")
+	shell3 := stringutils.NewStrSlice("/bin/bash", "-e", "-c")
 	labels1 := map[string]string{"LABEL1": "value1", "LABEL2": "value2"}
 	labels2 := map[string]string{"LABEL1": "value1", "LABEL2": "value3"}
 	labels3 := map[string]string{"LABEL1": "value1", "LABEL2": "value2", "LABEL3": "value3"}
@@ -72,6 +75,8 @@ func TestCompare(t *testing.T) {
 		&Config{Entrypoint: entrypoint1}: {Entrypoint: entrypoint1},
 		// only volumes
 		&Config{Volumes: volumes1}: {Volumes: volumes1},
+		// only shell
+		&Config{Shell: shell1}: {Shell: shell1},
 	}
 	differentConfigs := map[*Config]*Config{
 		nil: nil,
@@ -111,6 +116,11 @@ func TestCompare(t *testing.T) {
 		&Config{Volumes: volumes1}: {Volumes: volumes2},
 		// not the same number of labels
 		&Config{Volumes: volumes1}: {Volumes: volumes3},
+		// only shell
+		&Config{Shell: shell1}: {Shell: shell2},
+		// not the same number of parts
+		&Config{Shell: shell1}: {Shell: shell3},
+		&Config{Shell: shell1}: {},
 	}
 	for config1, config2 := range sameConfigs {
 		if !Compare(config1, config2) {
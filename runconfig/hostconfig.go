@@ -184,9 +184,17 @@ type Resources struct {
 	MemoryReservation int64            // Memory soft limit (in bytes)
 	MemorySwap        int64            // Total memory usage (memory + swap); set `-1` to disable swap
 	MemorySwappiness  *int64           // Tuning container memory swappiness behaviour
+	PidsLimit         int64            // Setting pids limit for a container
 	Ulimits           []*ulimit.Ulimit // List of ulimits to be set in the container
 }
 
+// UpdateConfig holds the mutable attributes of a Container.
+// Those attributes can be updated at runtime.
+type UpdateConfig struct {
+	// Contains container's resources (cgroups, ulimits)
+	Resources
+}
+
 // HostConfig the non-portable Config structure of a container.
 // Here, "non-portable" means "dependent of the host we are running on".
 // Portable information *should* appear in Config.
@@ -219,6 +227,7 @@ type HostConfig struct {
 	SecurityOpt     []string              // List of string values to customize labels for MLS systems, such as SELinux.
 	UTSMode         UTSMode               // UTS namespace to use for the container
 	ShmSize         int64                 // Total shm memory usage
+	Tmpfs           map[string]string     `json:",omitempty"` // List of tmpfs (mounts) used for the container
 
 	// Applicable to Windows
 	ConsoleSize [2]int         // Initial console size
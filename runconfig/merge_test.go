@@ -2,8 +2,10 @@ package runconfig
 
 import (
 	"testing"
+	"time"
 
 	"github.com/docker/docker/pkg/nat"
+	"github.com/docker/docker/pkg/stringutils"
 )
 
 func TestMerge(t *testing.T) {
@@ -81,3 +83,60 @@ func TestMerge(t *testing.T) {
 		}
 	}
 }
+
+func TestMergeHealthcheck(t *testing.T) {
+	configImage := &Config{
+		Healthcheck: &HealthConfig{
+			Test:     []string{"CMD-SHELL", "true"},
+			Interval: 5 * time.Second,
+			Retries:  5,
+		},
+	}
+
+	configUser := &Config{}
+	if err := Merge(configUser, configImage); err != nil {
+		t.Fatal(err)
+	}
+	if configUser.Healthcheck == nil || configUser.Healthcheck.Retries != 5 {
+		t.Fatalf("Expected the healthcheck of the image to be inherited, got %v", configUser.Healthcheck)
+	}
+
+	configUser = &Config{
+		Healthcheck: &HealthConfig{
+			Interval: 10 * time.Second,
+		},
+	}
+	if err := Merge(configUser, configImage); err != nil {
+		t.Fatal(err)
+	}
+	hc := configUser.Healthcheck
+	if len(hc.Test) != 2 || hc.Test[1] != "true" {
+		t.Fatalf("Expected the healthcheck test of the image, got %v", hc.Test)
+	}
+	if hc.Interval != 10*time.Second {
+		t.Fatalf("Expected the user interval to be kept, got %v", hc.Interval)
+	}
+	if hc.Retries != 5 {
+		t.Fatalf("Expected 5 retries, got %d", hc.Retries)
+	}
+}
+
+func TestMergeShell(t *testing.T) {
+	configImage := &Config{Shell: stringutils.NewStrSlice("/bin/bash", "-c")}
+
+	configUser := &Config{}
+	if err := Merge(configUser, configImage); err != nil {
+		t.Fatal(err)
+	}
+	if configUser.Shell.Len() != 2 || configUser.Shell.Slice()[0] != "/bin/bash" {
+		t.Fatalf("Expected the shell of the image to be inherited, got %v", configUser.Shell)
+	}
+
+	configUser = &Config{Shell: stringutils.NewStrSlice("/bin/ash", "-c")}
+	if err := Merge(configUser, configImage); err != nil {
+		t.Fatal(err)
+	}
+	if configUser.Shell.Slice()[0] != "/bin/ash" {
+		t.Fatalf("Expected the user shell to be kept, got %v", configUser.Shell)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"reflect"
 	"runtime"
 	"strings"
 	"testing"
@@ -449,6 +451,62 @@ func TestParseWithExpose(t *testing.T) {
 	}
 }
 
+func TestParseTmpfs(t *testing.T) {
+	_, hostConfig := mustParse(t, "--tmpfs /run --tmpfs /tmp:rw,noexec,size=64m")
+	expected := map[string]string{
+		"/run": "",
+		"/tmp": "rw,noexec,size=64m",
+	}
+	if !reflect.DeepEqual(hostConfig.Tmpfs, expected) {
+		t.Fatalf("Expected %v, got %v", expected, hostConfig.Tmpfs)
+	}
+
+	if _, _, err := parse(t, "--tmpfs /tmp:foo=bar"); err == nil {
+		t.Fatal("Expected an error for an invalid tmpfs option")
+	}
+	for _, tmpfs := range []string{"run", "tmp:rw", "./tmp"} {
+		if _, _, err := parse(t, "--tmpfs "+tmpfs); err == nil || !strings.Contains(err.Error(), "mount path must be absolute") {
+			t.Fatalf("Expected an error for the relative tmpfs destination %s, got %v", tmpfs, err)
+		}
+	}
+}
+
+func TestParseSecurityOpts(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "seccomp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	profile := filepath.Join(tmpDir, "profile.json")
+	if err := ioutil.WriteFile(profile, []byte(`{
+	"defaultAction": "SCMP_ACT_ALLOW",
+	"syscalls": [{"name": "chmod", "action": "SCMP_ACT_ERRNO", "args": []}]
+}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts, err := parseSecurityOpts([]string{"apparmor:unconfined", "seccomp=unconfined", "seccomp=" + profile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"apparmor:unconfined",
+		"seccomp=unconfined",
+		`seccomp:{"defaultAction":"SCMP_ACT_ALLOW","syscalls":[{"name":"chmod","action":"SCMP_ACT_ERRNO","args":[]}]}`,
+	}
+	if !reflect.DeepEqual(opts, expected) {
+		t.Fatalf("Expected %v, got %v", expected, opts)
+	}
+
+	if _, err := parseSecurityOpts([]string{"seccomp=" + filepath.Join(tmpDir, "missing.json")}); err == nil {
+		t.Fatal("Expected an error for a missing seccomp profile")
+	}
+	if _, err := parseSecurityOpts([]string{"seccomp"}); err == nil {
+		t.Fatal("Expected an error for an invalid security option")
+	}
+}
+
 func TestParseDevice(t *testing.T) {
 	valids := map[string]DeviceMapping{
 		"/dev/snd": {
@@ -536,6 +594,18 @@ func TestParseModes(t *testing.T) {
 	if hostconfig.ShmSize != 134217728 {
 		t.Fatalf("Expected a valid ShmSize, got %v", hostconfig.ShmSize)
 	}
+	// pids-limit ko
+	if _, _, _, err = parseRun([]string{"--pids-limit=-2", "img", "cmd"}); err == nil || err.Error() != "Invalid value: -2. The pids limit must be -1 (unlimited) or greater" {
+		t.Fatalf("Expected an error with message 'Invalid value: -2. The pids limit must be -1 (unlimited) or greater', got %v", err)
+	}
+	// pids-limit ok
+	_, hostconfig, _, err = parseRun([]string{"--pids-limit=-1", "img", "cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hostconfig.PidsLimit != -1 {
+		t.Fatalf("Expected a PidsLimit of -1, got %v", hostconfig.PidsLimit)
+	}
 }
 
 func TestParseRestartPolicy(t *testing.T) {